@@ -43,7 +43,7 @@ func runBalance(cmd *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmd.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+
+	"github.com/0x3639/znn_cli_go/pkg/testutil"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+)
+
+// update rewrites the golden files with the current output
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// harness runs CLI commands against a mock node with a keyStore created
+// from the fixture mnemonic
+type harness struct {
+	t      *testing.T
+	node   *testutil.Node
+	config string
+	// addresses are the first addresses of the keyStore
+	addresses []types.Address
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	color.NoColor = true
+
+	node := testutil.NewNode(t)
+	dir := t.TempDir()
+	walletDir := filepath.Join(dir, "wallet")
+
+	manager, err := wallet.NewManager(walletDir)
+	require.NoError(t, err)
+	ks, err := manager.CreateFromMnemonic(testutil.MnemonicFixture, testutil.PassphraseFixture, testutil.KeyStoreNameFixture)
+	require.NoError(t, err)
+
+	h := &harness{t: t, node: node, config: filepath.Join(dir, "cli-config.yaml")}
+	for i := 0; i < 2; i++ {
+		keypair, err := ks.GetKeyPair(i)
+		require.NoError(t, err)
+		address, err := keypair.GetAddress()
+		require.NoError(t, err)
+		h.addresses = append(h.addresses, *address)
+		node.SetPlasma(*address, 10*constants.AccountBlockBasePlasma)
+	}
+
+	// The mock node reports the mainnet chain identifier
+	config := fmt.Sprintf(`node:
+  url: %s
+wallet:
+  wallet_dir: %s
+  policy_dir: %s
+journal:
+  path: %s
+confirm:
+  mainnet_guard: false
+`, node.URL, walletDir, filepath.Join(dir, "policy"), filepath.Join(dir, "journal.log"))
+	require.NoError(t, os.WriteFile(h.config, []byte(config), 0600))
+	return h
+}
+
+// run executes a command with the keyStore flags and returns its stdout
+func (h *harness) run(args ...string) (string, error) {
+	h.t.Helper()
+	resetFlags(rootCmd)
+
+	args = append(args, "--config", h.config, "--keyStore", testutil.KeyStoreNameFixture,
+		"--passphrase", testutil.PassphraseFixture, "--yes")
+	rootCmd.SetArgs(args)
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(h.t, err)
+	os.Stdout = w
+	output := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- data
+	}()
+
+	err = rootCmd.ExecuteContext(h.t.Context())
+	os.Stdout = stdout
+	_ = w.Close()
+	return string(<-output), err
+}
+
+// resetFlags restores the flags of a command and its subcommands to their
+// defaults, since cobra keeps flag values between executions
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			_ = slice.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.PersistentFlags().VisitAll(reset)
+	cmd.Flags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// assertGolden compares output with testdata/<name>.golden
+func assertGolden(t *testing.T, name, output string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0750))
+		require.NoError(t, os.WriteFile(path, []byte(output), 0600))
+	}
+	// #nosec G304 - Path is a fixed test file
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), output)
+}
+
+func coins(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), big.NewInt(1e8))
+}
+
+func TestSendAndReceiveAll(t *testing.T) {
+	h := newHarness(t)
+	from, to := h.addresses[0], h.addresses[1]
+	h.node.SetBalance(from, types.ZnnTokenStandard, coins(10))
+
+	output, err := h.run("send", to.String(), "2.5", "ZNN", "--memo", "invoice 117")
+	require.NoError(t, err)
+	assertGolden(t, "send", output)
+	assert.Equal(t, new(big.Int).Div(coins(15), big.NewInt(2)), h.node.Balance(from, types.ZnnTokenStandard))
+	require.Len(t, h.node.Unreceived(to), 1)
+
+	output, err = h.run("receiveAll", "--index", "1")
+	require.NoError(t, err)
+	assertGolden(t, "receive_all", output)
+	assert.Empty(t, h.node.Unreceived(to))
+	assert.Equal(t, new(big.Int).Div(coins(5), big.NewInt(2)), h.node.Balance(to, types.ZnnTokenStandard))
+
+	output, err = h.run("receiveAll", "--index", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "Nothing to receive\n", output)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/exporter"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// exporterCmd serves Prometheus metrics
+var exporterCmd = &cobra.Command{
+	Use:   "exporter [address|@label...]",
+	Short: "Serve account and network state as Prometheus metrics",
+	Long: `Periodically query the node and expose the results as Prometheus gauges
+on /metrics:
+
+  znn_up                          node reachability (1 or 0)
+  znn_momentum_height             frontier momentum height
+  znn_momentum_age_seconds        age of the frontier momentum
+  znn_balance                     balance per address and token
+  znn_plasma_current / _max       plasma per address
+  znn_plasma_fused_qsr            QSR fused per address
+  znn_staked_znn                  ZNN staked per address
+  znn_uncollected_reward          uncollected rewards per source: stake, pillar,
+                                  sentinel and liquidity
+  znn_sentinel_registered/active  sentinel status per address
+  znn_pillar_produced_momentums   momentums produced in the current epoch
+  znn_pillar_expected_momentums   momentums expected in the current epoch
+  znn_pillar_weight / _rank       pillar weight and rank
+
+Amounts are in whole tokens. Addresses and pillars default to the exporter
+section of the config file; without --pillar every pillar is exported.
+
+Examples:
+  znn-cli exporter z1qq... @treasury --listen :9100
+  znn-cli exporter @treasury --pillar MyPillar --interval 1m`,
+	RunE: runExporter,
+}
+
+func init() {
+	exporterCmd.Flags().String("listen", "", "listen address (default from config, :9100)")
+	exporterCmd.Flags().StringSlice("pillar", nil, "pillar names to export (default: all)")
+	exporterCmd.Flags().Duration("interval", 0, "collection interval (default from config, 30s)")
+	rootCmd.AddCommand(exporterCmd)
+}
+
+func runExporter(cmd *cobra.Command, args []string) error {
+	cfg := GetConfig()
+
+	listen, _ := cmd.Flags().GetString("listen")
+	pillars, _ := cmd.Flags().GetStringSlice("pillar")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if listen == "" {
+		listen = cfg.Exporter.Listen
+	}
+	if len(pillars) == 0 {
+		pillars = cfg.Exporter.Pillars
+	}
+	if interval == 0 {
+		interval = cfg.Exporter.Interval
+	}
+	if interval < time.Second {
+		return errs.New(errs.UserInput, "interval must be at least 1s")
+	}
+
+	values := args
+	if len(values) == 0 {
+		values = cfg.Exporter.Addresses
+	}
+	var addresses []types.Address
+	for _, value := range values {
+		address, err := cfg.ResolveAddress(value)
+		if err != nil {
+			return errs.Errorf(errs.UserInput, "invalid address %s: %w", value, err)
+		}
+		addresses = append(addresses, address)
+	}
+
+	collector := &exporter.Collector{Addresses: addresses, Pillars: pillars}
+
+	var mu sync.RWMutex
+	var metrics []byte
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		mu.RLock()
+		defer mu.RUnlock()
+		w.Header().Set("Content-Type", exporter.ContentType)
+		_, _ = w.Write(metrics)
+	})
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", listen, err)
+	}
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx := cmd.Context()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	// Collect periodically; a node that is down is reported as znn_up 0
+	go func() {
+		var rpcClient *client.Client
+		defer func() {
+			if rpcClient != nil {
+				_ = rpcClient.Close()
+			}
+		}()
+
+		for {
+			if rpcClient == nil {
+				if connected, connectErr := client.New(cmd.Context(), cfg.Node.URL); connectErr == nil {
+					rpcClient = connected
+				}
+			}
+
+			var snapshot *exporter.Snapshot
+			var errs []error
+			if rpcClient != nil {
+				snapshot, errs = collector.Collect(rpcClient.RpcClient)
+			} else {
+				snapshot, errs = collector.Collect(nil)
+			}
+			for _, collectErr := range errs {
+				format.Warning(collectErr.Error())
+			}
+
+			var buf bytes.Buffer
+			if writeErr := snapshot.Write(&buf); writeErr == nil {
+				mu.Lock()
+				metrics = buf.Bytes()
+				mu.Unlock()
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+
+	fmt.Printf("Exporting %d address(es) on %s/metrics every %s\n", len(addresses), format.Green(listen), interval)
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server failed: %w", err)
+	}
+
+	fmt.Println("Exporter stopped")
+	return nil
+}
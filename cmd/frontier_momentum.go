@@ -25,7 +25,7 @@ func runFrontierMomentum(cmd *cobra.Command, args []string) error {
 	cfg := GetConfig()
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmd.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
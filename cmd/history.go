@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+)
+
+// historyCmd displays the account block history
+var historyCmd = &cobra.Command{
+	Use:   "history [pageIndex pageSize]",
+	Short: "List account block history",
+	Long: `List the account blocks of the current address, newest first.
+
+Shows for each block:
+  - Direction (sent or received) and counterparty address
+  - Amount and token
+  - Memo or data attached to the send block
+
+Optional pagination parameters:
+  pageIndex - Page number (default: 0)
+  pageSize  - Items per page (default: 25)
+
+Requires --keyStore flag to specify which wallet to use.`,
+	Args: cobra.RangeArgs(0, 2),
+	RunE: runHistory,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	cfg := GetConfig()
+	keystoreName := GetKeyStore()
+	passphrase := GetPassphrase()
+	index := GetIndex()
+
+	// Parse pagination
+	pageIndex := uint32(0)
+	pageSize := uint32(25)
+	if len(args) >= 1 {
+		// Ignore error - default value used on parse failure
+		_, _ = fmt.Sscanf(args[0], "%d", &pageIndex)
+	}
+	if len(args) >= 2 {
+		// Ignore error - default value used on parse failure
+		_, _ = fmt.Sscanf(args[1], "%d", &pageSize)
+	}
+
+	// Load wallet to get address
+	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
+	if err != nil {
+		return err
+	}
+
+	address, err := wallet.GetAddress(keypair)
+	if err != nil {
+		return err
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmd.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	// Get account blocks
+	blocks, err := rpcClient.LedgerApi.GetAccountBlocksByPage(types.ParseAddressPanic(address), pageIndex, pageSize)
+	if err != nil {
+		return fmt.Errorf("failed to get account blocks: %w", err)
+	}
+
+	// Display results
+	fmt.Printf("History for %s:\n", format.Cyan(address))
+	fmt.Println()
+
+	if len(blocks.List) == 0 {
+		fmt.Println("No account blocks")
+		return nil
+	}
+
+	fmt.Printf("Showing %d of %d block(s):\n", len(blocks.List), blocks.Count)
+	for _, block := range blocks.List {
+		printHistoryBlock(block)
+	}
+
+	return nil
+}
+
+// printHistoryBlock displays a single account block with its counterparty and memo
+func printHistoryBlock(block *api.AccountBlock) {
+	fmt.Printf("\n%d. Hash: %s\n", block.Height, format.Cyan(block.Hash.String()))
+
+	// Receive blocks carry the amount and data on the paired send block
+	send := block
+	if nom.IsReceiveBlock(block.BlockType) {
+		send = block.PairedAccountBlock
+		if send == nil {
+			fmt.Printf("   Received (from block %s)\n", block.FromBlockHash)
+			return
+		}
+		fmt.Printf("   Received from: %s\n", format.Cyan(send.Address.String()))
+	} else {
+		fmt.Printf("   Sent to: %s\n", format.Cyan(send.ToAddress.String()))
+	}
+
+	if send.Amount != nil && send.Amount.Sign() > 0 && send.TokenInfo != nil {
+		fmt.Printf("   Amount: %s\n", format.FormatToken(send.Amount,
+			int(send.TokenInfo.Decimals), send.TokenInfo.TokenSymbol))
+	}
+
+	// Data sent to embedded contracts is an encoded method call, not a memo
+	if types.IsEmbeddedAddress(send.ToAddress) && len(send.Data) > 0 {
+		fmt.Println("   Contract call")
+	} else if memo := format.Memo(send.Data); memo != "" {
+		fmt.Printf("   Memo: %s\n", memo)
+	}
+
+	if block.ConfirmationDetail != nil {
+		fmt.Printf("   Momentum: %d\n", block.ConfirmationDetail.MomentumHeight)
+	} else {
+		fmt.Println("   Unconfirmed")
+	}
+}
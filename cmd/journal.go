@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/journal"
+	"github.com/spf13/cobra"
+)
+
+// journalCmd is the root command for the transaction journal
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Browse the local transaction journal",
+	Long: `Every block the CLI publishes is appended to the transaction journal
+(~/.znn/journal.log by default, journal.path in the config file). Each entry
+records the command and its arguments with secrets removed, the block, its
+hash and whether the node accepted it. Confirmations are recorded when the
+journal is refreshed.
+
+Entries are hash-chained: each one includes the hash of the previous entry,
+so 'journal verify' detects edited, removed or reordered entries.
+
+Set journal.disabled in the config file to stop recording.
+
+Available subcommands:
+  list    - List journal entries
+  show    - Show one entry and its block
+  verify  - Check the hash chain of the journal`,
+}
+
+// journalListCmd lists journal entries
+var journalListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List journal entries",
+	Long: `List the blocks recorded in the transaction journal, newest first.
+
+With --refresh, the node is asked about every published block that is not
+confirmed yet and confirmations are added to the journal first.
+
+Examples:
+  znn-cli journal list
+  znn-cli journal list --limit 50 --refresh
+  znn-cli journal list --failed`,
+	Args: cobra.NoArgs,
+	RunE: runJournalList,
+}
+
+// journalShowCmd shows one journal entry
+var journalShowCmd = &cobra.Command{
+	Use:   "show <hash|seq>",
+	Short: "Show a journal entry and its block",
+	Long: `Show the journal entry of a block, selected by block hash, a hash prefix
+of at least 8 characters, or the entry sequence number shown by 'journal list'.
+
+Examples:
+  znn-cli journal show 3
+  znn-cli journal show 9f2c41d0 --refresh`,
+	Args: cobra.ExactArgs(1),
+	RunE: runJournalShow,
+}
+
+// journalVerifyCmd verifies the journal hash chain
+var journalVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the hash chain of the journal",
+	Long: `Recompute the hash of every journal entry and check that each entry links
+to the previous one. Fails on the first entry that was edited, removed or
+reordered.`,
+	Args: cobra.NoArgs,
+	RunE: runJournalVerify,
+}
+
+func init() {
+	journalListCmd.Flags().Int("limit", 20, "number of entries to show (0 for all)")
+	journalListCmd.Flags().Bool("failed", false, "only show blocks the node rejected")
+	journalListCmd.Flags().Bool("refresh", false, "record confirmations from the node first")
+	journalShowCmd.Flags().Bool("refresh", false, "record confirmations from the node first")
+	journalCmd.AddCommand(journalListCmd)
+	journalCmd.AddCommand(journalShowCmd)
+	journalCmd.AddCommand(journalVerifyCmd)
+	rootCmd.AddCommand(journalCmd)
+}
+
+// readJournal refreshes confirmations if requested and reads the journal
+func readJournal(ctx context.Context, refresh bool) ([]*journal.Entry, error) {
+	cfg := GetConfig()
+	if refresh {
+		rpcClient, err := client.New(ctx, cfg.Node.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to node: %w", err)
+		}
+		defer func() { _ = rpcClient.Close() }()
+
+		confirmed, err := journal.Refresh(rpcClient.RpcClient, &journal.Journal{Path: cfg.Journal.Path})
+		if err != nil {
+			return nil, err
+		}
+		if confirmed > 0 {
+			fmt.Printf("Recorded %d new confirmation(s)\n\n", confirmed)
+		}
+	}
+	return journal.Read(cfg.Journal.Path)
+}
+
+func runJournalList(cmd *cobra.Command, args []string) error {
+	limit, _ := cmd.Flags().GetInt("limit")
+	failedOnly, _ := cmd.Flags().GetBool("failed")
+	refresh, _ := cmd.Flags().GetBool("refresh")
+
+	entries, err := readJournal(cmd.Context(), refresh)
+	if err != nil {
+		return err
+	}
+	confirmations := journal.Confirmations(entries)
+
+	shown := 0
+	for i := len(entries) - 1; i >= 0 && (limit == 0 || shown < limit); i-- {
+		entry := entries[i]
+		if entry.Kind != journal.KindPublish || (failedOnly && entry.Published) {
+			continue
+		}
+		fmt.Printf("%4d  %s  %s  %s\n", entry.Seq, entry.Time.Local().Format(time.DateTime),
+			format.Cyan(entry.Hash), journalStatus(entry, confirmations[entry.Hash]))
+		fmt.Printf("      %s\n", journalCommand(entry))
+		shown++
+	}
+
+	if shown == 0 {
+		fmt.Println("No journal entries")
+	}
+	return nil
+}
+
+func runJournalShow(cmd *cobra.Command, args []string) error {
+	refresh, _ := cmd.Flags().GetBool("refresh")
+
+	entries, err := readJournal(cmd.Context(), refresh)
+	if err != nil {
+		return err
+	}
+	entry, confirmation, err := journal.Find(entries, args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Entry:    %d\n", entry.Seq)
+	fmt.Printf("Time:     %s\n", entry.Time.Local().Format(time.RFC3339))
+	fmt.Printf("Command:  %s\n", journalCommand(entry))
+	fmt.Printf("Address:  %s\n", entry.Address)
+	fmt.Printf("Hash:     %s\n", format.Cyan(entry.Hash))
+	fmt.Printf("Status:   %s\n", journalStatus(entry, confirmation))
+	if confirmation != nil {
+		fmt.Printf("Recorded: %s\n", confirmation.Time.Local().Format(time.RFC3339))
+	}
+
+	var block bytes.Buffer
+	if err := json.Indent(&block, entry.Block, "", "  "); err != nil {
+		return fmt.Errorf("failed to format block: %w", err)
+	}
+	fmt.Println()
+	fmt.Println(block.String())
+	return nil
+}
+
+func runJournalVerify(cmd *cobra.Command, args []string) error {
+	cfg := GetConfig()
+	entries, err := journal.Read(cfg.Journal.Path)
+	if err != nil {
+		return err
+	}
+	if err := journal.Verify(entries); err != nil {
+		return fmt.Errorf("journal %s is not intact: %w", cfg.Journal.Path, err)
+	}
+
+	format.Success(fmt.Sprintf("Journal intact: %d entries", len(entries)))
+	if len(entries) > 0 {
+		fmt.Printf("Head: %s\n", entries[len(entries)-1].EntryHash)
+	}
+	return nil
+}
+
+// journalStatus describes whether a block was published and confirmed
+func journalStatus(entry, confirmation *journal.Entry) string {
+	switch {
+	case !entry.Published:
+		return format.Red("failed: " + entry.Error)
+	case confirmation != nil:
+		return format.Green(fmt.Sprintf("confirmed at momentum %d", confirmation.MomentumHeight))
+	default:
+		return format.Yellow("published")
+	}
+}
+
+// journalCommand returns the recorded command line
+func journalCommand(entry *journal.Entry) string {
+	command := entry.Command
+	if len(entry.Args) > 0 {
+		command = "znn-cli " + strings.Join(entry.Args, " ")
+	}
+	return command
+}
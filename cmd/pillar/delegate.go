@@ -57,7 +57,7 @@ func runDelegate(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -77,7 +77,7 @@ func runDelegate(cmdCobra *cobra.Command, args []string) error {
 	// Send transaction
 	fmt.Printf("Delegating to pillar %s\n", format.Green(pillarName))
 
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to delegate: %w", err)
 	}
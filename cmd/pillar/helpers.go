@@ -1,8 +1,19 @@
 package pillar
 
 import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
 	"github.com/0x3639/znn_cli_go/pkg/config"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
 )
 
 // getConfigAndFlags extracts configuration and flags from the command
@@ -24,3 +35,57 @@ func getConfigAndFlags(cmd *cobra.Command) (*config.Config, string, string, int,
 
 	return cfg, keystoreName, passphrase, index, nil
 }
+
+// validatePercentage checks that a reward sharing percentage is between 0 and 100
+func validatePercentage(name string, value int) error {
+	if value < 0 || value > 100 {
+		return errs.Errorf(errs.UserInput, "%s must be between 0 and 100", name)
+	}
+	return nil
+}
+
+// qsrShortfall returns the current QSR registration cost, the QSR already
+// deposited by the address and the amount that still has to be deposited
+func qsrShortfall(c *client.Client, address types.Address) (*big.Int, *big.Int, *big.Int, error) {
+	cost, err := c.PillarQsrRegistrationCost()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get QSR registration cost: %w", err)
+	}
+
+	deposited, err := c.PillarDepositedQsr(address)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get deposited QSR: %w", err)
+	}
+
+	shortfall := new(big.Int).Sub(cost, deposited)
+	if shortfall.Sign() < 0 {
+		shortfall.SetInt64(0)
+	}
+	return cost, deposited, shortfall, nil
+}
+
+// depositQsr publishes a QSR deposit and waits until the pillar contract has credited it
+func depositQsr(ctx context.Context, c *client.Client, address types.Address, amount *big.Int, keypair *wallet.KeyPair, timeout time.Duration) error {
+	before, err := c.PillarDepositedQsr(address)
+	if err != nil {
+		return fmt.Errorf("failed to get deposited QSR: %w", err)
+	}
+
+	template := c.PillarApi.DepositQsr(amount)
+	if err := transaction.BuildAndSend(ctx, c.RpcClient, address, template, keypair); err != nil {
+		return fmt.Errorf("failed to deposit QSR: %w", err)
+	}
+
+	target := new(big.Int).Add(before, amount)
+	err = transaction.WaitUntil(ctx, timeout, transaction.DefaultWaitInterval, func() (bool, error) {
+		deposited, err := c.PillarDepositedQsr(address)
+		if err != nil {
+			return false, fmt.Errorf("failed to get deposited QSR: %w", err)
+		}
+		return deposited.Cmp(target) >= 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("deposit was published but not yet credited: %w", err)
+	}
+	return nil
+}
@@ -2,11 +2,14 @@ package pillar
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
 	"github.com/0x3639/znn_cli_go/pkg/config"
 	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/pillarstats"
 	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
 )
 
 // listCmd lists all pillars
@@ -21,16 +24,36 @@ Shows:
   - Producer/withdraw address
   - Weight (delegation weight)
   - Momentum produced/expected
+  - Reward sharing percentages and estimated delegator APR
   - Rank
 
+Sorting and filtering:
+  --sort        rank (default), weight, uptime, momentum-reward,
+                delegate-reward or apr; all but rank sort best first
+  --min-uptime  minimum produced/expected percentage in the current epoch
+  --search      case-insensitive name search
+  --all         show every matching pillar instead of one page
+
+The APR estimate is the yearly ZNN return of delegating, based on the
+pillar's reward sharing, current-epoch uptime and share of the total weight.
+It assumes these stay constant and excludes ZNN price changes.
+
 Optional pagination parameters:
   pageIndex - Page number (default: 0)
-  pageSize  - Items per page (default: 25)`,
+  pageSize  - Items per page (default: 25)
+
+Examples:
+  znn-cli pillar list --sort apr --min-uptime 95
+  znn-cli pillar list --search zenon --all`,
 	Args: cobra.RangeArgs(0, 2),
 	RunE: runList,
 }
 
 func init() {
+	listCmd.Flags().String("sort", pillarstats.SortRank, "sort by "+strings.Join(pillarstats.SortKeys, ", "))
+	listCmd.Flags().Float64("min-uptime", 0, "minimum uptime percentage")
+	listCmd.Flags().String("search", "", "search pillar names")
+	listCmd.Flags().Bool("all", false, "show all matching pillars")
 	PillarCmd.AddCommand(listCmd)
 }
 
@@ -47,6 +70,11 @@ func runList(cmdCobra *cobra.Command, args []string) error {
 		_, _ = fmt.Sscanf(args[1], "%d", &pageSize)
 	}
 
+	sortKey, _ := cmdCobra.Flags().GetString("sort")
+	minUptime, _ := cmdCobra.Flags().GetFloat64("min-uptime")
+	search, _ := cmdCobra.Flags().GetString("search")
+	all, _ := cmdCobra.Flags().GetBool("all")
+
 	// Get URL from flags or config
 	url, _ := cmdCobra.Flags().GetString("url")
 	configFile, _ := cmdCobra.Flags().GetString("config")
@@ -60,44 +88,101 @@ func runList(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
 	defer func() { _ = rpcClient.Close() }()
 
-	// Get pillar list
-	pillarList, err := rpcClient.PillarApi.GetAll(pageIndex, pageSize)
+	// Get every pillar; APR estimates need network totals
+	infos, err := getAllPillars(rpcClient)
 	if err != nil {
-		return fmt.Errorf("failed to get pillar list: %w", err)
+		return err
 	}
 
-	// Display results
-	if pillarList.Count == 0 {
+	if len(infos) == 0 {
 		fmt.Println("No pillars found")
 		return nil
 	}
 
-	fmt.Printf("Total pillars: %d\n", pillarList.Count)
+	pillars := pillarstats.Build(infos, currentEpoch(rpcClient, infos[0].Name))
+	pillars = pillarstats.Filter{Search: search, MinUptime: minUptime}.Apply(pillars)
+	if err := pillarstats.Sort(pillars, sortKey); err != nil {
+		return err
+	}
+
+	matching := len(pillars)
+	if !all {
+		start := int(pageIndex) * int(pageSize)
+		end := start + int(pageSize)
+		if start > len(pillars) {
+			start = len(pillars)
+		}
+		if end > len(pillars) {
+			end = len(pillars)
+		}
+		pillars = pillars[start:end]
+	}
+
+	// Display results
+	fmt.Printf("Total pillars: %d", len(infos))
+	if matching != len(infos) {
+		fmt.Printf(" (%d matching)", matching)
+	}
 	fmt.Println()
+	fmt.Println()
+
+	if len(pillars) == 0 {
+		fmt.Println("No pillars match")
+		return nil
+	}
 
-	for idx, pillar := range pillarList.List {
-		rank := int(pageIndex)*int(pageSize) + idx + 1
+	for _, pillar := range pillars {
+		info := pillar.Info
 
-		fmt.Printf("%d. Pillar %s\n", rank, format.Green(pillar.Name))
-		fmt.Printf("   Producer: %s\n", pillar.BlockProducingAddress.String())
-		fmt.Printf("   Reward: %s\n", pillar.RewardWithdrawAddress.String())
-		fmt.Printf("   Weight: %s\n", format.Amount(pillar.Weight, 8))
+		fmt.Printf("%d. Pillar %s\n", info.Rank+1, format.Green(info.Name))
+		fmt.Printf("   Producer: %s\n", info.BlockProducingAddress.String())
+		fmt.Printf("   Reward: %s\n", info.RewardWithdrawAddress.String())
+		fmt.Printf("   Weight: %s\n", format.Amount(info.Weight, 8))
 
-		if pillar.CurrentStats.ExpectedMomentums > 0 {
-			percentage := float64(pillar.CurrentStats.ProducedMomentums) * 100.0 / float64(pillar.CurrentStats.ExpectedMomentums)
+		if info.CurrentStats != nil && info.CurrentStats.ExpectedMomentums > 0 {
 			fmt.Printf("   Momentums: %d / %d (%.2f%%)\n",
-				pillar.CurrentStats.ProducedMomentums,
-				pillar.CurrentStats.ExpectedMomentums,
-				percentage)
+				info.CurrentStats.ProducedMomentums,
+				info.CurrentStats.ExpectedMomentums,
+				pillar.Uptime*100)
 		}
+		fmt.Printf("   Sharing: %d%% momentum rewards, %d%% delegation rewards\n",
+			info.GiveMomentumRewardPercentage, info.GiveDelegateRewardPercentage)
+		fmt.Printf("   Estimated delegator APR: %.2f%%\n", pillar.APR)
 		fmt.Println()
 	}
 
 	return nil
 }
+
+// getAllPillars fetches every page of the pillar list
+func getAllPillars(rpcClient *client.Client) ([]*embedded.PillarInfo, error) {
+	const pageSize = 100
+
+	var pillars []*embedded.PillarInfo
+	for pageIndex := uint32(0); ; pageIndex++ {
+		list, err := rpcClient.PillarApi.GetAll(pageIndex, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pillar list: %w", err)
+		}
+		pillars = append(pillars, list.List...)
+		if len(list.List) < pageSize || uint32(len(pillars)) >= list.Count {
+			return pillars, nil
+		}
+	}
+}
+
+// currentEpoch returns the current epoch, derived from the latest epoch in a
+// pillar's reward history. It returns 0 if the history is unavailable.
+func currentEpoch(rpcClient *client.Client, pillarName string) uint64 {
+	history, err := rpcClient.PillarApi.GetPillarEpochHistory(pillarName, 0, 1)
+	if err != nil || len(history.List) == 0 {
+		return 0
+	}
+	return history.List[0].Epoch + 1
+}
@@ -0,0 +1,239 @@
+package pillar
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/pillarmon"
+	"github.com/0x3639/znn_cli_go/pkg/watch"
+	"github.com/spf13/cobra"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+)
+
+// monitorCmd monitors a pillar's momentum production and weight
+var monitorCmd = &cobra.Command{
+	Use:   "monitor <name>",
+	Short: "Monitor a pillar and alert on missed momentums",
+	Long: `Track a pillar's current epoch statistics and weight over time and alert when:
+  - the produced/expected momentum ratio falls below --min-ratio
+    (checked once --min-expected momentums are expected in the epoch)
+  - the weight drops by --weight-drop percent or more within --window
+  - the weight decreases by --departure ZNN or more between two samples,
+    which means delegators left or reduced their balance
+
+Alerts are printed and, like 'watch' events, POSTed as signed JSON to every
+--webhook and piped to every --exec command.
+
+Samples are kept in ~/.znn/pillar-monitor/<name>.json; use --trend to print
+the recorded history instead of monitoring.
+
+Examples:
+  znn-cli pillar monitor MyPillar --min-ratio 0.95 --exec 'notify-send "$ZNN_EVENT_TYPE"'
+  znn-cli pillar monitor MyPillar --webhook https://example.com/alerts --secret s3cret
+  znn-cli pillar monitor MyPillar --trend 48`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMonitor,
+}
+
+func init() {
+	defaults := pillarmon.DefaultThresholds()
+	monitorCmd.Flags().Duration("interval", time.Minute, "poll interval")
+	monitorCmd.Flags().Float64("min-ratio", defaults.MinRatio, "minimum produced/expected ratio (0-1)")
+	monitorCmd.Flags().Uint64("min-expected", defaults.MinExpected, "expected momentums required before checking the ratio")
+	monitorCmd.Flags().Float64("weight-drop", defaults.MaxWeightDrop, "weight drop in percent within --window that raises an alert")
+	monitorCmd.Flags().Duration("window", defaults.Window, "window for weight drops")
+	monitorCmd.Flags().String("departure", "100", "weight decrease in ZNN between samples reported as delegators leaving (0 disables)")
+	monitorCmd.Flags().StringSlice("webhook", nil, "webhook URL to POST alerts to (repeatable)")
+	monitorCmd.Flags().String("secret", "", "secret used to sign webhook payloads (required with --webhook)")
+	monitorCmd.Flags().StringSlice("exec", nil, "shell command to run per alert (repeatable)")
+	monitorCmd.Flags().String("history", "", "history file (default ~/.znn/pillar-monitor/<name>.json)")
+	monitorCmd.Flags().Int("history-size", pillarmon.DefaultHistorySize, "number of samples to keep")
+	monitorCmd.Flags().Int("trend", 0, "print the last N recorded samples and exit")
+	monitorCmd.Flags().Bool("once", false, "take one sample, then exit")
+	PillarCmd.AddCommand(monitorCmd)
+}
+
+func runMonitor(cmdCobra *cobra.Command, args []string) error {
+	name := args[0]
+	cfg, _, _, _, err := getConfigAndFlags(cmdCobra)
+	if err != nil {
+		return err
+	}
+
+	interval, _ := cmdCobra.Flags().GetDuration("interval")
+	departureStr, _ := cmdCobra.Flags().GetString("departure")
+	webhookURLs, _ := cmdCobra.Flags().GetStringSlice("webhook")
+	secret, _ := cmdCobra.Flags().GetString("secret")
+	hooks, _ := cmdCobra.Flags().GetStringSlice("exec")
+	historyPath, _ := cmdCobra.Flags().GetString("history")
+	historySize, _ := cmdCobra.Flags().GetInt("history-size")
+	trend, _ := cmdCobra.Flags().GetInt("trend")
+	once, _ := cmdCobra.Flags().GetBool("once")
+
+	thresholds := pillarmon.DefaultThresholds()
+	thresholds.MinRatio, _ = cmdCobra.Flags().GetFloat64("min-ratio")
+	thresholds.MinExpected, _ = cmdCobra.Flags().GetUint64("min-expected")
+	thresholds.MaxWeightDrop, _ = cmdCobra.Flags().GetFloat64("weight-drop")
+	thresholds.Window, _ = cmdCobra.Flags().GetDuration("window")
+	if thresholds.MinDeparture, err = format.ParseAmount(departureStr, 8); err != nil {
+		return errs.Errorf(errs.UserInput, "invalid departure amount: %w", err)
+	}
+	if err := thresholds.Validate(); err != nil {
+		return err
+	}
+	if interval < time.Second {
+		return errs.New(errs.UserInput, "interval must be at least 1s")
+	}
+	if len(webhookURLs) > 0 && secret == "" {
+		return errs.New(errs.UserInput, "--webhook requires --secret to sign alert payloads")
+	}
+
+	if historyPath == "" {
+		if historyPath, err = pillarmon.HistoryPath(name); err != nil {
+			return err
+		}
+	}
+	history, err := pillarmon.LoadHistory(historyPath, name)
+	if err != nil {
+		return err
+	}
+
+	if trend > 0 {
+		printTrend(history, trend)
+		return nil
+	}
+
+	var sinks []watch.Sink
+	for _, url := range webhookURLs {
+		sinks = append(sinks, &watch.Webhook{URL: url, Secret: secret})
+	}
+	for _, hook := range hooks {
+		sinks = append(sinks, &watch.Command{Command: hook})
+	}
+	dispatcher := watch.NewDispatcher(sinks)
+	// Undelivered alerts are retried on the next poll while the monitor runs
+	outbox := watch.NewState()
+
+	// Connect to node
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	ctx := cmdCobra.Context()
+
+	fmt.Printf("Monitoring pillar %s every %s (history: %s)\n", format.Green(name), interval, historyPath)
+
+	for {
+		sample, err := takeSample(rpcClient.RpcClient, name)
+		if err != nil {
+			if once {
+				return err
+			}
+			format.Warning(err.Error())
+		} else {
+			alerts := pillarmon.Check(history, *sample, thresholds)
+			history.Add(*sample, historySize)
+			if err := history.Save(historyPath); err != nil {
+				return err
+			}
+
+			fmt.Printf("%s momentum %d: %d/%d produced (%.2f%%), weight %s ZNN\n",
+				sample.Time.Format(time.RFC3339), sample.Momentum, sample.Produced, sample.Expected,
+				sample.Ratio()*100, format.Amount(sample.WeightInt(), 8))
+			for _, alert := range alerts {
+				format.Warning(alert.Message)
+			}
+			outbox.Enqueue(alerts, dispatcher.SinkNames())
+		}
+
+		if _, err := dispatcher.Flush(ctx, outbox, func() error { return nil }); err != nil {
+			return err
+		}
+		for _, delivery := range outbox.Outbox {
+			format.Warning(fmt.Sprintf("Alert %s not delivered: %s", delivery.Event.ID, delivery.LastError))
+		}
+
+		if once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("Monitor stopped")
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// takeSample queries the current state of a pillar
+func takeSample(c *rpc_client.RpcClient, name string) (*pillarmon.Sample, error) {
+	momentum, err := c.LedgerApi.GetFrontierMomentum()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frontier momentum: %w", err)
+	}
+
+	pillar, err := c.PillarApi.GetByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pillar %s: %w", name, err)
+	}
+	if pillar == nil {
+		return nil, errs.Errorf(errs.UserInput, "pillar %s not found", name)
+	}
+
+	sample := &pillarmon.Sample{
+		Time:     time.Now().UTC(),
+		Momentum: momentum.Height,
+		Owner:    pillar.StakeAddress.String(),
+		Rank:     pillar.Rank,
+		Weight:   "0",
+	}
+	if pillar.CurrentStats != nil {
+		sample.Produced = pillar.CurrentStats.ProducedMomentums
+		sample.Expected = pillar.CurrentStats.ExpectedMomentums
+	}
+	if pillar.Weight != nil {
+		sample.Weight = pillar.Weight.String()
+	}
+	return sample, nil
+}
+
+// printTrend prints the last samples of a history with weight changes
+func printTrend(history *pillarmon.History, count int) {
+	samples := history.Samples
+	if len(samples) == 0 {
+		fmt.Printf("No history recorded for pillar %s\n", history.Pillar)
+		return
+	}
+	start := 0
+	if len(samples) > count {
+		start = len(samples) - count
+	}
+
+	fmt.Printf("Pillar %s, %d of %d sample(s):\n\n", format.Green(history.Pillar), len(samples)-start, len(samples))
+	fmt.Printf("%-20s  %10s  %13s  %8s  %20s  %16s\n", "Time", "Momentum", "Produced", "Ratio", "Weight (ZNN)", "Change")
+	for i := start; i < len(samples); i++ {
+		sample := samples[i]
+		change := ""
+		if i > 0 {
+			delta := new(big.Int).Sub(sample.WeightInt(), samples[i-1].WeightInt())
+			if delta.Sign() != 0 {
+				change = format.Amount(delta, 8)
+				if delta.Sign() > 0 {
+					change = "+" + change
+				}
+			}
+		}
+		fmt.Printf("%-20s  %10d  %6d/%-6d  %7.2f%%  %20s  %16s\n",
+			sample.Time.Local().Format("2006-01-02 15:04:05"), sample.Momentum,
+			sample.Produced, sample.Expected, sample.Ratio()*100,
+			format.Amount(sample.WeightInt(), 8), change)
+	}
+}
@@ -17,16 +17,19 @@ Pillars are the backbone of the Zenon Network, responsible for:
 
 Requirements to register a pillar:
   - 15,000 ZNN
-  - 150,000 QSR
+  - 150,000 QSR or more, deposited into the pillar contract
 
 Available subcommands:
   list        - List all pillars
   register    - Register a new pillar
+  update      - Update addresses and reward sharing
+  depositQsr  - Deposit QSR for registration
   revoke      - Revoke your pillar
   delegate    - Delegate to a pillar
   undelegate  - Remove delegation
   collect     - Collect pillar rewards
-  withdrawQsr - Withdraw deposited QSR`,
+  withdrawQsr - Withdraw deposited QSR
+  monitor     - Monitor production and weight`,
 }
 
 func init() {
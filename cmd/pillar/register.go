@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"math/big"
 	"regexp"
+	"time"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
@@ -16,9 +18,6 @@ import (
 const (
 	// PillarRegisterZnnAmount is the ZNN required to register a pillar (15,000 ZNN)
 	PillarRegisterZnnAmount = 15000 * 1e8
-
-	// PillarRegisterQsrAmount is the QSR required to register a pillar (150,000 QSR)
-	PillarRegisterQsrAmount = 150000 * 1e8
 )
 
 var pillarNameRegex = regexp.MustCompile(`^[a-zA-Z0-9]+[\-\.\_\+]{0,1}[a-zA-Z0-9]+$`)
@@ -31,14 +30,22 @@ var registerCmd = &cobra.Command{
 
 Requirements:
   - 15,000 ZNN
-  - 150,000 QSR
+  - QSR registration cost (150,000 QSR or more, rising with each pillar),
+    deposited into the pillar contract
   - Unique pillar name (3-40 characters, alphanumeric with -._+)
 
+Any QSR shortfall is deposited first; registration continues once the
+deposit has been credited. Use 'pillar depositQsr' to deposit in advance.
+
 The producing address will be used to sign momentums.
 The reward address will receive pillar rewards.
 
+The percentages set how much of the momentum and delegation rewards are
+given back to delegators; change them later with 'pillar update'.
+
 Example:
   znn-cli pillar register MyPillar z1qz... z1qz...
+  znn-cli pillar register MyPillar z1qz... z1qz... --momentum-percentage 20 --delegation-percentage 80
 
 Requires --keyStore flag to specify which wallet to use.`,
 	Args: cobra.ExactArgs(3),
@@ -46,6 +53,9 @@ Requires --keyStore flag to specify which wallet to use.`,
 }
 
 func init() {
+	registerCmd.Flags().Int("momentum-percentage", 0, "percentage of momentum rewards given to delegators")
+	registerCmd.Flags().Int("delegation-percentage", 100, "percentage of delegation rewards given to delegators")
+	registerCmd.Flags().Duration("wait", 2*time.Minute, "how long to wait for a QSR deposit to be credited")
 	PillarCmd.AddCommand(registerCmd)
 }
 
@@ -55,6 +65,16 @@ func runRegister(cmdCobra *cobra.Command, args []string) error {
 		return err
 	}
 
+	momentumPercentage, _ := cmdCobra.Flags().GetInt("momentum-percentage")
+	delegationPercentage, _ := cmdCobra.Flags().GetInt("delegation-percentage")
+	wait, _ := cmdCobra.Flags().GetDuration("wait")
+	if err := validatePercentage("momentum percentage", momentumPercentage); err != nil {
+		return err
+	}
+	if err := validatePercentage("delegation percentage", delegationPercentage); err != nil {
+		return err
+	}
+
 	// Parse arguments
 	pillarName := args[0]
 	producerAddressStr := args[1]
@@ -62,21 +82,21 @@ func runRegister(cmdCobra *cobra.Command, args []string) error {
 
 	// Validate pillar name
 	if len(pillarName) < 3 || len(pillarName) > 40 {
-		return fmt.Errorf("pillar name must be between 3 and 40 characters")
+		return errs.New(errs.UserInput, "pillar name must be between 3 and 40 characters")
 	}
 	if !pillarNameRegex.MatchString(pillarName) {
-		return fmt.Errorf("invalid pillar name: must be alphanumeric with optional -._+ separators")
+		return errs.New(errs.UserInput, "invalid pillar name: must be alphanumeric with optional -._+ separators")
 	}
 
 	// Parse addresses
 	producerAddress, err := types.ParseAddress(producerAddressStr)
 	if err != nil {
-		return fmt.Errorf("invalid producer address: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid producer address: %w", err)
 	}
 
 	rewardAddress, err := types.ParseAddress(rewardAddressStr)
 	if err != nil {
-		return fmt.Errorf("invalid reward address: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid reward address: %w", err)
 	}
 
 	// Load wallet
@@ -91,7 +111,7 @@ func runRegister(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -102,7 +122,7 @@ func runRegister(cmdCobra *cobra.Command, args []string) error {
 	// Check if pillar name is already taken
 	existingPillar, err := rpcClient.PillarApi.GetByName(pillarName)
 	if err == nil && existingPillar != nil {
-		return fmt.Errorf("pillar name '%s' is already registered", pillarName)
+		return errs.Errorf(errs.UserInput, "pillar name '%s' is already registered", pillarName)
 	}
 
 	// Get account info to check balances
@@ -119,22 +139,28 @@ func runRegister(cmdCobra *cobra.Command, args []string) error {
 		if found {
 			currentBalance = znnBalance.Balance
 		}
-		return fmt.Errorf("insufficient ZNN balance. You have %s but need %s",
+		return errs.Errorf(errs.InsufficientFunds, "insufficient ZNN balance. You have %s but need %s",
 			format.Amount(currentBalance, 8),
 			format.Amount(requiredZnn, 8))
 	}
 
-	// Check QSR balance
-	requiredQsr := big.NewInt(PillarRegisterQsrAmount)
-	qsrBalance, found := accountInfo.BalanceInfoMap[types.QsrTokenStandard]
-	if !found || qsrBalance.Balance.Cmp(requiredQsr) < 0 {
-		currentBalance := big.NewInt(0)
-		if found {
-			currentBalance = qsrBalance.Balance
+	// Check QSR deposit and balance
+	cost, deposited, shortfall, err := qsrShortfall(rpcClient, parsedAddress)
+	if err != nil {
+		return err
+	}
+	if shortfall.Sign() > 0 {
+		qsrBalance := big.NewInt(0)
+		if balanceInfo, found := accountInfo.BalanceInfoMap[types.QsrTokenStandard]; found && balanceInfo.Balance != nil {
+			qsrBalance = balanceInfo.Balance
+		}
+		if qsrBalance.Cmp(shortfall) < 0 {
+			return errs.Errorf(errs.InsufficientFunds, "insufficient QSR balance. You have %s but need %s more to deposit (cost %s, deposited %s)",
+				format.Amount(qsrBalance, 8),
+				format.Amount(shortfall, 8),
+				format.Amount(cost, 8),
+				format.Amount(deposited, 8))
 		}
-		return fmt.Errorf("insufficient QSR balance. You have %s but need %s",
-			format.Amount(currentBalance, 8),
-			format.Amount(requiredQsr, 8))
 	}
 
 	// Display registration info
@@ -142,16 +168,27 @@ func runRegister(cmdCobra *cobra.Command, args []string) error {
 	fmt.Printf("  Owner: %s\n", address)
 	fmt.Printf("  Producer: %s\n", producerAddress.String())
 	fmt.Printf("  Reward: %s\n", rewardAddress.String())
+	fmt.Printf("  Rewards given to delegators: %d%% momentum, %d%% delegation\n", momentumPercentage, delegationPercentage)
 	fmt.Printf("  Cost: %s %s + %s %s\n",
 		format.Amount(requiredZnn, 8), format.Green("ZNN"),
-		format.Amount(requiredQsr, 8), format.Blue("QSR"))
+		format.Amount(cost, 8), format.Blue("QSR"))
 	fmt.Println()
 
+	// Deposit the QSR shortfall first
+	if shortfall.Sign() > 0 {
+		fmt.Printf("Depositing %s %s (already deposited: %s)\n",
+			format.Amount(shortfall, 8), format.Blue("QSR"), format.Amount(deposited, 8))
+		if err := depositQsr(cmdCobra.Context(), rpcClient, parsedAddress, shortfall, keypair, wait); err != nil {
+			return fmt.Errorf("%w; run 'pillar register' again once it is credited", err)
+		}
+		fmt.Println("Deposit credited")
+	}
+
 	// Create pillar registration template
-	template := rpcClient.PillarApi.Register(pillarName, producerAddress, rewardAddress, uint8(0), uint8(100))
+	template := rpcClient.PillarApi.Register(pillarName, producerAddress, rewardAddress, uint8(momentumPercentage), uint8(delegationPercentage))
 
 	// Send transaction
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to register pillar: %w", err)
 	}
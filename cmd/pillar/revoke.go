@@ -57,7 +57,7 @@ func runRevoke(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -80,7 +80,7 @@ func runRevoke(cmdCobra *cobra.Command, args []string) error {
 	template := rpcClient.PillarApi.Revoke()
 
 	// Send transaction
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to revoke pillar: %w", err)
 	}
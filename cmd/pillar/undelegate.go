@@ -45,7 +45,7 @@ func runUndelegate(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -59,7 +59,7 @@ func runUndelegate(cmdCobra *cobra.Command, args []string) error {
 	// Send transaction
 	fmt.Println("Removing delegation")
 
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to undelegate: %w", err)
 	}
@@ -0,0 +1,153 @@
+package pillar
+
+import (
+	"fmt"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// updateCmd updates a pillar's addresses and reward sharing
+var updateCmd = &cobra.Command{
+	Use:   "update <name>",
+	Short: "Update a pillar's addresses and reward sharing",
+	Long: `Update the producer address, reward address or the percentages of momentum
+and delegation rewards given to delegators.
+
+Only the flags that are given change; the other values are kept. The
+command must be run with the pillar's owner address and fails when nothing
+would change.
+
+Examples:
+  znn-cli pillar update MyPillar --producer z1qz...
+  znn-cli pillar update MyPillar --momentum-percentage 10 --delegation-percentage 90
+
+Requires --keyStore flag to specify which wallet to use.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUpdate,
+}
+
+func init() {
+	updateCmd.Flags().String("producer", "", "new producer address")
+	updateCmd.Flags().String("reward", "", "new reward address")
+	updateCmd.Flags().Int("momentum-percentage", 0, "percentage of momentum rewards given to delegators")
+	updateCmd.Flags().Int("delegation-percentage", 0, "percentage of delegation rewards given to delegators")
+	PillarCmd.AddCommand(updateCmd)
+}
+
+func runUpdate(cmdCobra *cobra.Command, args []string) error {
+	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
+	if err != nil {
+		return err
+	}
+	pillarName := args[0]
+
+	flags := cmdCobra.Flags()
+	if !flags.Changed("producer") && !flags.Changed("reward") &&
+		!flags.Changed("momentum-percentage") && !flags.Changed("delegation-percentage") {
+		return errs.New(errs.UserInput, "nothing to update: use --producer, --reward, --momentum-percentage or --delegation-percentage")
+	}
+
+	// Load wallet
+	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
+	if err != nil {
+		return err
+	}
+
+	address, err := wallet.GetAddress(keypair)
+	if err != nil {
+		return err
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	parsedAddress := types.ParseAddressPanic(address)
+
+	pillar, err := rpcClient.PillarApi.GetByName(pillarName)
+	if err != nil || pillar == nil {
+		return errs.Errorf(errs.UserInput, "pillar %s not found", pillarName)
+	}
+	if pillar.StakeAddress != parsedAddress {
+		return errs.Errorf(errs.UserInput, "pillar %s is owned by %s, not %s", pillarName, pillar.StakeAddress.String(), address)
+	}
+
+	// Start from the current values
+	producerAddress := pillar.BlockProducingAddress
+	rewardAddress := pillar.RewardWithdrawAddress
+	momentumPercentage := int(pillar.GiveMomentumRewardPercentage)
+	delegationPercentage := int(pillar.GiveDelegateRewardPercentage)
+
+	if flags.Changed("producer") {
+		value, _ := flags.GetString("producer")
+		if producerAddress, err = cfg.ResolveAddress(value); err != nil {
+			return errs.Errorf(errs.UserInput, "invalid producer address: %w", err)
+		}
+	}
+	if flags.Changed("reward") {
+		value, _ := flags.GetString("reward")
+		if rewardAddress, err = cfg.ResolveAddress(value); err != nil {
+			return errs.Errorf(errs.UserInput, "invalid reward address: %w", err)
+		}
+	}
+	if flags.Changed("momentum-percentage") {
+		momentumPercentage, _ = flags.GetInt("momentum-percentage")
+		if err := validatePercentage("momentum percentage", momentumPercentage); err != nil {
+			return err
+		}
+	}
+	if flags.Changed("delegation-percentage") {
+		delegationPercentage, _ = flags.GetInt("delegation-percentage")
+		if err := validatePercentage("delegation percentage", delegationPercentage); err != nil {
+			return err
+		}
+	}
+
+	if producerAddress == pillar.BlockProducingAddress &&
+		rewardAddress == pillar.RewardWithdrawAddress &&
+		momentumPercentage == int(pillar.GiveMomentumRewardPercentage) &&
+		delegationPercentage == int(pillar.GiveDelegateRewardPercentage) {
+		return errs.New(errs.UserInput, "the given values match the current pillar settings")
+	}
+
+	// Display changes
+	fmt.Printf("Updating pillar %s\n", format.Green(pillarName))
+	printChange("Producer", pillar.BlockProducingAddress.String(), producerAddress.String())
+	printChange("Reward", pillar.RewardWithdrawAddress.String(), rewardAddress.String())
+	printChange("Momentum rewards to delegators",
+		fmt.Sprintf("%d%%", pillar.GiveMomentumRewardPercentage), fmt.Sprintf("%d%%", momentumPercentage))
+	printChange("Delegation rewards to delegators",
+		fmt.Sprintf("%d%%", pillar.GiveDelegateRewardPercentage), fmt.Sprintf("%d%%", delegationPercentage))
+	fmt.Println()
+
+	template := rpcClient.PillarApi.UpdatePillar(pillarName, producerAddress, rewardAddress, uint8(momentumPercentage), uint8(delegationPercentage))
+
+	// Send transaction
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
+	if err != nil {
+		return fmt.Errorf("failed to update pillar: %w", err)
+	}
+
+	fmt.Println("Done")
+	fmt.Printf("Pillar %s updated\n", format.Green(pillarName))
+
+	return nil
+}
+
+// printChange prints a setting, marking it when it changes
+func printChange(name, current, updated string) {
+	if current == updated {
+		fmt.Printf("  %s: %s (unchanged)\n", name, current)
+		return
+	}
+	fmt.Printf("  %s: %s -> %s\n", name, current, format.Green(updated))
+}
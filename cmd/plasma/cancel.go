@@ -4,7 +4,9 @@ import (
 	"fmt"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/plasma"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
 	"github.com/spf13/cobra"
@@ -13,28 +15,43 @@ import (
 
 // cancelCmd cancels a fusion entry by ID
 var cancelCmd = &cobra.Command{
-	Use:   "cancel <id>",
+	Use:   "cancel <id> | --all-expired",
 	Short: "Cancel fusion by ID",
 	Long: `Cancel a plasma fusion entry by its ID.
 
 Fusion entries can only be canceled after they reach their expiration height
 (10 momentums after creation, approximately 1 hour).
 
+With --all-expired, every fusion entry whose expiration height is at or
+below the frontier momentum is canceled. The blocks are chained locally and
+published without waiting for each other, and the result of each entry is
+reported.
+
 Use 'plasma list' to see fusion entry IDs and expiration heights.
 
-Example:
+Examples:
   znn-cli plasma cancel abc123...
+  znn-cli plasma cancel --all-expired
 
 Requires --keyStore flag to specify which wallet to use.`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.RangeArgs(0, 1),
 	RunE: runCancel,
 }
 
 func init() {
+	cancelCmd.Flags().Bool("all-expired", false, "cancel every expired fusion entry")
 	PlasmaCmd.AddCommand(cancelCmd)
 }
 
 func runCancel(cmdCobra *cobra.Command, args []string) error {
+	allExpired, _ := cmdCobra.Flags().GetBool("all-expired")
+	if allExpired == (len(args) == 1) {
+		return errs.New(errs.UserInput, "specify either a fusion ID or --all-expired")
+	}
+	if allExpired {
+		return runCancelAllExpired(cmdCobra)
+	}
+
 	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
 	if err != nil {
 		return err
@@ -44,7 +61,7 @@ func runCancel(cmdCobra *cobra.Command, args []string) error {
 	idStr := args[0]
 	var fusionId types.Hash
 	if err := fusionId.UnmarshalText([]byte(idStr)); err != nil {
-		return fmt.Errorf("invalid fusion ID: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid fusion ID: %w", err)
 	}
 
 	// Load wallet
@@ -59,7 +76,7 @@ func runCancel(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -111,11 +128,11 @@ func runCancel(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	if !found {
-		return fmt.Errorf("no fusion entry found with ID %s", fusionId.String())
+		return errs.Errorf(errs.UserInput, "no fusion entry found with ID %s", fusionId.String())
 	}
 
 	if gotError {
-		return fmt.Errorf("fusion entry not ready to cancel")
+		return errs.New(errs.UserInput, "fusion entry not ready to cancel")
 	}
 
 	// Create cancel template
@@ -123,7 +140,7 @@ func runCancel(cmdCobra *cobra.Command, args []string) error {
 
 	// Send transaction
 	fmt.Println("Canceling fusion entry...")
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to cancel fusion: %w", err)
 	}
@@ -133,3 +150,69 @@ func runCancel(cmdCobra *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// runCancelAllExpired cancels every expired fusion entry of the wallet address
+func runCancelAllExpired(cmdCobra *cobra.Command) error {
+	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
+	if err != nil {
+		return err
+	}
+
+	// Load wallet
+	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
+	if err != nil {
+		return err
+	}
+
+	address, err := wallet.GetAddress(keypair)
+	if err != nil {
+		return err
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	parsedAddress := types.ParseAddressPanic(address)
+
+	momentum, err := rpcClient.LedgerApi.GetFrontierMomentum()
+	if err != nil {
+		return fmt.Errorf("failed to get frontier momentum: %w", err)
+	}
+
+	fusions, err := plasma.Fusions(rpcClient.RpcClient, parsedAddress)
+	if err != nil {
+		return err
+	}
+
+	expired := plasma.ExpiredFusions(fusions, momentum.Height)
+	if len(expired) == 0 {
+		fmt.Printf("No expired fusion entries at momentum height %d\n", momentum.Height)
+		return nil
+	}
+
+	fmt.Printf("Canceling %d expired fusion entries (momentum height %d)...\n", len(expired), momentum.Height)
+
+	chain := transaction.NewChain(rpcClient.RpcClient, parsedAddress, keypair)
+	failed := 0
+	for _, entry := range expired {
+		description := fmt.Sprintf("Cancel %s QSR for %s (%s)",
+			format.Amount(entry.QsrAmount, 8), entry.Beneficiary.String(), entry.Id.String())
+		template := rpcClient.PlasmaApi.Cancel(entry.Id)
+		err := chain.Send(cmdCobra.Context(), template)
+		if err != nil {
+			failed++
+		}
+		printEntryResult(description, template.Hash, err)
+	}
+
+	if err := summarize(len(expired), failed); err != nil {
+		return err
+	}
+	fmt.Printf("Use %s to collect your QSR after 2 momentums\n", format.Green("receiveAll"))
+
+	return nil
+}
@@ -3,23 +3,22 @@ package plasma
 import (
 	"fmt"
 	"math/big"
+	"os"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/plasma"
+	"github.com/0x3639/znn_cli_go/pkg/service"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
 	"github.com/spf13/cobra"
 	"github.com/zenon-network/go-zenon/common/types"
 )
 
-const (
-	// MinFuseAmount is the minimum QSR that can be fused (10 QSR)
-	MinFuseAmount = 10 * 1e8
-)
-
 // fuseCmd fuses QSR for plasma
 var fuseCmd = &cobra.Command{
-	Use:   "fuse <beneficiaryAddress> <amount>",
+	Use:   "fuse <beneficiaryAddress|@label> <amount> | --from-file <file>",
 	Short: "Fuse QSR for beneficiary",
 	Long: `Fuse QSR tokens to generate plasma for a beneficiary address.
 
@@ -31,52 +30,110 @@ Requirements:
   - Amount must be a whole number (no decimals)
   - Sufficient QSR balance
 
-Example:
+Amount can be a whole number (1,000), max, a percentage of the QSR
+balance (50%), or an integer number of base units with --raw.
+max and percentages are rounded down to a whole QSR.
+
+With --from-file, QSR is fused for every line of a CSV file of
+"beneficiary,amount" pairs. Beneficiaries may be address book labels,
+amounts are whole QSR, and lines starting with # are ignored. The whole file
+is validated against the QSR balance first; the blocks are then chained
+locally and published without waiting for each other, and the result of
+each line is reported.
+
+Examples:
   znn-cli plasma fuse z1qz... 50
+  znn-cli plasma fuse z1qz... 50%
+  znn-cli plasma fuse --from-file beneficiaries.csv
 
 Requires --keyStore flag to specify which wallet to use.`,
-	Args: cobra.ExactArgs(2),
+	Args: cobra.RangeArgs(0, 2),
 	RunE: runFuse,
 }
 
 func init() {
+	fuseCmd.Flags().Bool("raw", false, "amount is an integer number of base units")
+	fuseCmd.Flags().String("from-file", "", "CSV file of beneficiary,amount lines to fuse for")
 	PlasmaCmd.AddCommand(fuseCmd)
 }
 
 func runFuse(cmdCobra *cobra.Command, args []string) error {
+	fromFile, _ := cmdCobra.Flags().GetString("from-file")
+	if fromFile != "" {
+		if len(args) != 0 {
+			return errs.New(errs.UserInput, "--from-file does not take a beneficiary or amount")
+		}
+		return runFuseFromFile(cmdCobra, fromFile)
+	}
+	if len(args) != 2 {
+		return errs.New(errs.UserInput, "specify a beneficiary and an amount, or --from-file")
+	}
+
 	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
 	if err != nil {
 		return err
 	}
 
-	// Parse arguments
-	beneficiaryStr := args[0]
-	amountStr := args[1]
+	raw, _ := cmdCobra.Flags().GetBool("raw")
+	req := service.FuseRequest{
+		Beneficiary: args[0],
+		Amount:      args[1],
+		Raw:         raw,
+	}
 
-	// Parse beneficiary address
-	beneficiary, err := types.ParseAddress(beneficiaryStr)
+	// Load wallet
+	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
 	if err != nil {
-		return fmt.Errorf("invalid beneficiary address: %w", err)
+		return err
 	}
 
-	// Parse amount (QSR has 8 decimals)
-	amount, err := format.ParseAmount(amountStr, 8)
+	// Connect to node
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
-		return fmt.Errorf("invalid amount: %w", err)
+		return fmt.Errorf("failed to connect to node: %w", err)
 	}
+	defer func() { _ = rpcClient.Close() }()
 
-	// Validate amount
-	if amount.Cmp(big.NewInt(MinFuseAmount)) < 0 {
-		return fmt.Errorf("invalid amount: %s QSR. Minimum fuse amount is %s",
-			format.Amount(amount, 8),
-			format.Amount(big.NewInt(MinFuseAmount), 8))
+	svc, err := service.New(cmdCobra.Context(), rpcClient.RpcClient, cfg, keypair)
+	if err != nil {
+		return err
 	}
 
-	// Check if amount is a whole number (no decimals)
-	oneQsr := big.NewInt(1e8)
-	remainder := new(big.Int).Mod(amount, oneQsr)
-	if remainder.Cmp(big.NewInt(0)) != 0 {
-		return fmt.Errorf("amount must be a whole number (no decimals)")
+	prepared, err := svc.PrepareFuse(req)
+	if err != nil {
+		return err
+	}
+
+	// Send transaction
+	beneficiary, _ := cfg.ResolveAddress(req.Beneficiary)
+	fmt.Printf("Fusing %s to %s\n", prepared.Describe(), beneficiary.String())
+
+	if _, err := svc.Submit(prepared); err != nil {
+		return fmt.Errorf("failed to fuse: %w", err)
+	}
+
+	fmt.Println("Done")
+	fmt.Println("Plasma will be available after 1 momentum")
+
+	return nil
+}
+
+// runFuseFromFile fuses QSR for every beneficiary listed in a CSV file
+func runFuseFromFile(cmdCobra *cobra.Command, path string) error {
+	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
+	if err != nil {
+		return err
+	}
+
+	// #nosec G304 - Path is provided by the user
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open beneficiaries file: %w", err)
+	}
+	requests, err := plasma.ParseFusionFile(file, cfg.ResolveAddress)
+	_ = file.Close()
+	if err != nil {
+		return err
 	}
 
 	// Load wallet
@@ -91,45 +148,47 @@ func runFuse(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
 	defer func() { _ = rpcClient.Close() }()
 
-	// Get account info to check QSR balance
-	accountInfo, err := rpcClient.LedgerApi.GetAccountInfoByAddress(types.ParseAddressPanic(address))
+	parsedAddress := types.ParseAddressPanic(address)
+
+	info, err := rpcClient.LedgerApi.GetAccountInfoByAddress(parsedAddress)
 	if err != nil {
 		return fmt.Errorf("failed to get account info: %w", err)
 	}
-
-	// Check QSR balance
-	qsrBalance, found := accountInfo.BalanceInfoMap[types.QsrTokenStandard]
-	if !found || qsrBalance.Balance.Cmp(amount) < 0 {
-		currentBalance := big.NewInt(0)
-		if found {
-			currentBalance = qsrBalance.Balance
-		}
-		return fmt.Errorf("insufficient QSR balance. You have %s but need %s",
-			format.Amount(currentBalance, 8),
-			format.Amount(amount, 8))
+	balance := big.NewInt(0)
+	if balanceInfo, found := info.BalanceInfoMap[types.QsrTokenStandard]; found && balanceInfo.Balance != nil {
+		balance = balanceInfo.Balance
 	}
 
-	// Create fuse template
-	template := rpcClient.PlasmaApi.Fuse(beneficiary, amount)
-
-	// Send transaction
-	fmt.Printf("Fusing %s %s to %s\n",
-		format.Amount(amount, 8),
-		format.Blue("QSR"),
-		beneficiary.String())
+	total := plasma.TotalQsr(requests)
+	if balance.Cmp(total) < 0 {
+		return errs.Errorf(errs.InsufficientFunds, "insufficient QSR balance. You have %s but the file needs %s",
+			format.Amount(balance, 8), format.Amount(total, 8))
+	}
 
-	err = transaction.BuildAndSend(rpcClient.RpcClient, types.ParseAddressPanic(address), template, keypair)
-	if err != nil {
-		return fmt.Errorf("failed to fuse: %w", err)
+	fmt.Printf("Fusing %s QSR for %d beneficiaries...\n", format.Amount(total, 8), len(requests))
+
+	chain := transaction.NewChain(rpcClient.RpcClient, parsedAddress, keypair)
+	failed := 0
+	for _, request := range requests {
+		description := fmt.Sprintf("Line %d: fuse %s QSR to %s",
+			request.Line, format.Amount(request.Amount, 8), request.Beneficiary.String())
+		template := rpcClient.PlasmaApi.Fuse(request.Beneficiary, request.Amount)
+		err := chain.Send(cmdCobra.Context(), template)
+		if err != nil {
+			failed++
+		}
+		printEntryResult(description, template.Hash, err)
 	}
 
-	fmt.Println("Done")
+	if err := summarize(len(requests), failed); err != nil {
+		return err
+	}
 	fmt.Println("Plasma will be available after 1 momentum")
 
 	return nil
@@ -0,0 +1,171 @@
+package plasma
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/confirm"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/plasma"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// keeperCmd keeps the plasma of beneficiaries above a threshold
+var keeperCmd = &cobra.Command{
+	Use:   "keeper",
+	Short: "Keep beneficiaries' plasma above a threshold",
+	// Runs unattended: blocks are not confirmed one by one
+	Annotations: map[string]string{confirm.Annotation: confirm.Unattended},
+	Long: `Watch the plasma of a set of beneficiaries and fuse QSR to them from the
+wallet address whenever their current plasma drops below --min-plasma, so
+their transactions do not fall back to slow PoW.
+
+With --cancel-expired, expired fusions of the wallet address are cancelled
+when their beneficiary keeps at least --min-plasma without them. Fusions
+to addresses outside the beneficiary set are never cancelled.
+
+Beneficiaries default to the wallet address. Runs every --interval until
+interrupted; use --once for a single check.
+
+Examples:
+  znn-cli plasma keeper --beneficiary z1qz... --beneficiary @bot --fuse 50
+  znn-cli plasma keeper --min-plasma 105000 --cancel-expired --once
+
+Requires --keyStore flag to specify the funding wallet.`,
+	RunE: runKeeper,
+}
+
+func init() {
+	keeperCmd.Flags().StringSlice("beneficiary", nil, "address to keep topped up (z1 address or @label; repeatable)")
+	keeperCmd.Flags().Uint64("min-plasma", 42000, "current plasma below which QSR is fused (one send costs 21000)")
+	keeperCmd.Flags().String("fuse", "100", "QSR fused per top-up")
+	keeperCmd.Flags().Bool("cancel-expired", false, "cancel expired fusions that are no longer needed")
+	keeperCmd.Flags().Duration("interval", 5*time.Minute, "time between checks")
+	keeperCmd.Flags().Bool("once", false, "check once, then exit")
+	PlasmaCmd.AddCommand(keeperCmd)
+}
+
+func runKeeper(cmdCobra *cobra.Command, args []string) error {
+	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
+	if err != nil {
+		return err
+	}
+
+	beneficiaryValues, _ := cmdCobra.Flags().GetStringSlice("beneficiary")
+	fuseStr, _ := cmdCobra.Flags().GetString("fuse")
+	interval, _ := cmdCobra.Flags().GetDuration("interval")
+	once, _ := cmdCobra.Flags().GetBool("once")
+
+	policy := plasma.Policy{}
+	policy.MinPlasma, _ = cmdCobra.Flags().GetUint64("min-plasma")
+	policy.CancelExpired, _ = cmdCobra.Flags().GetBool("cancel-expired")
+	if policy.FuseAmount, err = format.ParseAmount(fuseStr, 8); err != nil {
+		return errs.Errorf(errs.UserInput, "invalid fuse amount: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if interval < 10*time.Second {
+		return errs.New(errs.UserInput, "interval must be at least 10s")
+	}
+
+	// Load wallet
+	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
+	if err != nil {
+		return err
+	}
+
+	address, err := wallet.GetAddress(keypair)
+	if err != nil {
+		return err
+	}
+	funder := types.ParseAddressPanic(address)
+
+	beneficiaries := []types.Address{funder}
+	if len(beneficiaryValues) > 0 {
+		beneficiaries = beneficiaries[:0]
+		for _, value := range beneficiaryValues {
+			beneficiary, err := cfg.ResolveAddress(value)
+			if err != nil {
+				return errs.Errorf(errs.UserInput, "invalid beneficiary %s: %w", value, err)
+			}
+			beneficiaries = append(beneficiaries, beneficiary)
+		}
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	keeper := &plasma.Keeper{
+		Client:        rpcClient.RpcClient,
+		Funder:        funder,
+		KeyPair:       keypair,
+		Beneficiaries: beneficiaries,
+		Policy:        policy,
+	}
+
+	ctx := cmdCobra.Context()
+
+	fmt.Printf("Keeping %d beneficiary(ies) above %d plasma, fusing %s %s from %s\n",
+		len(beneficiaries), policy.MinPlasma, format.Amount(policy.FuseAmount, 8), format.Blue("QSR"), format.Green(address))
+
+	for {
+		actions, err := keeper.Run(ctx)
+		if err != nil {
+			if once {
+				return err
+			}
+			format.Warning(err.Error())
+		}
+		failed := 0
+		for _, action := range actions {
+			printKeeperAction(action)
+			if action.Err != nil {
+				failed++
+			}
+		}
+		if err == nil && len(actions) == 0 {
+			fmt.Printf("%s all beneficiaries have enough plasma\n", time.Now().Format("2006-01-02 15:04:05"))
+		}
+
+		if once {
+			if failed > 0 {
+				return fmt.Errorf("%d action(s) failed", failed)
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("Keeper stopped")
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// printKeeperAction prints a keeper action and its outcome
+func printKeeperAction(action *plasma.Action) {
+	var description string
+	switch action.Kind {
+	case plasma.ActionFuse:
+		description = fmt.Sprintf("Fuse %s QSR to %s", format.Amount(action.Amount, 8), action.Beneficiary.String())
+	case plasma.ActionCancel:
+		description = fmt.Sprintf("Cancel fusion %s of %s QSR for %s", action.ID.String(), format.Amount(action.Amount, 8), action.Beneficiary.String())
+	}
+
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	if action.Err != nil {
+		fmt.Printf("%s %s (%s): %s\n", timestamp, description, action.Reason, format.Red("failed: "+action.Err.Error()))
+		return
+	}
+	fmt.Printf("%s %s (%s): %s\n", timestamp, description, action.Reason, format.Cyan(action.Hash.String()))
+}
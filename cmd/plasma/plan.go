@@ -0,0 +1,137 @@
+package plasma
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/plasma"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// planCmd estimates the QSR to fuse for a number of transactions
+var planCmd = &cobra.Command{
+	Use:   "plan <count>",
+	Short: "Estimate the QSR to fuse for N transactions",
+	Long: `Estimate how much QSR must be fused so that count transactions of a
+given type can be sent back to back with plasma instead of PoW.
+
+The plasma cost of one transaction is queried from the node. Plasma spent
+by a transaction is available again once it is confirmed, so count is the
+number of transactions sent in a burst. The QSR already fused for the
+address is taken into account.
+
+Transaction types: ` + strings.Join(plasma.TxTypes, ", ") + `
+Use --memo-size to include the data cost of a memo in sends.
+
+Examples:
+  znn-cli plasma plan 50
+  znn-cli plasma plan 20 --type send --memo-size 64
+  znn-cli plasma plan 10 --type stake --address z1qz...
+
+Requires --keyStore flag unless --address is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPlan,
+}
+
+func init() {
+	planCmd.Flags().String("type", plasma.TxSend, "transaction type ("+strings.Join(plasma.TxTypes, ", ")+")")
+	planCmd.Flags().Int("memo-size", 0, "memo size in bytes for sends")
+	planCmd.Flags().String("address", "", "address to plan for (z1 address or @label)")
+	PlasmaCmd.AddCommand(planCmd)
+}
+
+func runPlan(cmdCobra *cobra.Command, args []string) error {
+	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
+	if err != nil {
+		return err
+	}
+
+	count, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil || count == 0 {
+		return errs.New(errs.UserInput, "invalid count: must be a positive number")
+	}
+	txType, _ := cmdCobra.Flags().GetString("type")
+	memoSize, _ := cmdCobra.Flags().GetInt("memo-size")
+	addressFlag, _ := cmdCobra.Flags().GetString("address")
+
+	if memoSize < 0 {
+		return errs.New(errs.UserInput, "memo size must not be negative")
+	}
+	if memoSize > 0 && txType != plasma.TxSend {
+		return errs.New(errs.UserInput, "--memo-size only applies to sends")
+	}
+
+	var address types.Address
+	if addressFlag != "" {
+		if address, err = cfg.ResolveAddress(addressFlag); err != nil {
+			return errs.Errorf(errs.UserInput, "invalid address: %w", err)
+		}
+	} else {
+		_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
+		if err != nil {
+			return err
+		}
+		walletAddress, err := wallet.GetAddress(keypair)
+		if err != nil {
+			return err
+		}
+		address = types.ParseAddressPanic(walletAddress)
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	template, err := plasma.Template(rpcClient.RpcClient, txType, address, memoSize)
+	if err != nil {
+		return err
+	}
+	basePlasma, err := plasma.BasePlasma(cmdCobra.Context(), rpcClient.RpcClient, address, template)
+	if err != nil {
+		return err
+	}
+
+	info, err := rpcClient.PlasmaApi.Get(address)
+	if err != nil {
+		return fmt.Errorf("failed to get plasma info: %w", err)
+	}
+
+	plan := plasma.NewPlan(basePlasma, count, info)
+
+	fmt.Printf("Plasma plan for %s\n", address.String())
+	fmt.Printf("  Transaction type: %s", txType)
+	if memoSize > 0 {
+		fmt.Printf(" (%d byte memo)", memoSize)
+	}
+	fmt.Println()
+	fmt.Printf("  Plasma per transaction: %d\n", plan.BasePlasma)
+	fmt.Printf("  Required for %d transaction(s): %d\n", plan.Count, plan.Required)
+	fmt.Printf("  Fused: %s %s (max plasma %d, current %d)\n",
+		format.Amount(plan.FusedQsr, 8), format.Blue("QSR"), plan.Available, info.CurrentPlasma)
+	fmt.Println()
+
+	if plan.FuseQsr.Sign() == 0 && !plan.Capped {
+		fmt.Println(format.Green("The fused QSR already covers this budget"))
+		return nil
+	}
+	if plan.FuseQsr.Sign() > 0 {
+		whole := new(big.Int).Div(plan.FuseQsr, big.NewInt(1e8)).String()
+		fmt.Printf("Fuse %s %s more (%d plasma per QSR)\n", whole, format.Blue("QSR"), plasma.PlasmaPerQsr)
+		fmt.Printf("Use %s\n", format.Green(fmt.Sprintf("plasma fuse %s %s", address.String(), whole)))
+	}
+	if plan.Capped {
+		format.Warning(fmt.Sprintf("An address can have at most %d QSR fused; the remaining transactions need PoW", plasma.MaxFusedQsr))
+	}
+
+	return nil
+}
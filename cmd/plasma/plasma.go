@@ -17,7 +17,9 @@ Available subcommands:
   list   - List fusion entries
   get    - Get plasma info for current address
   fuse   - Fuse QSR for beneficiary
-  cancel - Cancel fusion by ID`,
+  cancel - Cancel fusion by ID
+  plan   - Estimate the QSR to fuse for N transactions
+  keeper - Keep beneficiaries' plasma above a threshold`,
 }
 
 func init() {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
@@ -43,7 +44,7 @@ func runReceive(cmd *cobra.Command, args []string) error {
 	var blockHash types.Hash
 	err := blockHash.UnmarshalText([]byte(blockHashStr))
 	if err != nil {
-		return fmt.Errorf("invalid block hash: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid block hash: %w", err)
 	}
 
 	// Load wallet
@@ -58,7 +59,7 @@ func runReceive(cmd *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmd.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -75,7 +76,7 @@ func runReceive(cmd *cobra.Command, args []string) error {
 
 	// Receive transaction
 	fmt.Println("Receiving transaction...")
-	err = transaction.BuildAndSend(rpcClient.RpcClient, types.ParseAddressPanic(address), template, keypair)
+	err = transaction.BuildAndSend(cmd.Context(), rpcClient.RpcClient, types.ParseAddressPanic(address), template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to receive transaction: %w", err)
 	}
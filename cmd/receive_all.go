@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
 	"github.com/0x3639/znn_cli_go/pkg/format"
@@ -49,7 +50,7 @@ func runReceiveAll(cmd *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmd.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -81,34 +82,11 @@ func runReceiveAll(cmd *cobra.Command, args []string) error {
 	fmt.Println("Receiving transactions...")
 
 	// Receive all blocks in batches
-	receivedCount := 0
-	for len(blocks.List) > 0 {
-		// Receive each block in current batch
-		for _, block := range blocks.List {
-			template := &nom.AccountBlock{
-				Version:         1,
-				ChainIdentifier: 1,
-				BlockType:       nom.BlockTypeUserReceive,
-				FromBlockHash:   block.Hash,
-				Data:            nil,
-			}
-
-			err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
-			if err != nil {
-				return fmt.Errorf("failed to receive block %s: %w", block.Hash, err)
-			}
-
-			receivedCount++
-			if cfg.Display.Verbose {
-				fmt.Printf("  Received %s\n", format.Cyan(block.Hash.String()))
-			}
-		}
-
-		// Get next batch
-		blocks, err = rpcClient.LedgerApi.GetUnreceivedBlocksByAddress(parsedAddress, 0, 5)
-		if err != nil {
-			return fmt.Errorf("failed to get unreceived blocks: %w", err)
-		}
+	receivedCount, err := transaction.ReceiveAll(cmd.Context(), rpcClient.RpcClient, parsedAddress, keypair, func(block *nom.AccountBlock) {
+		slog.Info("received block", "hash", block.Hash, "from", block.FromBlockHash)
+	})
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("Successfully received %s transaction(s)\n", format.Green(fmt.Sprintf("%d", receivedCount)))
@@ -0,0 +1,143 @@
+package rewards
+
+import (
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/rewards"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// collectCmd collects the rewards of every source and receives them
+var collectCmd = &cobra.Command{
+	Use:   "collect",
+	Short: "Collect all rewards and receive them",
+	Long: `Collect the rewards of every source with a non-zero uncollected balance,
+then wait for the reward blocks to arrive and receive them.
+
+The contracts pay collected rewards a few momentums after the collect
+blocks are confirmed. The command waits up to --wait for them; use
+--no-receive to only publish the collect blocks and receive later with
+'receiveAll'.
+
+Examples:
+  znn-cli rewards collect
+  znn-cli rewards collect --source pillar --source sentinel
+  znn-cli rewards collect --no-receive
+
+Requires --keyStore flag to specify which wallet to use.`,
+	RunE: runCollect,
+}
+
+func init() {
+	collectCmd.Flags().StringSlice("source", nil, "reward source to collect (stake, pillar, sentinel, liquidity; repeatable)")
+	collectCmd.Flags().Duration("wait", 3*time.Minute, "how long to wait for the rewards to arrive")
+	collectCmd.Flags().Bool("no-receive", false, "only publish the collect blocks")
+	RewardsCmd.AddCommand(collectCmd)
+}
+
+func runCollect(cmdCobra *cobra.Command, args []string) error {
+	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
+	if err != nil {
+		return err
+	}
+	sourceNames, _ := cmdCobra.Flags().GetStringSlice("source")
+	wait, _ := cmdCobra.Flags().GetDuration("wait")
+	noReceive, _ := cmdCobra.Flags().GetBool("no-receive")
+
+	sources, err := rewards.Lookup(sourceNames)
+	if err != nil {
+		return err
+	}
+
+	// Load wallet
+	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
+	if err != nil {
+		return err
+	}
+
+	address, err := wallet.GetAddress(keypair)
+	if err != nil {
+		return err
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	parsedAddress := types.ParseAddressPanic(address)
+
+	// Blocks already waiting to be received are not counted as rewards
+	pendingZnn, pendingQsr, err := rewards.Pending(rpcClient.RpcClient, parsedAddress)
+	if err != nil {
+		return err
+	}
+
+	collected, err := rewards.Collect(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, keypair, sources)
+	for _, entry := range collected {
+		fmt.Printf("Collected %s rewards: %s %s, %s %s (%s)\n", entry.Source.Name,
+			format.Amount(entry.Znn, 8), format.Green("ZNN"),
+			format.Amount(entry.Qsr, 8), format.Blue("QSR"),
+			format.Cyan(entry.Hash.String()))
+	}
+	if err != nil {
+		return err
+	}
+	if len(collected) == 0 {
+		fmt.Println("Nothing to collect")
+		return nil
+	}
+
+	if noReceive {
+		fmt.Println("Done")
+		fmt.Printf("Use %s to receive the rewards\n", format.Green("receiveAll"))
+		return nil
+	}
+
+	// Wait for the contracts to pay the rewards
+	rewardList := make([]*rewards.Reward, 0, len(collected))
+	for _, entry := range collected {
+		rewardList = append(rewardList, &entry.Reward)
+	}
+	znn, qsr := rewards.Total(rewardList)
+	targetZnn := new(big.Int).Add(pendingZnn, znn)
+	targetQsr := new(big.Int).Add(pendingQsr, qsr)
+
+	fmt.Println("Waiting for the rewards to arrive...")
+	err = transaction.WaitUntil(cmdCobra.Context(), wait, transaction.DefaultWaitInterval, func() (bool, error) {
+		currentZnn, currentQsr, err := rewards.Pending(rpcClient.RpcClient, parsedAddress)
+		if err != nil {
+			return false, err
+		}
+		return currentZnn.Cmp(targetZnn) >= 0 && currentQsr.Cmp(targetQsr) >= 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("rewards were collected but have not arrived yet: %w; use 'receiveAll' later", err)
+	}
+
+	receivedCount, err := transaction.ReceiveAll(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, keypair, func(block *nom.AccountBlock) {
+		slog.Info("received block", "hash", block.Hash, "from", block.FromBlockHash)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Done")
+	fmt.Printf("Received %s transaction(s): %s %s, %s %s collected\n",
+		format.Green(fmt.Sprintf("%d", receivedCount)),
+		format.Amount(znn, 8), format.Green("ZNN"),
+		format.Amount(qsr, 8), format.Blue("QSR"))
+
+	return nil
+}
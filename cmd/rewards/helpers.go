@@ -0,0 +1,45 @@
+package rewards
+
+import (
+	"github.com/0x3639/znn_cli_go/pkg/config"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// getConfigAndFlags extracts configuration and flags from the command
+func getConfigAndFlags(cmd *cobra.Command) (*config.Config, string, string, int, error) {
+	keystoreName, _ := cmd.Flags().GetString("keyStore")
+	passphrase, _ := cmd.Flags().GetString("passphrase")
+	index, _ := cmd.Flags().GetInt("index")
+	url, _ := cmd.Flags().GetString("url")
+	configFile, _ := cmd.Flags().GetString("config")
+
+	// Load configuration
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	if url != "" {
+		cfg.Node.URL = url
+	}
+
+	return cfg, keystoreName, passphrase, index, nil
+}
+
+// resolveAddress returns the --address flag, or the wallet address when it is not given
+func resolveAddress(cmd *cobra.Command, cfg *config.Config, keystoreName, passphrase string, index int) (types.Address, error) {
+	if value, _ := cmd.Flags().GetString("address"); value != "" {
+		return cfg.ResolveAddress(value)
+	}
+
+	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
+	if err != nil {
+		return types.ZeroAddress, err
+	}
+	address, err := wallet.GetAddress(keypair)
+	if err != nil {
+		return types.ZeroAddress, err
+	}
+	return types.ParseAddress(address)
+}
@@ -0,0 +1,103 @@
+package rewards
+
+import (
+	"fmt"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/rewards"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd shows the reward history of every source
+var historyCmd = &cobra.Command{
+	Use:   "history [pageIndex pageSize]",
+	Short: "Show the per-epoch reward history",
+	Long: `Show the rewards received per epoch from every reward source, most
+recent first. Sources without any history are skipped.
+
+Optional pagination parameters, applied to each source:
+  pageIndex - Page number (default: 0)
+  pageSize  - Epochs per page (default: 10)
+
+Examples:
+  znn-cli rewards history
+  znn-cli rewards history 1 20 --source pillar
+  znn-cli rewards history --address @treasury
+
+Requires --keyStore flag unless --address is given.`,
+	Args: cobra.RangeArgs(0, 2),
+	RunE: runHistory,
+}
+
+func init() {
+	historyCmd.Flags().String("address", "", "address to inspect (z1 address or @label)")
+	historyCmd.Flags().StringSlice("source", nil, "reward source to show (stake, pillar, sentinel, liquidity; repeatable)")
+	RewardsCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmdCobra *cobra.Command, args []string) error {
+	// Parse pagination
+	pageIndex := uint32(0)
+	pageSize := uint32(10)
+	if len(args) >= 1 {
+		// Ignore error - default value used on parse failure
+		_, _ = fmt.Sscanf(args[0], "%d", &pageIndex)
+	}
+	if len(args) >= 2 {
+		// Ignore error - default value used on parse failure
+		_, _ = fmt.Sscanf(args[1], "%d", &pageSize)
+	}
+
+	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
+	if err != nil {
+		return err
+	}
+	sourceNames, _ := cmdCobra.Flags().GetStringSlice("source")
+
+	sources, err := rewards.Lookup(sourceNames)
+	if err != nil {
+		return err
+	}
+
+	address, err := resolveAddress(cmdCobra, cfg, keystoreName, passphrase, index)
+	if err != nil {
+		return err
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	fmt.Printf("Reward history of %s\n", address.String())
+
+	found := false
+	for _, source := range sources {
+		history, err := source.History(rpcClient.RpcClient, address, pageIndex, pageSize)
+		if err != nil {
+			return err
+		}
+		if history.Count == 0 {
+			continue
+		}
+		found = true
+
+		fmt.Println()
+		fmt.Printf("%s (%d epoch(s))\n", format.Green(source.Name), history.Count)
+		fmt.Printf("  %-8s  %20s  %20s\n", "Epoch", "ZNN", "QSR")
+		for _, entry := range history.List {
+			fmt.Printf("  %-8d  %20s  %20s\n", entry.Epoch,
+				format.Amount(entry.Znn, 8), format.Amount(entry.Qsr, 8))
+		}
+	}
+
+	if !found {
+		fmt.Println()
+		fmt.Println("No reward history found")
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package rewards
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// RewardsCmd is the root command for reward operations
+var RewardsCmd = &cobra.Command{
+	Use:   "rewards",
+	Short: "Reward operations across all sources",
+	Long: `Show, collect and list the rewards of every reward source at once.
+
+Reward sources:
+  stake     - Staked ZNN
+  pillar    - Pillar operation and delegation
+  sentinel  - Sentinel operation
+  liquidity - Staked liquidity tokens
+
+Available subcommands:
+  show    - Show uncollected rewards
+  collect - Collect all rewards and receive them
+  history - Show the per-epoch reward history`,
+}
+
+func init() {
+	// Subcommands will register themselves
+}
@@ -0,0 +1,81 @@
+package rewards
+
+import (
+	"fmt"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/rewards"
+	"github.com/spf13/cobra"
+)
+
+// showCmd shows the uncollected rewards of every source
+var showCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show uncollected rewards",
+	Long: `Show the uncollected rewards of every reward source and their total.
+
+Use --source to limit the sources and --address to inspect any address
+without loading a wallet.
+
+Examples:
+  znn-cli rewards show
+  znn-cli rewards show --address @treasury
+  znn-cli rewards show --source stake --source liquidity
+
+Requires --keyStore flag unless --address is given.`,
+	RunE: runShow,
+}
+
+func init() {
+	showCmd.Flags().String("address", "", "address to inspect (z1 address or @label)")
+	showCmd.Flags().StringSlice("source", nil, "reward source to show (stake, pillar, sentinel, liquidity; repeatable)")
+	RewardsCmd.AddCommand(showCmd)
+}
+
+func runShow(cmdCobra *cobra.Command, args []string) error {
+	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
+	if err != nil {
+		return err
+	}
+	sourceNames, _ := cmdCobra.Flags().GetStringSlice("source")
+
+	sources, err := rewards.Lookup(sourceNames)
+	if err != nil {
+		return err
+	}
+
+	address, err := resolveAddress(cmdCobra, cfg, keystoreName, passphrase, index)
+	if err != nil {
+		return err
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	uncollected, err := rewards.UncollectedAll(rpcClient.RpcClient, address, sources)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Uncollected rewards of %s\n\n", address.String())
+	fmt.Printf("%-10s  %20s  %20s\n", "Source", "ZNN", "QSR")
+	for _, reward := range uncollected {
+		fmt.Printf("%-10s  %20s  %20s\n", reward.Source.Name,
+			format.Amount(reward.Znn, 8), format.Amount(reward.Qsr, 8))
+	}
+
+	znn, qsr := rewards.Total(uncollected)
+	fmt.Printf("%-10s  %20s  %20s\n", "Total", format.Amount(znn, 8), format.Amount(qsr, 8))
+
+	if znn.Sign() > 0 || qsr.Sign() > 0 {
+		fmt.Println()
+		fmt.Printf("Use %s to collect and receive them\n", format.Green("rewards collect"))
+	}
+
+	return nil
+}
@@ -2,16 +2,30 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/0x3639/znn_cli_go/cmd/pillar"
 	"github.com/0x3639/znn_cli_go/cmd/plasma"
+	"github.com/0x3639/znn_cli_go/cmd/rewards"
 	"github.com/0x3639/znn_cli_go/cmd/sentinel"
 	"github.com/0x3639/znn_cli_go/cmd/stake"
 	"github.com/0x3639/znn_cli_go/cmd/token"
+	"github.com/0x3639/znn_cli_go/pkg/client"
 	"github.com/0x3639/znn_cli_go/pkg/config"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/journal"
+	"github.com/0x3639/znn_cli_go/pkg/logging"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/chain/nom"
 )
 
 var (
@@ -24,6 +38,19 @@ var (
 	passphrase string
 	index      int
 	verbose    bool
+	yes        bool
+	traceRPC   bool
+	recordFile string
+	replayFile string
+
+	// configErr is the error that made initConfig fall back to defaults
+	configErr error
+
+	// logFile closes the log file, if any, on exit
+	logFile io.Closer
+
+	// recorder records the RPC session with --record
+	recorder *client.Recorder
 
 	// cfg holds the application configuration
 	cfg *config.Config
@@ -41,6 +68,18 @@ including wallet management, token transfers, staking, plasma operations, and mo
 For more information, visit: https://github.com/0x3639/znn_cli_go`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := startLogging(); err != nil {
+			return err
+		}
+		cmd.SetContext(client.WithRequestTimeout(cmd.Context(), cfg.Node.Timeout))
+		if err := startRecording(cmd); err != nil {
+			return err
+		}
+		startJournal(cmd)
+		startSignGuard(cmd)
+		return nil
+	},
 }
 
 // RootCmd returns the root command for use in subcommand packages
@@ -51,18 +90,94 @@ func RootCmd() *cobra.Command {
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	rootCmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return errs.Wrap(errs.UserInput, err)
+	})
+	userInputErrors(rootCmd)
+
+	ctx, stop := interruptContext()
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if recorder != nil {
+		if closeErr := recorder.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write RPC recording: %v\n", closeErr)
+		}
+	}
+	if logFile != nil {
+		_ = logFile.Close()
+	}
+	if err != nil {
+		if errs.KindOf(err) == errs.Internal && isUsageError(err) {
+			err = errs.Wrap(errs.UserInput, err)
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(errs.ExitCode(err))
 	}
 }
 
+// interruptGrace is how long an interrupted command has to return before
+// the process exits anyway
+const interruptGrace = 10 * time.Second
+
+// interruptContext returns a context cancelled on SIGINT or SIGTERM, which
+// makes node calls in progress return at once. Commands blocked elsewhere,
+// such as at a passphrase prompt, are ended by a second signal or after
+// interruptGrace.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+		case <-ctx.Done():
+			return
+		}
+		cancel()
+		select {
+		case <-signals:
+		case <-time.After(interruptGrace):
+		}
+		fmt.Fprintln(os.Stderr, "Error: interrupted")
+		os.Exit(errs.Interrupted.ExitCode())
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+// userInputErrors gives argument errors of a command and its subcommands
+// the UserInput kind
+func userInputErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(c *cobra.Command, args []string) error {
+			return errs.Wrap(errs.UserInput, validate(c, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		userInputErrors(sub)
+	}
+}
+
+// isUsageError reports whether an error is one cobra returns for unknown
+// commands or missing required flags
+func isUsageError(err error) bool {
+	message := err.Error()
+	return strings.HasPrefix(message, "unknown command") ||
+		strings.HasPrefix(message, "required flag") ||
+		strings.Contains(message, "if any flags in the group")
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Register subcommand groups
 	rootCmd.AddCommand(pillar.PillarCmd)
 	rootCmd.AddCommand(plasma.PlasmaCmd)
+	rootCmd.AddCommand(rewards.RewardsCmd)
 	rootCmd.AddCommand(sentinel.SentinelCmd)
 	rootCmd.AddCommand(stake.StakeCmd)
 	rootCmd.AddCommand(token.TokenCmd)
@@ -74,6 +189,11 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&passphrase, "passphrase", "p", "", "wallet passphrase (will prompt if not provided)")
 	rootCmd.PersistentFlags().IntVarP(&index, "index", "i", 0, "address index in wallet")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVar(&traceRPC, "trace-rpc", false, "log every JSON-RPC request and response with timings")
+	rootCmd.PersistentFlags().StringVar(&recordFile, "record", "", "record every RPC request and response to a file")
+	rootCmd.PersistentFlags().StringVar(&replayFile, "replay", "", "answer RPC requests from a recording instead of a node")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
+	rootCmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "skip transaction confirmation prompts (not on mainnet unless confirm.mainnet_guard is false)")
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -81,10 +201,8 @@ func initConfig() {
 	var err error
 	cfg, err = config.Load(cfgFile)
 	if err != nil {
-		if verbose {
-			fmt.Fprintf(os.Stderr, "Warning: Failed to load config: %v\n", err)
-		}
-		// Use default config if loading fails
+		// Use default config if loading fails; reported once logging starts
+		configErr = err
 		cfg = config.DefaultConfig()
 	}
 
@@ -101,6 +219,24 @@ func initConfig() {
 	if verbose {
 		cfg.Display.Verbose = true
 	}
+	if traceRPC {
+		cfg.Display.TraceRPC = true
+	}
+}
+
+// startLogging makes the logger configured in the display settings the
+// default logger
+func startLogging() error {
+	logger, closer, err := logging.New(cfg.Display, os.Stderr)
+	if err != nil {
+		return errs.Wrap(errs.UserInput, err)
+	}
+	logFile = closer
+	slog.SetDefault(logger)
+	if configErr != nil {
+		slog.Warn("failed to load config, using defaults", "error", configErr)
+	}
+	return nil
 }
 
 // GetConfig returns the current configuration
@@ -108,6 +244,11 @@ func GetConfig() *config.Config {
 	return cfg
 }
 
+// GetConfigFile returns the config file path from flag (empty for default location)
+func GetConfigFile() string {
+	return cfgFile
+}
+
 // GetPassphrase returns the passphrase from flag
 func GetPassphrase() string {
 	return passphrase
@@ -128,3 +269,46 @@ func GetIndex() int {
 	}
 	return cfg.Wallet.DefaultIndex
 }
+
+// startRecording records the RPC session to --record, or answers it from
+// --replay instead of the node
+func startRecording(cmd *cobra.Command) error {
+	switch {
+	case recordFile != "":
+		r, err := client.NewRecorder(recordFile)
+		if err != nil {
+			return errs.Wrap(errs.UserInput, err)
+		}
+		recorder = r
+		cmd.SetContext(client.WithRecorder(cmd.Context(), r))
+	case replayFile != "":
+		replay, err := client.LoadReplay(replayFile)
+		if err != nil {
+			return errs.Wrap(errs.UserInput, err)
+		}
+		slog.Info("replaying RPC session", "file", replayFile, "exchanges", replay.Len())
+		cmd.SetContext(client.WithReplay(cmd.Context(), replay))
+	}
+	return nil
+}
+
+// startJournal records every block published by the command in the
+// transaction journal. Journal failures are reported but do not fail the
+// command, since the block has already been published.
+func startJournal(cmd *cobra.Command) {
+	// Blocks "published" to a replayed session never reached a node
+	if cfg.Journal.Disabled || cfg.Journal.Path == "" || replayFile != "" {
+		return
+	}
+	recorder := &journal.Recorder{
+		Journal:  &journal.Journal{Path: cfg.Journal.Path},
+		Command:  cmd.CommandPath(),
+		Args:     journal.RedactArgs(os.Args[1:]),
+		KeyStore: keyStoreOf,
+	}
+	transaction.SetPublishRecorder(func(block *nom.AccountBlock, err error) {
+		if err := recorder.Record(block, err); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write transaction journal: %v\n", err)
+		}
+	})
+}
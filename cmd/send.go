@@ -2,20 +2,19 @@ package cmd
 
 import (
 	"fmt"
-	"math/big"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/service"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
 	"github.com/spf13/cobra"
-	"github.com/zenon-network/go-zenon/chain/nom"
-	"github.com/zenon-network/go-zenon/common/types"
 )
 
 // sendCmd sends tokens to an address
 var sendCmd = &cobra.Command{
-	Use:   "send <toAddress> <amount> <token>",
+	Use:   "send <toAddress|@label> <amount> <token>",
 	Short: "Send tokens to an address",
 	Long: `Send ZNN, QSR, or custom ZTS tokens to a destination address.
 
@@ -23,18 +22,43 @@ Examples:
   znn-cli send z1qz... 10.5 ZNN
   znn-cli send z1qz... 100 QSR
   znn-cli send z1qz... 5.25 zts1...
+  znn-cli send @treasury-0 10 ZNN
+  znn-cli send z1qz... 10 ZNN --memo "invoice 2024-117"
+  znn-cli send z1qz... 10 ZNN --data-hex 0a0b0c
+  znn-cli send z1qz... max QSR
+  znn-cli send z1qz... 50% ZNN
+  znn-cli send z1qz... 1,000 ZNN
+  znn-cli send z1qz... 150000000 ZNN --raw
+
+Amount can be:
+  - A decimal amount, optionally with thousands separators (1,000.5)
+  - max, to send the full balance
+  - A percentage of the balance (50%)
+  - An integer number of base units with --raw
 
 Token can be:
   - ZNN (Zenon coin)
   - QSR (Quasar coin)
   - zts1... (Custom ZTS token standard)
 
+The destination can be an address or the @label of a known account
+(see 'wallet discover --save').
+
+A payload can be attached with --memo (UTF-8 text), --data-hex or
+--data-base64, up to 16 KB. Larger blocks require more plasma; the
+requirement is shown before sending.
+
 Requires --keyStore flag to specify which wallet to use.`,
 	Args: cobra.ExactArgs(3),
 	RunE: runSend,
 }
 
 func init() {
+	sendCmd.Flags().String("memo", "", "UTF-8 memo to attach")
+	sendCmd.Flags().String("data-hex", "", "hex-encoded data to attach")
+	sendCmd.Flags().String("data-base64", "", "base64-encoded data to attach")
+	sendCmd.MarkFlagsMutuallyExclusive("memo", "data-hex", "data-base64")
+	sendCmd.Flags().Bool("raw", false, "amount is an integer number of base units")
 	rootCmd.AddCommand(sendCmd)
 }
 
@@ -44,110 +68,86 @@ func runSend(cmd *cobra.Command, args []string) error {
 	passphrase := GetPassphrase()
 	index := GetIndex()
 
-	// Parse arguments
-	toAddressStr := args[0]
-	amountStr := args[1]
-	tokenStr := args[2]
-
-	// Parse destination address
-	toAddress, err := types.ParseAddress(toAddressStr)
-	if err != nil {
-		return fmt.Errorf("invalid destination address: %w", err)
+	memo, _ := cmd.Flags().GetString("memo")
+	dataHex, _ := cmd.Flags().GetString("data-hex")
+	dataBase64, _ := cmd.Flags().GetString("data-base64")
+	raw, _ := cmd.Flags().GetBool("raw")
+
+	req := service.SendRequest{
+		To:         args[0],
+		Amount:     args[1],
+		Token:      args[2],
+		Raw:        raw,
+		Memo:       memo,
+		DataHex:    dataHex,
+		DataBase64: dataBase64,
 	}
 
-	// Parse token standard
-	var tokenStandard types.ZenonTokenStandard
-	switch tokenStr {
-	case "ZNN", "znn":
-		tokenStandard = types.ZnnTokenStandard
-	case "QSR", "qsr":
-		tokenStandard = types.QsrTokenStandard
-	default:
-		// Try to parse as ZTS
-		ts, err := types.ParseZTS(tokenStr)
-		if err != nil {
-			return fmt.Errorf("invalid token standard (use ZNN/QSR or zts1...): %w", err)
-		}
-		tokenStandard = ts
+	// Validate the amount, payload and token before touching the wallet
+	if raw && format.IsRelativeAmount(req.Amount) {
+		return errs.Errorf(errs.UserInput, "%s cannot be combined with --raw", req.Amount)
 	}
-
-	// Load wallet to get address
-	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
-	if err != nil {
+	if _, err := transaction.EncodeData(memo, dataHex, dataBase64); err != nil {
+		return err
+	}
+	if _, err := service.ParseToken(req.Token); err != nil {
 		return err
 	}
 
-	address, err := wallet.GetAddress(keypair)
+	// Load wallet
+	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
 	if err != nil {
 		return err
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmd.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
 	defer func() { _ = rpcClient.Close() }()
 
-	// Get account info to check balance and get token decimals
-	accountInfo, err := rpcClient.LedgerApi.GetAccountInfoByAddress(types.ParseAddressPanic(address))
+	svc, err := service.New(cmd.Context(), rpcClient.RpcClient, cfg, keypair)
 	if err != nil {
-		return fmt.Errorf("failed to get account info: %w", err)
-	}
-
-	// Find the token balance and decimals
-	var decimals int
-	var balance *big.Int
-	found := false
-	for tokenStd, balanceInfo := range accountInfo.BalanceInfoMap {
-		if tokenStd == tokenStandard {
-			decimals = int(balanceInfo.TokenInfo.Decimals)
-			balance = balanceInfo.Balance
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		return fmt.Errorf("you have no balance for token %s", tokenStandard)
+		return err
 	}
 
-	// Parse amount with token decimals
-	amount, err := format.ParseAmount(amountStr, decimals)
+	prepared, err := svc.PrepareSend(req)
 	if err != nil {
-		return fmt.Errorf("invalid amount: %w", err)
+		return err
 	}
+	data := prepared.Template.Data
 
-	// Check if balance is sufficient
-	if balance.Cmp(amount) < 0 {
-		return fmt.Errorf("insufficient balance. You have %s but need %s",
-			format.Amount(balance, decimals),
-			format.Amount(amount, decimals))
-	}
+	// Show the plasma requirement, which grows with the payload size
+	if len(data) > 0 {
+		required, err := svc.RequiredPlasma(prepared)
+		if err != nil {
+			return err
+		}
 
-	// Create send template
-	template := &nom.AccountBlock{
-		Version:         1,
-		ChainIdentifier: 1,
-		BlockType:       nom.BlockTypeUserSend,
-		ToAddress:       toAddress,
-		Amount:          amount,
-		TokenStandard:   tokenStandard,
-		Data:            nil,
+		fmt.Printf("Data: %d bytes\n", len(data))
+		fmt.Printf("Memo: %s\n", format.Memo(data))
+		if required.RequiredDifficulty == 0 {
+			fmt.Printf("Plasma: %d required, %d available\n", required.BasePlasma, required.AvailablePlasma)
+		} else {
+			fmt.Printf("Plasma: %d required, %d available (%s)\n", required.BasePlasma, required.AvailablePlasma,
+				format.Yellow(fmt.Sprintf("PoW with difficulty %d will be generated", required.RequiredDifficulty)))
+		}
 	}
 
 	// Send transaction
+	fmt.Printf("Amount: %s\n", prepared.Describe())
 	fmt.Println("Sending transaction...")
-	err = transaction.BuildAndSend(rpcClient.RpcClient, types.ParseAddressPanic(address), template, keypair)
-	if err != nil {
+	if _, err := svc.Submit(prepared); err != nil {
 		return fmt.Errorf("failed to send transaction: %w", err)
 	}
 
 	// Display success
-	fmt.Printf("Successfully sent %s %s to %s\n",
-		format.Amount(amount, decimals),
-		format.FormatToken(amount, decimals, tokenStandard.String()),
-		format.Cyan(toAddress.String()))
+	template := prepared.Template
+	fmt.Printf("Successfully sent %s (%s) to %s\n",
+		format.FormatToken(template.Amount, prepared.Decimals, prepared.Symbol),
+		template.TokenStandard.String(),
+		format.Cyan(template.ToAddress.String()))
 
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
 	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/rewards"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
 	"github.com/spf13/cobra"
@@ -52,7 +53,7 @@ func runCollect(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -61,9 +62,9 @@ func runCollect(cmdCobra *cobra.Command, args []string) error {
 	parsedAddress := types.ParseAddressPanic(address)
 
 	// Get uncollected rewards
-	rewardInfo, err := rpcClient.SentinelApi.GetUncollectedReward(parsedAddress)
+	rewardInfo, err := rewards.Sentinel.Uncollected(rpcClient.RpcClient, parsedAddress)
 	if err != nil {
-		return fmt.Errorf("failed to get uncollected rewards: %w", err)
+		return err
 	}
 
 	// Check if there are rewards to collect
@@ -88,10 +89,10 @@ func runCollect(cmdCobra *cobra.Command, args []string) error {
 	fmt.Println()
 
 	// Create collect template
-	template := rpcClient.SentinelApi.CollectReward()
+	template := rewards.Sentinel.CollectTemplate(rpcClient.RpcClient)
 
 	// Send transaction
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to collect rewards: %w", err)
 	}
@@ -0,0 +1,126 @@
+package sentinel
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// depositQsrCmd deposits QSR for sentinel registration
+var depositQsrCmd = &cobra.Command{
+	Use:   "depositQsr [amount]",
+	Short: "Deposit QSR for sentinel registration",
+	Long: `Deposit QSR into the sentinel contract.
+
+Sentinel registration locks 50,000 QSR that must first be deposited into
+the sentinel contract. Without an amount, the remaining shortfall to
+50,000 QSR is deposited.
+
+The amount accepts the same expressions as send, such as "max" or "50%"
+of the QSR balance. Use --raw for base units.
+
+Examples:
+  znn-cli sentinel depositQsr
+  znn-cli sentinel depositQsr 25000
+
+Requires --keyStore flag to specify which wallet to use.`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: runDepositQsr,
+}
+
+func init() {
+	depositQsrCmd.Flags().Bool("raw", false, "amount is in base units")
+	depositQsrCmd.Flags().Duration("wait", 2*time.Minute, "how long to wait for the deposit to be credited")
+	SentinelCmd.AddCommand(depositQsrCmd)
+}
+
+func runDepositQsr(cmdCobra *cobra.Command, args []string) error {
+	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
+	if err != nil {
+		return err
+	}
+	raw, _ := cmdCobra.Flags().GetBool("raw")
+	wait, _ := cmdCobra.Flags().GetDuration("wait")
+
+	// Load wallet
+	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
+	if err != nil {
+		return err
+	}
+
+	address, err := wallet.GetAddress(keypair)
+	if err != nil {
+		return err
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	parsedAddress := types.ParseAddressPanic(address)
+
+	deposited, shortfall, err := qsrShortfall(rpcClient, parsedAddress)
+	if err != nil {
+		return err
+	}
+
+	accountInfo, err := rpcClient.LedgerApi.GetAccountInfoByAddress(parsedAddress)
+	if err != nil {
+		return fmt.Errorf("failed to get account info: %w", err)
+	}
+	qsrBalance := big.NewInt(0)
+	if balanceInfo, found := accountInfo.BalanceInfoMap[types.QsrTokenStandard]; found && balanceInfo.Balance != nil {
+		qsrBalance = balanceInfo.Balance
+	}
+
+	fmt.Printf("Registration requires: %s %s\n", format.Amount(big.NewInt(SentinelRegisterQsrAmount), 8), format.Blue("QSR"))
+	fmt.Printf("Deposited: %s %s\n", format.Amount(deposited, 8), format.Blue("QSR"))
+
+	amount := shortfall
+	if len(args) == 1 {
+		amount, err = format.ResolveAmount(args[0], 8, qsrBalance, raw)
+		if err != nil {
+			return err
+		}
+		if format.IsRelativeAmount(args[0]) {
+			fmt.Println(format.AmountExpression(args[0], amount, 8, "QSR"))
+		}
+	} else if shortfall.Sign() == 0 {
+		fmt.Println("Enough QSR is already deposited to register a sentinel")
+		return nil
+	}
+
+	if amount.Sign() <= 0 {
+		return errs.New(errs.UserInput, "amount must be greater than 0")
+	}
+	if amount.Cmp(qsrBalance) > 0 {
+		return errs.Errorf(errs.InsufficientFunds, "insufficient QSR balance. You have %s but need %s",
+			format.Amount(qsrBalance, 8), format.Amount(amount, 8))
+	}
+
+	fmt.Printf("Depositing %s %s\n", format.Amount(amount, 8), format.Blue("QSR"))
+
+	if err := depositQsr(cmdCobra.Context(), rpcClient, parsedAddress, amount, keypair, wait); err != nil {
+		return err
+	}
+
+	fmt.Println("Done")
+	remaining := new(big.Int).Sub(shortfall, amount)
+	if remaining.Sign() > 0 {
+		fmt.Printf("Deposit %s more %s to register a sentinel\n", format.Amount(remaining, 8), format.Blue("QSR"))
+	} else {
+		fmt.Printf("Use %s to register your sentinel\n", format.Green("sentinel register"))
+	}
+
+	return nil
+}
@@ -1,8 +1,20 @@
 package sentinel
 
 import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
 	"github.com/0x3639/znn_cli_go/pkg/config"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
 )
 
 // getConfigAndFlags extracts configuration and flags from the command
@@ -24,3 +36,53 @@ func getConfigAndFlags(cmd *cobra.Command) (*config.Config, string, string, int,
 
 	return cfg, keystoreName, passphrase, index, nil
 }
+
+// qsrShortfall returns the QSR already deposited by the address and the
+// amount that still has to be deposited to register a sentinel
+func qsrShortfall(c *client.Client, address types.Address) (*big.Int, *big.Int, error) {
+	deposited, err := c.SentinelDepositedQsr(address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get deposited QSR: %w", err)
+	}
+
+	shortfall := new(big.Int).Sub(big.NewInt(SentinelRegisterQsrAmount), deposited)
+	if shortfall.Sign() < 0 {
+		shortfall.SetInt64(0)
+	}
+	return deposited, shortfall, nil
+}
+
+// depositQsr publishes a QSR deposit and waits until the sentinel contract has credited it
+func depositQsr(ctx context.Context, c *client.Client, address types.Address, amount *big.Int, keypair *wallet.KeyPair, timeout time.Duration) error {
+	before, err := c.SentinelDepositedQsr(address)
+	if err != nil {
+		return fmt.Errorf("failed to get deposited QSR: %w", err)
+	}
+
+	template := c.SentinelApi.DepositQsr(amount)
+	if err := transaction.BuildAndSend(ctx, c.RpcClient, address, template, keypair); err != nil {
+		return fmt.Errorf("failed to deposit QSR: %w", err)
+	}
+
+	target := new(big.Int).Add(before, amount)
+	err = transaction.WaitUntil(ctx, timeout, transaction.DefaultWaitInterval, func() (bool, error) {
+		deposited, err := c.SentinelDepositedQsr(address)
+		if err != nil {
+			return false, fmt.Errorf("failed to get deposited QSR: %w", err)
+		}
+		return deposited.Cmp(target) >= 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("deposit was published but not yet credited: %w", err)
+	}
+	return nil
+}
+
+// revocationWindow describes when a sentinel can be revoked. Sentinels can
+// only be revoked during a short window that opens periodically.
+func revocationWindow(sentinel *embedded.SentinelInfo) string {
+	if sentinel.CanBeRevoked {
+		return fmt.Sprintf("%s, closes in %s", format.Green("open"), format.Duration(sentinel.RevokeCooldown))
+	}
+	return fmt.Sprintf("opens in %s", format.Duration(sentinel.RevokeCooldown))
+}
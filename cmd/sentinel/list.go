@@ -2,32 +2,41 @@ package sentinel
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
-	"github.com/0x3639/znn_cli_go/pkg/config"
 	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
 	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
 )
 
 // listCmd lists all sentinels
 var listCmd = &cobra.Command{
 	Use:   "list [pageIndex pageSize]",
 	Short: "List all sentinels",
-	Long: `List all registered sentinels in the network.
+	Long: `List all active sentinels in the network.
 
 Shows:
   - Sentinel owner address
-  - Registration timestamp
-  - Status (active/revoked)
+  - Registration time
+  - Revocation window (sentinels can only be revoked while it is open)
+
+With --keyStore, the sentinel owned by the wallet address and its
+deposited QSR are shown first.
 
 Optional pagination parameters:
   pageIndex - Page number (default: 0)
-  pageSize  - Items per page (default: 25)`,
+  pageSize  - Items per page (default: 25)
+
+Use --all to page through every active sentinel.`,
 	Args: cobra.RangeArgs(0, 2),
 	RunE: runList,
 }
 
 func init() {
+	listCmd.Flags().Bool("all", false, "list every active sentinel")
 	SentinelCmd.AddCommand(listCmd)
 }
 
@@ -43,48 +52,116 @@ func runList(cmdCobra *cobra.Command, args []string) error {
 		// Ignore error - default value used on parse failure
 		_, _ = fmt.Sscanf(args[1], "%d", &pageSize)
 	}
+	all, _ := cmdCobra.Flags().GetBool("all")
 
-	// Get URL from flags or config
-	url, _ := cmdCobra.Flags().GetString("url")
-	configFile, _ := cmdCobra.Flags().GetString("config")
-
-	cfg, err := config.Load(configFile)
+	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
 	if err != nil {
-		cfg = config.DefaultConfig()
-	}
-	if url != "" {
-		cfg.Node.URL = url
+		return err
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
 	defer func() { _ = rpcClient.Close() }()
 
+	// Show the wallet's own sentinel first
+	if keystoreName != "" {
+		_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
+		if err != nil {
+			return err
+		}
+		address, err := wallet.GetAddress(keypair)
+		if err != nil {
+			return err
+		}
+		if err := printOwnSentinel(rpcClient, types.ParseAddressPanic(address)); err != nil {
+			return err
+		}
+	}
+
 	// Get sentinel list
-	sentinelList, err := rpcClient.SentinelApi.GetAllActive(pageIndex, pageSize)
-	if err != nil {
-		return fmt.Errorf("failed to get sentinel list: %w", err)
+	var sentinels []*embedded.SentinelInfo
+	var count int
+	if all {
+		if sentinels, err = getAllSentinels(rpcClient); err != nil {
+			return err
+		}
+		pageIndex = 0
+		count = len(sentinels)
+	} else {
+		sentinelList, err := rpcClient.SentinelApi.GetAllActive(pageIndex, pageSize)
+		if err != nil {
+			return fmt.Errorf("failed to get sentinel list: %w", err)
+		}
+		sentinels = sentinelList.List
+		count = sentinelList.Count
 	}
 
 	// Display results
-	if sentinelList.Count == 0 {
+	if count == 0 {
 		fmt.Println("No sentinels found")
 		return nil
 	}
 
-	fmt.Printf("Total active sentinels: %d\n", sentinelList.Count)
+	fmt.Printf("Total active sentinels: %d\n", count)
 	fmt.Println()
 
-	for idx, sentinel := range sentinelList.List {
+	for idx, sentinel := range sentinels {
 		rank := int(pageIndex)*int(pageSize) + idx + 1
 
 		fmt.Printf("%d. Sentinel %s\n", rank, format.Green(sentinel.Owner.String()))
-		fmt.Printf("   Registered at momentum: %d\n", sentinel.RegistrationTimestamp)
+		printSentinelDetails(sentinel)
 		fmt.Println()
 	}
 
 	return nil
 }
+
+// printOwnSentinel prints the sentinel owned by address and its deposited QSR
+func printOwnSentinel(rpcClient *client.Client, address types.Address) error {
+	deposited, err := rpcClient.SentinelDepositedQsr(address)
+	if err != nil {
+		return fmt.Errorf("failed to get deposited QSR: %w", err)
+	}
+
+	sentinel, err := rpcClient.SentinelApi.GetByOwner(address)
+	if err != nil {
+		return fmt.Errorf("failed to get sentinel: %w", err)
+	}
+
+	fmt.Printf("Your sentinel (%s):\n", address.String())
+	if sentinel == nil || !sentinel.Active {
+		fmt.Println("   No active sentinel")
+	} else {
+		printSentinelDetails(sentinel)
+	}
+	fmt.Printf("   Deposited QSR: %s %s\n", format.Amount(deposited, 8), format.Blue("QSR"))
+	fmt.Println()
+	return nil
+}
+
+// printSentinelDetails prints the registration time and revocation window of a sentinel
+func printSentinelDetails(sentinel *embedded.SentinelInfo) {
+	registered := time.Unix(sentinel.RegistrationTimestamp, 0)
+	fmt.Printf("   Registered: %s\n", registered.Format("2006-01-02 15:04:05"))
+	fmt.Printf("   Revocation window: %s\n", revocationWindow(sentinel))
+}
+
+// getAllSentinels fetches every page of the active sentinel list
+func getAllSentinels(rpcClient *client.Client) ([]*embedded.SentinelInfo, error) {
+	const pageSize = 100
+
+	var sentinels []*embedded.SentinelInfo
+	for pageIndex := uint32(0); ; pageIndex++ {
+		list, err := rpcClient.SentinelApi.GetAllActive(pageIndex, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sentinel list: %w", err)
+		}
+		sentinels = append(sentinels, list.List...)
+		if len(list.List) < pageSize || len(sentinels) >= list.Count {
+			return sentinels, nil
+		}
+	}
+}
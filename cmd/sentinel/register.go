@@ -3,8 +3,10 @@ package sentinel
 import (
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
@@ -30,6 +32,10 @@ Requirements:
   - 5,000 ZNN
   - 50,000 QSR
 
+The QSR is deposited into the sentinel contract first. Any QSR already
+deposited with 'sentinel depositQsr' is counted, and only the shortfall is
+deposited before the registration is sent.
+
 Sentinels help secure the network by monitoring and validating consensus.
 
 Requires --keyStore flag to specify which wallet to use.`,
@@ -37,6 +43,7 @@ Requires --keyStore flag to specify which wallet to use.`,
 }
 
 func init() {
+	registerCmd.Flags().Duration("wait", 2*time.Minute, "how long to wait for the QSR deposit to be credited")
 	SentinelCmd.AddCommand(registerCmd)
 }
 
@@ -45,6 +52,7 @@ func runRegister(cmdCobra *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	wait, _ := cmdCobra.Flags().GetDuration("wait")
 
 	// Load wallet
 	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
@@ -58,7 +66,7 @@ func runRegister(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -66,6 +74,12 @@ func runRegister(cmdCobra *cobra.Command, args []string) error {
 
 	parsedAddress := types.ParseAddressPanic(address)
 
+	// Check for an existing sentinel
+	existing, err := rpcClient.SentinelApi.GetByOwner(parsedAddress)
+	if err == nil && existing != nil && existing.Active {
+		return errs.Errorf(errs.UserInput, "address %s already owns an active sentinel", address)
+	}
+
 	// Get account info to check balances
 	accountInfo, err := rpcClient.LedgerApi.GetAccountInfoByAddress(parsedAddress)
 	if err != nil {
@@ -80,22 +94,28 @@ func runRegister(cmdCobra *cobra.Command, args []string) error {
 		if found {
 			currentBalance = znnBalance.Balance
 		}
-		return fmt.Errorf("insufficient ZNN balance. You have %s but need %s",
+		return errs.Errorf(errs.InsufficientFunds, "insufficient ZNN balance. You have %s but need %s",
 			format.Amount(currentBalance, 8),
 			format.Amount(requiredZnn, 8))
 	}
 
-	// Check QSR balance
+	// Check QSR deposit and balance
 	requiredQsr := big.NewInt(SentinelRegisterQsrAmount)
-	qsrBalance, found := accountInfo.BalanceInfoMap[types.QsrTokenStandard]
-	if !found || qsrBalance.Balance.Cmp(requiredQsr) < 0 {
-		currentBalance := big.NewInt(0)
-		if found {
-			currentBalance = qsrBalance.Balance
+	deposited, shortfall, err := qsrShortfall(rpcClient, parsedAddress)
+	if err != nil {
+		return err
+	}
+	if shortfall.Sign() > 0 {
+		qsrBalance := big.NewInt(0)
+		if balanceInfo, found := accountInfo.BalanceInfoMap[types.QsrTokenStandard]; found && balanceInfo.Balance != nil {
+			qsrBalance = balanceInfo.Balance
+		}
+		if qsrBalance.Cmp(shortfall) < 0 {
+			return errs.Errorf(errs.InsufficientFunds, "insufficient QSR balance. You have %s but need %s more to deposit (deposited %s)",
+				format.Amount(qsrBalance, 8),
+				format.Amount(shortfall, 8),
+				format.Amount(deposited, 8))
 		}
-		return fmt.Errorf("insufficient QSR balance. You have %s but need %s",
-			format.Amount(currentBalance, 8),
-			format.Amount(requiredQsr, 8))
 	}
 
 	// Display registration info
@@ -106,11 +126,21 @@ func runRegister(cmdCobra *cobra.Command, args []string) error {
 		format.Amount(requiredQsr, 8), format.Blue("QSR"))
 	fmt.Println()
 
+	// Deposit the QSR shortfall first
+	if shortfall.Sign() > 0 {
+		fmt.Printf("Depositing %s %s (already deposited: %s)\n",
+			format.Amount(shortfall, 8), format.Blue("QSR"), format.Amount(deposited, 8))
+		if err := depositQsr(cmdCobra.Context(), rpcClient, parsedAddress, shortfall, keypair, wait); err != nil {
+			return fmt.Errorf("%w; run 'sentinel register' again once it is credited", err)
+		}
+		fmt.Println("Deposit credited")
+	}
+
 	// Create sentinel registration template
 	template := rpcClient.SentinelApi.Register()
 
 	// Send transaction
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to register sentinel: %w", err)
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
@@ -48,7 +49,7 @@ func runRevoke(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -59,7 +60,7 @@ func runRevoke(cmdCobra *cobra.Command, args []string) error {
 	// Verify sentinel exists for this address
 	_, err = rpcClient.SentinelApi.GetByOwner(parsedAddress)
 	if err != nil {
-		return fmt.Errorf("no sentinel found for address %s", address)
+		return errs.Errorf(errs.UserInput, "no sentinel found for address %s", address)
 	}
 
 	// Display revoke info
@@ -71,7 +72,7 @@ func runRevoke(cmdCobra *cobra.Command, args []string) error {
 	template := rpcClient.SentinelApi.Revoke()
 
 	// Send transaction
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to revoke sentinel: %w", err)
 	}
@@ -21,6 +21,7 @@ Requirements to register a sentinel:
 
 Available subcommands:
   list        - List all sentinels
+  depositQsr  - Deposit QSR for registration
   register    - Register a new sentinel
   revoke      - Revoke your sentinel
   collect     - Collect sentinel rewards
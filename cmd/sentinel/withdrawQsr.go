@@ -50,7 +50,7 @@ func runWithdrawQsr(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -59,7 +59,7 @@ func runWithdrawQsr(cmdCobra *cobra.Command, args []string) error {
 	parsedAddress := types.ParseAddressPanic(address)
 
 	// Get deposit info
-	depositInfo, err := rpcClient.SentinelApi.GetDepositedQsr(parsedAddress)
+	depositInfo, err := rpcClient.SentinelDepositedQsr(parsedAddress)
 	if err != nil {
 		return fmt.Errorf("failed to get deposited QSR: %w", err)
 	}
@@ -80,7 +80,7 @@ func runWithdrawQsr(cmdCobra *cobra.Command, args []string) error {
 	template := rpcClient.SentinelApi.WithdrawQsr()
 
 	// Send transaction
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to withdraw QSR: %w", err)
 	}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/config"
+	"github.com/0x3639/znn_cli_go/pkg/confirm"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/server"
+	"github.com/0x3639/znn_cli_go/pkg/service"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/spf13/cobra"
+)
+
+// serveCmd runs the local HTTP/JSON API server
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a local HTTP/JSON API for the wallet",
+	// Runs unattended: blocks are not confirmed one by one
+	Annotations: map[string]string{confirm.Annotation: confirm.Unattended},
+	Long: `Expose wallet and chain operations as a local HTTP/JSON API.
+
+The server signs with the address selected by --keyStore and --index.
+It listens on 127.0.0.1:35990 by default; use --listen unix:/path/to.sock
+for a Unix socket. Non-loopback addresses require --allow-remote.
+
+Every request needs an "Authorization: Bearer <token>" header. Tokens are
+configured under server.tokens in the config file, each with an allow-list
+of endpoint names, "read" (all read-only endpoints) or "*" (everything).
+Use 'serve token' to create one.
+
+Endpoints:
+  GET  /v1/balance                 balance
+  GET  /v1/history?page=&size=     history
+  GET  /v1/unreceived              unreceived
+  GET  /v1/momentum                momentum
+  GET  /v1/plasma                  plasma
+  GET  /v1/plasma/fusions          fusions
+  GET  /v1/stakes                  stakes
+  POST /v1/send                    send          {to, amount, token, raw, memo, dataHex, dataBase64}
+  POST /v1/receive                 receive       {hash}
+  POST /v1/receive-all             receiveAll
+  POST /v1/plasma/fuse             fuse          {beneficiary, amount, raw}
+  POST /v1/plasma/cancel           cancelFusion  {id}
+  POST /v1/stake                   stake         {amount, months, raw}
+  POST /v1/stake/revoke            revokeStake   {id}
+  POST /v1/stake/collect           collectStakeRewards
+
+Examples:
+  znn-cli serve token monitor --allow read
+  znn-cli serve --keyStore my-wallet
+  znn-cli serve --listen unix:/run/znn-cli.sock
+
+Requires --keyStore flag to specify which wallet to use.`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+// serveTokenCmd creates an API token
+var serveTokenCmd = &cobra.Command{
+	Use:   "token <name>",
+	Short: "Create an API token for the serve command",
+	Long: `Generate a random API token, save it in the config file and print it.
+An existing token with the same name is replaced.
+
+Examples:
+  znn-cli serve token monitor --allow read
+  znn-cli serve token payouts --allow balance,send,receiveAll`,
+	Args: cobra.ExactArgs(1),
+	RunE: runServeToken,
+}
+
+func init() {
+	serveCmd.Flags().String("listen", "", "listen address, host:port or unix:/path (default from config)")
+	serveCmd.Flags().Bool("allow-remote", false, "allow listening on non-loopback addresses")
+	serveTokenCmd.Flags().StringSlice("allow", []string{server.AllowRead}, "allowed endpoints, \"read\" or \"*\"")
+	serveCmd.AddCommand(serveTokenCmd)
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cfg := GetConfig()
+	keystoreName := GetKeyStore()
+	passphrase := GetPassphrase()
+	index := GetIndex()
+
+	listen, _ := cmd.Flags().GetString("listen")
+	allowRemote, _ := cmd.Flags().GetBool("allow-remote")
+	if listen == "" {
+		listen = cfg.Server.Listen
+	}
+
+	// Load wallet
+	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
+	if err != nil {
+		return err
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmd.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	svc, err := service.New(cmd.Context(), rpcClient.RpcClient, cfg, keypair)
+	if err != nil {
+		return err
+	}
+
+	srv, err := server.New(svc, cfg.Server.Tokens)
+	if err != nil {
+		return fmt.Errorf("%w (use 'serve token <name>' to create one)", err)
+	}
+
+	listener, err := server.Listen(listen, allowRemote)
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx := cmd.Context()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("Serving %s on %s\n", format.Cyan(svc.Address.String()), format.Green(listen))
+	fmt.Printf("%d token(s) configured\n", len(cfg.Server.Tokens))
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server failed: %w", err)
+	}
+
+	fmt.Println("Server stopped")
+	return nil
+}
+
+func runServeToken(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	allow, _ := cmd.Flags().GetStringSlice("allow")
+	for i := range allow {
+		allow[i] = strings.TrimSpace(allow[i])
+	}
+
+	if err := server.ValidateAllow(allow); err != nil {
+		return err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := hex.EncodeToString(secret)
+
+	path := GetConfigFile()
+	if path == "" {
+		defaultPath, err := config.DefaultConfigPath()
+		if err != nil {
+			return err
+		}
+		path = defaultPath
+	}
+
+	// Reload the file so flag overrides are not persisted
+	fileCfg, err := config.Load(GetConfigFile())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	fileCfg.AddAPIToken(config.APITokenConfig{
+		Name:  name,
+		Token: token,
+		Allow: allow,
+	})
+	if err := config.SaveKey(path, "server.tokens", fileCfg.Server.Tokens); err != nil {
+		return err
+	}
+
+	format.Success(fmt.Sprintf("Saved token %s to %s", name, path))
+	fmt.Printf("Allowed: %s\n", strings.Join(allow, ", "))
+	fmt.Printf("Token: %s\n", token)
+	fmt.Println("Store this token securely; it grants access to the listed endpoints")
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/internal/prompt"
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/confirm"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/journal"
+	"github.com/0x3639/znn_cli_go/pkg/policy"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// startSignGuard checks every send block against the spending policy of
+// the keyStore that signs it and asks the user to confirm it, showing a
+// summary, before the block is signed
+func startSignGuard(cmd *cobra.Command) {
+	node := &nodeInfo{ctx: cmd.Context(), tokens: map[types.ZenonTokenStandard]tokenInfo{
+		types.ZnnTokenStandard: {symbol: "ZNN", decimals: 8},
+		types.QsrTokenStandard: {symbol: "QSR", decimals: 8},
+	}}
+	mode := confirm.Mode{
+		Yes:          yes,
+		MainnetGuard: cfg.Confirm.MainnetGuard,
+		Unattended:   isUnattended(cmd),
+	}
+
+	transaction.SetSignGuard(func(block *nom.AccountBlock) error {
+		if !nom.IsSendBlock(block.BlockType) {
+			return nil
+		}
+
+		checker, err := policyChecker(block.Address, node)
+		if err != nil {
+			return err
+		}
+		var reasons []string
+		if checker != nil {
+			if reasons, err = checker.Check(block); err != nil {
+				return err
+			}
+		}
+
+		network, err := node.network()
+		if err != nil {
+			return err
+		}
+		required, err := mode.Required(network, reasons)
+		if err != nil {
+			if len(reasons) > 0 {
+				return checker.Declined(reasons[0] + " and an unattended command cannot confirm it")
+			}
+			return err
+		}
+		if !required {
+			return nil
+		}
+
+		summary, err := confirm.Describe(block, network, confirm.Resolver{
+			Account: wallet.Account,
+			Label:   cfg.LabelFor,
+			Token:   node.token,
+		})
+		if err != nil {
+			return err
+		}
+		summary.Write(os.Stderr)
+		for _, reason := range reasons {
+			fmt.Fprintln(os.Stderr, format.Yellow("  Policy:   "+reason))
+		}
+		if mode.Yes && len(reasons) == 0 {
+			fmt.Fprintln(os.Stderr, format.Yellow("  --yes does not skip confirmation on mainnet (confirm.mainnet_guard in the config file)"))
+		}
+
+		confirmed, err := prompt.Confirm("Sign and publish")
+		if err != nil || !confirmed {
+			if len(reasons) > 0 {
+				return checker.Declined(reasons[0] + " and was not confirmed")
+			}
+			if err != nil {
+				return errs.Errorf(errs.NotConfirmed, "transaction not confirmed: %w (use --yes to skip confirmation)", err)
+			}
+			return errs.New(errs.NotConfirmed, "transaction not confirmed")
+		}
+		return nil
+	})
+}
+
+// isUnattended reports whether a command or one of its parents is annotated
+// as unattended
+func isUnattended(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[confirm.Annotation] == confirm.Unattended {
+			return true
+		}
+	}
+	return false
+}
+
+// policyChecker returns the checker for the policy of the keyStore that owns
+// a signing address, or nil if that keyStore has no policy
+func policyChecker(address types.Address, node *nodeInfo) (*policy.Checker, error) {
+	name, _, found := wallet.Account(address)
+	if !found {
+		// Without its keyStore no policy can be applied, which is only safe
+		// when none of the loaded keyStores has one
+		for _, loaded := range wallet.LoadedKeyStores() {
+			p, err := policy.Load(policy.Path(cfg.Wallet.PolicyDir, loaded), cfg.ResolveAddress)
+			if err != nil {
+				return nil, err
+			}
+			if p != nil {
+				return nil, errs.Errorf(errs.Policy, "the keyStore of %s is unknown, so its spending policy cannot be checked", address)
+			}
+		}
+		return nil, nil
+	}
+
+	p, err := policy.Load(policy.Path(cfg.Wallet.PolicyDir, name), cfg.ResolveAddress)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	checker := &policy.Checker{KeyStore: name, Policy: p, Decimals: node.decimals}
+	if !cfg.Journal.Disabled && cfg.Journal.Path != "" {
+		checker.Spent = func(zts types.ZenonTokenStandard, since time.Time) (*big.Int, error) {
+			return journalSpent(name, zts, since)
+		}
+	}
+	return checker, nil
+}
+
+// journalSpent returns the amount of a token every address of a keyStore
+// sent since a time, according to the transaction journal. Entries written
+// before the journal recorded keyStores are attributed by their address.
+func journalSpent(keyStore string, zts types.ZenonTokenStandard, since time.Time) (*big.Int, error) {
+	entries, err := journal.Read(cfg.Journal.Path)
+	if err != nil {
+		return nil, err
+	}
+	return journal.Spent(entries, func(entry *journal.Entry) bool {
+		if entry.KeyStore != "" {
+			return entry.KeyStore == keyStore
+		}
+		address, err := types.ParseAddress(entry.Address)
+		return err == nil && keyStoreOf(address) == keyStore
+	}, zts, since)
+}
+
+// keyStoreOf returns the loaded keyStore of an address, or an empty string
+func keyStoreOf(address types.Address) string {
+	name, _, _ := wallet.Account(address)
+	return name
+}
+
+// tokenInfo is the symbol and decimals of a token
+type tokenInfo struct {
+	symbol   string
+	decimals int
+}
+
+// nodeInfo caches what the sign guard asks the node: token details and the
+// chain the node belongs to
+type nodeInfo struct {
+	ctx     context.Context
+	mu      sync.Mutex
+	tokens  map[types.ZenonTokenStandard]tokenInfo
+	chainID *uint64
+}
+
+// withClient runs f with a client connected to the configured node
+func withClient(ctx context.Context, f func(c *client.Client) error) error {
+	rpcClient, err := client.New(ctx, cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+	return f(rpcClient)
+}
+
+func (n *nodeInfo) token(zts types.ZenonTokenStandard) (string, int, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if info, found := n.tokens[zts]; found {
+		return info.symbol, info.decimals, nil
+	}
+
+	err := withClient(n.ctx, func(c *client.Client) error {
+		token, err := c.TokenApi.GetByZts(zts)
+		if err != nil {
+			return fmt.Errorf("failed to get token info: %w", err)
+		}
+		if token == nil {
+			return fmt.Errorf("token %s not found", zts)
+		}
+		n.tokens[zts] = tokenInfo{symbol: token.TokenSymbol, decimals: int(token.Decimals)}
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return n.tokens[zts].symbol, n.tokens[zts].decimals, nil
+}
+
+func (n *nodeInfo) decimals(zts types.ZenonTokenStandard) (int, error) {
+	_, decimals, err := n.token(zts)
+	return decimals, err
+}
+
+func (n *nodeInfo) network() (confirm.Network, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.chainID == nil {
+		err := withClient(n.ctx, func(c *client.Client) error {
+			momentum, err := c.LedgerApi.GetFrontierMomentum()
+			if err != nil {
+				return fmt.Errorf("failed to get frontier momentum: %w", err)
+			}
+			n.chainID = &momentum.ChainIdentifier
+			return nil
+		})
+		if err != nil {
+			return confirm.Network{}, err
+		}
+	}
+	return confirm.Network{URL: cfg.Node.URL, ChainID: *n.chainID}, nil
+}
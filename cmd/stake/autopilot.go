@@ -0,0 +1,203 @@
+package stake
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/autopilot"
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/confirm"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// autopilotCmd collects, revokes and restakes automatically
+var autopilotCmd = &cobra.Command{
+	Use:   "autopilot",
+	Short: "Collect rewards, revoke expired stakes and restake",
+	// Runs unattended: blocks are not confirmed one by one
+	Annotations: map[string]string{confirm.Annotation: confirm.Unattended},
+	Long: `Maintain the stakes of the wallet address. Each run:
+  1. Revokes every expired stake entry
+  2. Collects stake rewards
+  3. Waits for the revoked ZNN and rewards to arrive and receives them
+  4. With --restake-expired, restakes each revoked entry for its original duration
+  5. With --threshold, stakes the ZNN balance above the threshold for --months
+
+Every action is published like any other transaction and appended to the
+audit log (~/.znn/stake-autopilot.log by default, one JSON object per line).
+Use --log to print the recorded actions.
+
+Runs once by default; use --daemon to repeat every --interval until
+interrupted.
+
+Examples:
+  znn-cli stake autopilot --threshold 100 --months 12
+  znn-cli stake autopilot --restake-expired --daemon --interval 6h
+  znn-cli stake autopilot --log 20
+
+Requires --keyStore flag to specify which wallet to use.`,
+	RunE: runAutopilot,
+}
+
+func init() {
+	autopilotCmd.Flags().String("threshold", "", "ZNN balance to keep liquid; the balance above it is staked (disabled when empty)")
+	autopilotCmd.Flags().Int64("months", 12, "duration of new stakes in months")
+	autopilotCmd.Flags().Bool("restake-expired", false, "restake revoked entries for their original duration")
+	autopilotCmd.Flags().Duration("wait", 3*time.Minute, "how long to wait for revoked ZNN and rewards to arrive")
+	autopilotCmd.Flags().Bool("daemon", false, "keep running and repeat every --interval")
+	autopilotCmd.Flags().Duration("interval", time.Hour, "time between runs in daemon mode")
+	autopilotCmd.Flags().String("audit", "", "audit log file (default ~/.znn/stake-autopilot.log)")
+	autopilotCmd.Flags().Int("log", 0, "print the last N audit log entries and exit")
+	StakeCmd.AddCommand(autopilotCmd)
+}
+
+func runAutopilot(cmdCobra *cobra.Command, args []string) error {
+	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
+	if err != nil {
+		return err
+	}
+
+	thresholdStr, _ := cmdCobra.Flags().GetString("threshold")
+	daemon, _ := cmdCobra.Flags().GetBool("daemon")
+	interval, _ := cmdCobra.Flags().GetDuration("interval")
+	auditPath, _ := cmdCobra.Flags().GetString("audit")
+	logCount, _ := cmdCobra.Flags().GetInt("log")
+
+	if auditPath == "" {
+		if auditPath, err = autopilot.AuditPath(); err != nil {
+			return err
+		}
+	}
+	if logCount > 0 {
+		return printAuditLog(auditPath, logCount)
+	}
+
+	options := autopilot.Options{}
+	options.Months, _ = cmdCobra.Flags().GetInt64("months")
+	options.RestakeExpired, _ = cmdCobra.Flags().GetBool("restake-expired")
+	options.Wait, _ = cmdCobra.Flags().GetDuration("wait")
+	if thresholdStr != "" {
+		if options.Threshold, err = format.ParseAmount(thresholdStr, 8); err != nil {
+			return errs.Errorf(errs.UserInput, "invalid threshold: %w", err)
+		}
+	}
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	if daemon && interval < time.Minute {
+		return errs.New(errs.UserInput, "interval must be at least 1m")
+	}
+
+	// Load wallet
+	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
+	if err != nil {
+		return err
+	}
+
+	address, err := wallet.GetAddress(keypair)
+	if err != nil {
+		return err
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	pilot := &autopilot.Pilot{
+		Client:  rpcClient.RpcClient,
+		Address: types.ParseAddressPanic(address),
+		KeyPair: keypair,
+		Options: options,
+		Audit:   &autopilot.AuditLog{Path: auditPath},
+	}
+
+	ctx := cmdCobra.Context()
+
+	fmt.Printf("Stake autopilot for %s (audit log: %s)\n", format.Green(address), auditPath)
+	if options.Threshold != nil {
+		fmt.Printf("Staking the balance above %s %s for %d month(s)\n",
+			format.Amount(options.Threshold, 8), format.Green("ZNN"), options.Months)
+	}
+
+	for {
+		actions, err := pilot.Run(ctx)
+		for _, action := range actions {
+			printAuditEntry(action)
+		}
+		if err != nil {
+			if !daemon {
+				return err
+			}
+			format.Warning(err.Error())
+		} else if len(actions) == 0 {
+			fmt.Println("Nothing to do")
+		}
+
+		if !daemon {
+			fmt.Println("Done")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("Autopilot stopped")
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// printAuditLog prints the last entries of an audit log
+func printAuditLog(path string, count int) error {
+	entries, err := autopilot.ReadAudit(path)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No autopilot actions recorded in %s\n", path)
+		return nil
+	}
+	if len(entries) > count {
+		entries = entries[len(entries)-count:]
+	}
+	for _, entry := range entries {
+		printAuditEntry(entry)
+	}
+	return nil
+}
+
+// printAuditEntry prints one autopilot action
+func printAuditEntry(entry *autopilot.AuditEntry) {
+	var detail string
+	switch entry.Action {
+	case autopilot.ActionRevoke:
+		detail = fmt.Sprintf("stake %s (%s ZNN)", entry.StakeID, formatBaseUnits(entry.Amount))
+	case autopilot.ActionCollect:
+		detail = fmt.Sprintf("%s ZNN, %s QSR", formatBaseUnits(entry.Amount), formatBaseUnits(entry.QsrAmount))
+	case autopilot.ActionStake:
+		detail = fmt.Sprintf("%s ZNN for %d month(s)", formatBaseUnits(entry.Amount), entry.Months)
+	}
+
+	status := format.Cyan(entry.Hash)
+	if entry.Error != "" {
+		status = format.Red("failed: " + entry.Error)
+	}
+	fmt.Printf("%s  %-8s %s %s\n", entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Action, detail, status)
+}
+
+// formatBaseUnits formats an amount in base units recorded in the audit log
+func formatBaseUnits(value string) string {
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return value
+	}
+	return format.Amount(amount, 8)
+}
@@ -1,8 +1,12 @@
 package stake
 
 import (
+	"fmt"
+
 	"github.com/0x3639/znn_cli_go/pkg/config"
+	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
 )
 
 // getConfigAndFlags extracts configuration and flags from the command
@@ -24,3 +28,23 @@ func getConfigAndFlags(cmd *cobra.Command) (*config.Config, string, string, int,
 
 	return cfg, keystoreName, passphrase, index, nil
 }
+
+// printEntryResult prints the outcome of one block of a bulk operation
+func printEntryResult(description string, hash types.Hash, err error) {
+	if err != nil {
+		fmt.Printf("%s: %s\n", description, format.Red("failed: "+err.Error()))
+		return
+	}
+	fmt.Printf("%s: %s\n", description, format.Cyan(hash.String()))
+}
+
+// summarize prints how many entries of a bulk operation succeeded and
+// returns an error if any failed
+func summarize(total, failed int) error {
+	fmt.Printf("%d of %d entries published\n", total-failed, total)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d entries failed", failed, total)
+	}
+	fmt.Println("Done")
+	return nil
+}
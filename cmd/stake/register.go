@@ -2,29 +2,14 @@ package stake
 
 import (
 	"fmt"
-	"math/big"
 	"strconv"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
-	"github.com/0x3639/znn_cli_go/pkg/transaction"
+	"github.com/0x3639/znn_cli_go/pkg/service"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
 	"github.com/spf13/cobra"
-	"github.com/zenon-network/go-zenon/common/types"
-)
-
-const (
-	// MinStakeAmount is the minimum ZNN that can be staked (1 ZNN)
-	MinStakeAmount = 1 * 1e8
-
-	// StakeTimeUnit is one month in seconds (30 days)
-	StakeTimeUnit = 30 * 24 * 60 * 60
-
-	// MinStakeMonths is the minimum staking duration
-	MinStakeMonths = 1
-
-	// MaxStakeMonths is the maximum staking duration
-	MaxStakeMonths = 12
 )
 
 // registerCmd stakes ZNN for rewards
@@ -41,8 +26,12 @@ Requirements:
   - Duration: 1-12 months
   - Sufficient ZNN balance
 
-Example:
+Amount can be a decimal (1,000.5), max, a percentage of the ZNN
+balance (50%), or an integer number of base units with --raw.
+
+Examples:
   znn-cli stake register 100 3    # Stake 100 ZNN for 3 months
+  znn-cli stake register 50% 12   # Stake half the ZNN balance for 12 months
 
 Requires --keyStore flag to specify which wallet to use.`,
 	Args: cobra.ExactArgs(2),
@@ -50,6 +39,7 @@ Requires --keyStore flag to specify which wallet to use.`,
 }
 
 func init() {
+	registerCmd.Flags().Bool("raw", false, "amount is an integer number of base units")
 	StakeCmd.AddCommand(registerCmd)
 }
 
@@ -59,83 +49,47 @@ func runRegister(cmdCobra *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Parse arguments
-	amountStr := args[0]
-	durationStr := args[1]
-
-	// Parse amount (ZNN has 8 decimals)
-	amount, err := format.ParseAmount(amountStr, 8)
-	if err != nil {
-		return fmt.Errorf("invalid amount: %w", err)
-	}
-
 	// Parse duration (in months)
-	duration, err := strconv.ParseInt(durationStr, 10, 64)
+	duration, err := strconv.ParseInt(args[1], 10, 64)
 	if err != nil {
-		return fmt.Errorf("invalid duration: must be a number between %d and %d", MinStakeMonths, MaxStakeMonths)
-	}
-
-	// Validate amount
-	if amount.Cmp(big.NewInt(MinStakeAmount)) < 0 {
-		return fmt.Errorf("invalid amount: minimum stake amount is %s ZNN",
-			format.Amount(big.NewInt(MinStakeAmount), 8))
+		return errs.Errorf(errs.UserInput, "invalid duration: must be a number between %d and %d",
+			service.MinStakeMonths, service.MaxStakeMonths)
 	}
 
-	// Validate duration
-	if duration < MinStakeMonths || duration > MaxStakeMonths {
-		return fmt.Errorf("invalid duration: must be between %d and %d months", MinStakeMonths, MaxStakeMonths)
+	raw, _ := cmdCobra.Flags().GetBool("raw")
+	req := service.StakeRequest{
+		Amount: args[0],
+		Months: duration,
+		Raw:    raw,
 	}
 
-	// Calculate duration in seconds
-	durationSeconds := duration * StakeTimeUnit
-
 	// Load wallet
 	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
 	if err != nil {
 		return err
 	}
 
-	address, err := wallet.GetAddress(keypair)
-	if err != nil {
-		return err
-	}
-
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
 	defer func() { _ = rpcClient.Close() }()
 
-	// Get account info to check ZNN balance
-	accountInfo, err := rpcClient.LedgerApi.GetAccountInfoByAddress(types.ParseAddressPanic(address))
+	svc, err := service.New(cmdCobra.Context(), rpcClient.RpcClient, cfg, keypair)
 	if err != nil {
-		return fmt.Errorf("failed to get account info: %w", err)
+		return err
 	}
 
-	// Check ZNN balance
-	znnBalance, found := accountInfo.BalanceInfoMap[types.ZnnTokenStandard]
-	if !found || znnBalance.Balance.Cmp(amount) < 0 {
-		currentBalance := big.NewInt(0)
-		if found {
-			currentBalance = znnBalance.Balance
-		}
-		return fmt.Errorf("insufficient ZNN balance. You have %s but need %s",
-			format.Amount(currentBalance, 8),
-			format.Amount(amount, 8))
+	prepared, err := svc.PrepareStake(req)
+	if err != nil {
+		return err
 	}
 
-	// Create stake template
-	template := rpcClient.StakeApi.Stake(durationSeconds, amount)
-
 	// Send transaction
-	fmt.Printf("Staking %s %s for %d month(s)\n",
-		format.Amount(amount, 8),
-		format.Green("ZNN"),
-		duration)
+	fmt.Printf("Staking %s for %d month(s)\n", prepared.Describe(), duration)
 
-	err = transaction.BuildAndSend(rpcClient.RpcClient, types.ParseAddressPanic(address), template, keypair)
-	if err != nil {
+	if _, err := svc.Submit(prepared); err != nil {
 		return fmt.Errorf("failed to stake: %w", err)
 	}
 
@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/stake"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
 	"github.com/spf13/cobra"
@@ -14,28 +16,42 @@ import (
 
 // revokeCmd cancels an expired stake entry
 var revokeCmd = &cobra.Command{
-	Use:   "revoke <id>",
+	Use:   "revoke <id> | --all-expired",
 	Short: "Cancel expired stake",
 	Long: `Cancel a stake entry by its ID.
 
-Stake entries can only be revoked after they reach their expiration time.
-The staked ZNN will be returned to your account.
+Stake entries can only be revoked after they reach their expiration time,
+measured by the timestamp of the latest momentum. The staked ZNN will be returned to your account.
+
+With --all-expired, every stake entry past its expiration time is revoked.
+The blocks are chained locally and published without waiting for each
+other, and the result of each entry is reported.
 
 Use 'stake list' to see stake entry IDs and expiration times.
 
-Example:
+Examples:
   znn-cli stake revoke abc123...
+  znn-cli stake revoke --all-expired
 
 Requires --keyStore flag to specify which wallet to use.`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.RangeArgs(0, 1),
 	RunE: runRevoke,
 }
 
 func init() {
+	revokeCmd.Flags().Bool("all-expired", false, "revoke every expired stake entry")
 	StakeCmd.AddCommand(revokeCmd)
 }
 
 func runRevoke(cmdCobra *cobra.Command, args []string) error {
+	allExpired, _ := cmdCobra.Flags().GetBool("all-expired")
+	if allExpired == (len(args) == 1) {
+		return errs.New(errs.UserInput, "specify either a stake ID or --all-expired")
+	}
+	if allExpired {
+		return runRevokeAllExpired(cmdCobra)
+	}
+
 	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
 	if err != nil {
 		return err
@@ -45,7 +61,7 @@ func runRevoke(cmdCobra *cobra.Command, args []string) error {
 	idStr := args[0]
 	var stakeId types.Hash
 	if err := stakeId.UnmarshalText([]byte(idStr)); err != nil {
-		return fmt.Errorf("invalid stake ID: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid stake ID: %w", err)
 	}
 
 	// Load wallet
@@ -60,7 +76,7 @@ func runRevoke(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -68,6 +84,13 @@ func runRevoke(cmdCobra *cobra.Command, args []string) error {
 
 	parsedAddress := types.ParseAddressPanic(address)
 
+	// Expiration is checked against the momentum time, as the contract does
+	momentum, err := rpcClient.LedgerApi.GetFrontierMomentum()
+	if err != nil {
+		return fmt.Errorf("failed to get frontier momentum: %w", err)
+	}
+	now := int64(momentum.TimestampUnix)
+
 	// Search through stake entries to find the one with matching ID
 	pageIndex := uint32(0)
 	found := false
@@ -88,7 +111,6 @@ func runRevoke(cmdCobra *cobra.Command, args []string) error {
 			if entry.Id == stakeId {
 				found = true
 				// Check if it can be revoked
-				now := time.Now().Unix()
 				if entry.ExpirationTimestamp > now {
 					expirationTime := time.Unix(entry.ExpirationTimestamp, 0)
 					fmt.Printf("%s Stake entry cannot be revoked yet\n", format.Red("Error!"))
@@ -109,11 +131,11 @@ func runRevoke(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	if !found {
-		return fmt.Errorf("no stake entry found with ID %s", stakeId.String())
+		return errs.Errorf(errs.UserInput, "no stake entry found with ID %s", stakeId.String())
 	}
 
 	if gotError {
-		return fmt.Errorf("stake entry not ready to revoke")
+		return errs.New(errs.UserInput, "stake entry not ready to revoke")
 	}
 
 	// Create revoke template
@@ -121,7 +143,7 @@ func runRevoke(cmdCobra *cobra.Command, args []string) error {
 
 	// Send transaction
 	fmt.Println("Revoking stake entry...")
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to revoke stake: %w", err)
 	}
@@ -131,3 +153,69 @@ func runRevoke(cmdCobra *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// runRevokeAllExpired revokes every expired stake entry of the wallet address
+func runRevokeAllExpired(cmdCobra *cobra.Command) error {
+	cfg, keystoreName, passphrase, index, err := getConfigAndFlags(cmdCobra)
+	if err != nil {
+		return err
+	}
+
+	// Load wallet
+	_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
+	if err != nil {
+		return err
+	}
+
+	address, err := wallet.GetAddress(keypair)
+	if err != nil {
+		return err
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	parsedAddress := types.ParseAddressPanic(address)
+
+	momentum, err := rpcClient.LedgerApi.GetFrontierMomentum()
+	if err != nil {
+		return fmt.Errorf("failed to get frontier momentum: %w", err)
+	}
+	momentumTime := time.Unix(int64(momentum.TimestampUnix), 0)
+
+	entries, err := stake.Entries(rpcClient.RpcClient, parsedAddress)
+	if err != nil {
+		return err
+	}
+
+	expired := stake.ExpiredEntries(entries, momentumTime.Unix())
+	if len(expired) == 0 {
+		fmt.Printf("No expired stake entries at momentum time %s\n", momentumTime.Format("2006-01-02 15:04:05"))
+		return nil
+	}
+
+	fmt.Printf("Revoking %d expired stake entries (momentum time %s)...\n", len(expired), momentumTime.Format("2006-01-02 15:04:05"))
+
+	chain := transaction.NewChain(rpcClient.RpcClient, parsedAddress, keypair)
+	failed := 0
+	for _, entry := range expired {
+		description := fmt.Sprintf("Revoke %s ZNN (%s)", format.Amount(entry.Amount, 8), entry.Id.String())
+		template := rpcClient.StakeApi.Cancel(entry.Id)
+		err := chain.Send(cmdCobra.Context(), template)
+		if err != nil {
+			failed++
+		}
+		printEntryResult(description, template.Hash, err)
+	}
+
+	if err := summarize(len(expired), failed); err != nil {
+		return err
+	}
+	fmt.Printf("Use %s to collect your ZNN after 2 momentums\n", format.Green("receiveAll"))
+
+	return nil
+}
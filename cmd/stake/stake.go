@@ -15,10 +15,11 @@ Minimum stake amount: 1 ZNN
 Valid durations: 1-12 months (in 30-day increments)
 
 Available subcommands:
-  list     - List stake entries
-  register - Stake ZNN for rewards
-  revoke   - Cancel expired stake
-  collect  - Collect staking rewards`,
+  list      - List stake entries
+  register  - Stake ZNN for rewards
+  revoke    - Cancel expired stake
+  collect   - Collect staking rewards
+  autopilot - Collect, revoke expired stakes and restake automatically`,
 }
 
 func init() {
@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+
+	sdkwallet "github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+)
+
+// sweepCmd moves all funds from a set of derived addresses to one destination
+var sweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "Sweep all funds from derived addresses to one destination",
+	Long: `Consolidate funds from several derived addresses into one destination.
+
+For each source address this command will:
+  1. Receive all pending (unreceived) blocks
+  2. Send every token balance in full to the destination
+
+Plasma is used when available, otherwise PoW is generated per block.
+Source addresses equal to the destination are skipped.
+
+Use --dry-run to display the plan without sending anything.
+
+Examples:
+  znn-cli sweep --from-indices 0-20 --to z1qz...
+  znn-cli sweep --from-indices 1,3,5-8 --to @cold-0 --dry-run
+
+Requires --keyStore flag to specify which wallet to use.`,
+	Args: cobra.NoArgs,
+	RunE: runSweep,
+}
+
+func init() {
+	sweepCmd.Flags().String("from-indices", "", "source address indices, e.g. 0-20 or 0,2,5-7")
+	sweepCmd.Flags().String("to", "", "destination address or @label")
+	sweepCmd.Flags().Bool("dry-run", false, "show the plan without sending")
+	_ = sweepCmd.MarkFlagRequired("from-indices")
+	_ = sweepCmd.MarkFlagRequired("to")
+	rootCmd.AddCommand(sweepCmd)
+}
+
+// sweepSource holds the state of one source address in a sweep
+type sweepSource struct {
+	index   int
+	address types.Address
+	keypair *sdkwallet.KeyPair
+	pending []*api.AccountBlock
+	plasma  uint64
+	info    *api.AccountInfo
+}
+
+// sweepResult summarizes the outcome for one source address
+type sweepResult struct {
+	received int
+	sent     []string
+	powUsed  int
+	err      error
+}
+
+func runSweep(cmd *cobra.Command, args []string) error {
+	cfg := GetConfig()
+	keystoreName := GetKeyStore()
+	passphrase := GetPassphrase()
+
+	indicesStr, _ := cmd.Flags().GetString("from-indices")
+	toStr, _ := cmd.Flags().GetString("to")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	indices, err := wallet.ParseIndexRange(indicesStr)
+	if err != nil {
+		return errs.Wrap(errs.UserInput, err)
+	}
+
+	destination, err := cfg.ResolveAddress(toStr)
+	if err != nil {
+		return errs.Errorf(errs.UserInput, "invalid destination address: %w", err)
+	}
+
+	// Load wallet
+	keyStore, _, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, 0)
+	if err != nil {
+		return err
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmd.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	// Build the plan
+	var sources []*sweepSource
+	for _, i := range indices {
+		keypair, err := wallet.DeriveKeyPair(keyStore, i)
+		if err != nil {
+			return err
+		}
+		address, err := keypair.GetAddress()
+		if err != nil {
+			return fmt.Errorf("failed to get address at index %d: %w", i, err)
+		}
+		if *address == destination {
+			continue
+		}
+
+		source, err := loadSweepSource(rpcClient, i, *address, keypair)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source)
+	}
+
+	fmt.Printf("Sweep to %s\n", format.Cyan(destination.String()))
+	fmt.Println()
+
+	empty := true
+	for _, source := range sources {
+		if source.isEmpty() {
+			continue
+		}
+		empty = false
+		printSweepPlan(source)
+	}
+
+	if empty {
+		fmt.Println("Nothing to sweep")
+		return nil
+	}
+
+	if dryRun {
+		format.Info("Dry run: no transactions were sent")
+		return nil
+	}
+
+	// Execute the plan
+	fmt.Println("Sweeping...")
+	failed := 0
+	for _, source := range sources {
+		if source.isEmpty() {
+			continue
+		}
+
+		result := executeSweep(cmd.Context(), rpcClient, source, destination)
+		printSweepResult(source, result)
+		if result.err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("sweep failed for %d address(es)", failed)
+	}
+
+	format.Success("Sweep complete")
+	return nil
+}
+
+// loadSweepSource queries balances, pending blocks and plasma for a source address
+func loadSweepSource(rpcClient *client.Client, index int, address types.Address, keypair *sdkwallet.KeyPair) (*sweepSource, error) {
+	info, err := rpcClient.LedgerApi.GetAccountInfoByAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account info for %s: %w", address, err)
+	}
+
+	var pending []*api.AccountBlock
+	for pageIndex := uint32(0); ; pageIndex++ {
+		blocks, err := rpcClient.LedgerApi.GetUnreceivedBlocksByAddress(address, pageIndex, 50)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get unreceived blocks for %s: %w", address, err)
+		}
+		pending = append(pending, blocks.List...)
+		if !blocks.More || len(blocks.List) == 0 {
+			break
+		}
+	}
+
+	plasmaInfo, err := rpcClient.PlasmaApi.Get(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plasma info for %s: %w", address, err)
+	}
+
+	return &sweepSource{
+		index:   index,
+		address: address,
+		keypair: keypair,
+		pending: pending,
+		plasma:  plasmaInfo.CurrentPlasma,
+		info:    info,
+	}, nil
+}
+
+// isEmpty reports whether the source has neither balances nor pending blocks
+func (s *sweepSource) isEmpty() bool {
+	return len(s.pending) == 0 && len(s.tokensToSend()) == 0
+}
+
+// expectedAmounts returns the balance per token after all pending blocks are received
+func (s *sweepSource) expectedAmounts() map[types.ZenonTokenStandard]*big.Int {
+	amounts := make(map[types.ZenonTokenStandard]*big.Int)
+	for zts, balanceInfo := range s.info.BalanceInfoMap {
+		if balanceInfo.Balance != nil {
+			amounts[zts] = new(big.Int).Set(balanceInfo.Balance)
+		}
+	}
+	for _, block := range s.pending {
+		if block.Amount == nil {
+			continue
+		}
+		if _, ok := amounts[block.TokenStandard]; !ok {
+			amounts[block.TokenStandard] = big.NewInt(0)
+		}
+		amounts[block.TokenStandard].Add(amounts[block.TokenStandard], block.Amount)
+	}
+	return amounts
+}
+
+// tokensToSend returns the tokens with a non-zero expected balance
+func (s *sweepSource) tokensToSend() []types.ZenonTokenStandard {
+	var tokens []types.ZenonTokenStandard
+	for zts, amount := range s.expectedAmounts() {
+		if amount.Sign() > 0 {
+			tokens = append(tokens, zts)
+		}
+	}
+	return tokens
+}
+
+// tokenInfo returns the decimals and symbol for a token held or pending at the source
+func (s *sweepSource) tokenInfo(zts types.ZenonTokenStandard) (int, string) {
+	if balanceInfo, ok := s.info.BalanceInfoMap[zts]; ok && balanceInfo.TokenInfo != nil {
+		return int(balanceInfo.TokenInfo.Decimals), balanceInfo.TokenInfo.TokenSymbol
+	}
+	for _, block := range s.pending {
+		if block.TokenStandard == zts && block.TokenInfo != nil {
+			return int(block.TokenInfo.Decimals), block.TokenInfo.TokenSymbol
+		}
+	}
+	return format.CoinDecimals, zts.String()
+}
+
+// printSweepPlan displays what will be received and sent for a source address
+func printSweepPlan(source *sweepSource) {
+	fmt.Printf("  %d\t%s\n", source.index, format.Cyan(source.address.String()))
+	if len(source.pending) > 0 {
+		fmt.Printf("  \tReceive %d pending block(s)\n", len(source.pending))
+	}
+
+	amounts := source.expectedAmounts()
+	for _, zts := range source.tokensToSend() {
+		decimals, symbol := source.tokenInfo(zts)
+		fmt.Printf("  \tSend %s\n", format.FormatToken(amounts[zts], decimals, symbol))
+	}
+
+	transactions := uint64(len(source.pending) + len(source.tokensToSend()))
+	if source.plasma >= transactions*transaction.MinPlasmaAmount {
+		fmt.Printf("  \tPlasma: %d (sufficient)\n", source.plasma)
+	} else {
+		fmt.Printf("  \tPlasma: %d (%s)\n", source.plasma, format.Yellow("PoW will be required"))
+	}
+	fmt.Println()
+}
+
+// executeSweep receives pending blocks and sends every balance to the destination
+func executeSweep(ctx context.Context, rpcClient *client.Client, source *sweepSource, destination types.Address) sweepResult {
+	var result sweepResult
+
+	received, err := transaction.ReceiveAll(ctx, rpcClient.RpcClient, source.address, source.keypair, func(block *nom.AccountBlock) {
+		slog.Info("received block", "hash", block.Hash, "from", block.FromBlockHash)
+	})
+	result.received = received
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	// Refresh balances after receiving
+	info, err := rpcClient.LedgerApi.GetAccountInfoByAddress(source.address)
+	if err != nil {
+		result.err = fmt.Errorf("failed to get account info: %w", err)
+		return result
+	}
+	source.info = info
+
+	for zts, balanceInfo := range info.BalanceInfoMap {
+		if balanceInfo.Balance == nil || balanceInfo.Balance.Sign() == 0 {
+			continue
+		}
+
+		template := &nom.AccountBlock{
+			Version:         1,
+			ChainIdentifier: 1,
+			BlockType:       nom.BlockTypeUserSend,
+			ToAddress:       destination,
+			Amount:          new(big.Int).Set(balanceInfo.Balance),
+			TokenStandard:   zts,
+			Data:            nil,
+		}
+
+		decimals, symbol := source.tokenInfo(zts)
+		err = transaction.BuildAndSend(ctx, rpcClient.RpcClient, source.address, template, source.keypair)
+		if err != nil {
+			result.err = fmt.Errorf("failed to send %s: %w", symbol, err)
+			return result
+		}
+
+		if template.Difficulty > 0 {
+			result.powUsed++
+		}
+		result.sent = append(result.sent, format.FormatToken(template.Amount, decimals, symbol))
+	}
+
+	return result
+}
+
+// printSweepResult displays the outcome for one source address
+func printSweepResult(source *sweepSource, result sweepResult) {
+	fmt.Printf("  %d\t%s\n", source.index, format.Cyan(source.address.String()))
+	if result.received > 0 {
+		fmt.Printf("  \tReceived %d block(s)\n", result.received)
+	}
+	for _, sent := range result.sent {
+		fmt.Printf("  \tSent %s\n", sent)
+	}
+	if result.powUsed > 0 {
+		fmt.Printf("  \tPoW generated for %d block(s)\n", result.powUsed)
+	}
+	if result.err != nil {
+		fmt.Printf("  \t%s %v\n", format.Red("Error!"), result.err)
+	}
+}
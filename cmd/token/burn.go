@@ -2,8 +2,10 @@ package token
 
 import (
 	"fmt"
+	"math/big"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
@@ -23,8 +25,12 @@ Requirements:
 
 The burned tokens are permanently destroyed and cannot be recovered.
 
-Example:
+Amount can be a decimal (1,000.5), max, a percentage of the balance
+(50%), or an integer number of base units with --raw.
+
+Examples:
   znn-cli token burn zts1... 1000
+  znn-cli token burn zts1... 25%
 
 Requires --keyStore flag to specify which wallet to use.`,
 	Args: cobra.ExactArgs(2),
@@ -32,6 +38,7 @@ Requires --keyStore flag to specify which wallet to use.`,
 }
 
 func init() {
+	burnCmd.Flags().Bool("raw", false, "amount is an integer number of base units")
 	TokenCmd.AddCommand(burnCmd)
 }
 
@@ -44,11 +51,12 @@ func runBurn(cmdCobra *cobra.Command, args []string) error {
 	// Parse arguments
 	tokenStandardStr := args[0]
 	amountStr := args[1]
+	raw, _ := cmdCobra.Flags().GetBool("raw")
 
 	// Parse token standard
 	tokenStandard, err := types.ParseZTS(tokenStandardStr)
 	if err != nil {
-		return fmt.Errorf("invalid token standard: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid token standard: %w", err)
 	}
 
 	// Load wallet
@@ -63,7 +71,7 @@ func runBurn(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -79,33 +87,40 @@ func runBurn(cmdCobra *cobra.Command, args []string) error {
 
 	// Verify burnable
 	if !token.IsBurnable {
-		return fmt.Errorf("token is not burnable")
+		return errs.New(errs.UserInput, "token is not burnable")
 	}
 
-	// Parse amount with token decimals
-	amount, err := format.ParseAmount(amountStr, int(token.Decimals))
+	// Get balance
+	accountInfo, err := rpcClient.LedgerApi.GetAccountInfoByAddress(parsedAddress)
 	if err != nil {
-		return fmt.Errorf("invalid amount: %w", err)
+		return fmt.Errorf("failed to get account info: %w", err)
 	}
 
-	// Check balance
-	accountInfo, err := rpcClient.LedgerApi.GetAccountInfoByAddress(parsedAddress)
+	currentBalance := big.NewInt(0)
+	if balance, found := accountInfo.BalanceInfoMap[tokenStandard]; found && balance.Balance != nil {
+		currentBalance = balance.Balance
+	}
+
+	// Resolve amount against the balance with token decimals
+	amount, err := format.ResolveAmount(amountStr, int(token.Decimals), currentBalance, raw)
 	if err != nil {
-		return fmt.Errorf("failed to get account info: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid amount: %w", err)
+	}
+	if amount.Sign() == 0 {
+		return errs.New(errs.UserInput, "invalid amount: resolves to zero")
 	}
 
-	balance, found := accountInfo.BalanceInfoMap[tokenStandard]
-	if !found || balance.Balance.Cmp(amount) < 0 {
-		return fmt.Errorf("insufficient balance. You have %s but need %s",
-			format.Amount(balance.Balance, int(token.Decimals)),
+	// Check balance
+	if currentBalance.Cmp(amount) < 0 {
+		return errs.Errorf(errs.InsufficientFunds, "insufficient balance. You have %s but need %s",
+			format.Amount(currentBalance, int(token.Decimals)),
 			format.Amount(amount, int(token.Decimals)))
 	}
 
 	// Display burn info
-	fmt.Printf("%s Burning %s %s (%s)\n",
+	fmt.Printf("%s Burning %s (%s)\n",
 		format.Red("Warning!"),
-		format.Amount(amount, int(token.Decimals)),
-		format.Magenta(token.TokenSymbol),
+		format.AmountExpression(amountStr, amount, int(token.Decimals), format.Magenta(token.TokenSymbol)),
 		token.ZenonTokenStandard.String())
 	fmt.Println("This cannot be undone!")
 	fmt.Println()
@@ -114,7 +129,7 @@ func runBurn(cmdCobra *cobra.Command, args []string) error {
 	template := rpcClient.TokenApi.Burn(tokenStandard, amount)
 
 	// Send transaction
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to burn tokens: %w", err)
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
@@ -47,7 +48,7 @@ func runDisableMint(cmdCobra *cobra.Command, args []string) error {
 	tokenStandardStr := args[0]
 	tokenStandard, err := types.ParseZTS(tokenStandardStr)
 	if err != nil {
-		return fmt.Errorf("invalid token standard: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid token standard: %w", err)
 	}
 
 	// Load wallet
@@ -62,7 +63,7 @@ func runDisableMint(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -78,12 +79,12 @@ func runDisableMint(cmdCobra *cobra.Command, args []string) error {
 
 	// Verify ownership
 	if token.Owner.String() != address {
-		return fmt.Errorf("you do not own this token. Owner is %s", token.Owner.String())
+		return errs.Errorf(errs.UserInput, "you do not own this token. Owner is %s", token.Owner.String())
 	}
 
 	// Verify currently mintable
 	if !token.IsMintable {
-		return fmt.Errorf("token minting is already disabled")
+		return errs.New(errs.UserInput, "token minting is already disabled")
 	}
 
 	// Display disable mint info
@@ -98,7 +99,7 @@ func runDisableMint(cmdCobra *cobra.Command, args []string) error {
 	template := rpcClient.TokenApi.UpdateToken(tokenStandard, token.Owner, false, token.IsBurnable)
 
 	// Send transaction
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to disable minting: %w", err)
 	}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
 	"github.com/0x3639/znn_cli_go/pkg/config"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/spf13/cobra"
 	"github.com/zenon-network/go-zenon/common/types"
@@ -38,7 +39,7 @@ func runGetByOwner(cmdCobra *cobra.Command, args []string) error {
 	// Parse owner address
 	ownerAddress, err := types.ParseAddress(ownerAddressStr)
 	if err != nil {
-		return fmt.Errorf("invalid owner address: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid owner address: %w", err)
 	}
 
 	// Parse pagination
@@ -66,7 +67,7 @@ func runGetByOwner(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
 	"github.com/0x3639/znn_cli_go/pkg/config"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/spf13/cobra"
 	"github.com/zenon-network/go-zenon/common/types"
@@ -56,7 +57,7 @@ func runGetByStandard(cmdCobra *cobra.Command, args []string) error {
 	default:
 		tokenStandard, err = types.ParseZTS(tokenStandardStr)
 		if err != nil {
-			return fmt.Errorf("invalid token standard: %w", err)
+			return errs.Errorf(errs.UserInput, "invalid token standard: %w", err)
 		}
 	}
 
@@ -73,7 +74,7 @@ func runGetByStandard(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
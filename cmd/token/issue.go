@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
@@ -69,51 +70,51 @@ func runIssue(cmdCobra *cobra.Command, args []string) error {
 
 	// Validate name and symbol
 	if len(tokenName) < 1 || len(tokenName) > 40 {
-		return fmt.Errorf("token name must be 1-40 characters")
+		return errs.New(errs.UserInput, "token name must be 1-40 characters")
 	}
 	if len(tokenSymbol) < 1 || len(tokenSymbol) > 10 {
-		return fmt.Errorf("token symbol must be 1-10 characters")
+		return errs.New(errs.UserInput, "token symbol must be 1-10 characters")
 	}
 	if len(tokenDomain) > 128 {
-		return fmt.Errorf("domain must be max 128 characters")
+		return errs.New(errs.UserInput, "domain must be max 128 characters")
 	}
 
 	// Parse decimals
 	decimals, err := strconv.ParseUint(decimalsStr, 10, 8)
 	if err != nil || decimals > 18 {
-		return fmt.Errorf("decimals must be 0-18")
+		return errs.New(errs.UserInput, "decimals must be 0-18")
 	}
 
 	// Parse supply amounts
 	totalSupply, err := format.ParseAmount(totalSupplyStr, int(decimals))
 	if err != nil {
-		return fmt.Errorf("invalid total supply: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid total supply: %w", err)
 	}
 
 	maxSupply, err := format.ParseAmount(maxSupplyStr, int(decimals))
 	if err != nil {
-		return fmt.Errorf("invalid max supply: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid max supply: %w", err)
 	}
 
 	// Validate supplies
 	if maxSupply.Cmp(totalSupply) < 0 {
-		return fmt.Errorf("max supply must be >= total supply")
+		return errs.New(errs.UserInput, "max supply must be >= total supply")
 	}
 
 	// Parse boolean flags
 	mintable, err := strconv.ParseBool(mintableStr)
 	if err != nil {
-		return fmt.Errorf("mintable must be true or false")
+		return errs.New(errs.UserInput, "mintable must be true or false")
 	}
 
 	burnable, err := strconv.ParseBool(burnableStr)
 	if err != nil {
-		return fmt.Errorf("burnable must be true or false")
+		return errs.New(errs.UserInput, "burnable must be true or false")
 	}
 
 	utility, err := strconv.ParseBool(utilityStr)
 	if err != nil {
-		return fmt.Errorf("utility must be true or false")
+		return errs.New(errs.UserInput, "utility must be true or false")
 	}
 
 	// Load wallet
@@ -128,7 +129,7 @@ func runIssue(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -149,7 +150,7 @@ func runIssue(cmdCobra *cobra.Command, args []string) error {
 		if found {
 			currentBalance = znnBalance.Balance
 		}
-		return fmt.Errorf("insufficient ZNN for issuance fee. You have %s but need %s",
+		return errs.Errorf(errs.InsufficientFunds, "insufficient ZNN for issuance fee. You have %s but need %s",
 			format.Amount(currentBalance, 8),
 			format.Amount(requiredZnn, 8))
 	}
@@ -180,7 +181,7 @@ func runIssue(cmdCobra *cobra.Command, args []string) error {
 	)
 
 	// Send transaction
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to issue token: %w", err)
 	}
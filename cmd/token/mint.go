@@ -2,8 +2,10 @@ package token
 
 import (
 	"fmt"
+	"math/big"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
@@ -24,8 +26,13 @@ Requirements:
 
 The minted tokens will be sent to the specified receive address.
 
-Example:
+Amount can be a decimal (1,000.5), max (the remaining mintable supply),
+a percentage of the remaining mintable supply (10%), or an integer
+number of base units with --raw.
+
+Examples:
   znn-cli token mint zts1... 1000 z1qz...
+  znn-cli token mint zts1... max z1qz...
 
 Requires --keyStore flag to specify which wallet to use.`,
 	Args: cobra.ExactArgs(3),
@@ -33,6 +40,7 @@ Requires --keyStore flag to specify which wallet to use.`,
 }
 
 func init() {
+	mintCmd.Flags().Bool("raw", false, "amount is an integer number of base units")
 	TokenCmd.AddCommand(mintCmd)
 }
 
@@ -46,17 +54,18 @@ func runMint(cmdCobra *cobra.Command, args []string) error {
 	tokenStandardStr := args[0]
 	amountStr := args[1]
 	receiveAddressStr := args[2]
+	raw, _ := cmdCobra.Flags().GetBool("raw")
 
 	// Parse token standard
 	tokenStandard, err := types.ParseZTS(tokenStandardStr)
 	if err != nil {
-		return fmt.Errorf("invalid token standard: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid token standard: %w", err)
 	}
 
 	// Parse receive address
 	receiveAddress, err := types.ParseAddress(receiveAddressStr)
 	if err != nil {
-		return fmt.Errorf("invalid receive address: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid receive address: %w", err)
 	}
 
 	// Load wallet
@@ -71,7 +80,7 @@ func runMint(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -87,24 +96,34 @@ func runMint(cmdCobra *cobra.Command, args []string) error {
 
 	// Verify ownership
 	if token.Owner.String() != address {
-		return fmt.Errorf("you do not own this token. Owner is %s", token.Owner.String())
+		return errs.Errorf(errs.UserInput, "you do not own this token. Owner is %s", token.Owner.String())
 	}
 
 	// Verify mintable
 	if !token.IsMintable {
-		return fmt.Errorf("token is not mintable")
+		return errs.New(errs.UserInput, "token is not mintable")
 	}
 
-	// Parse amount with token decimals
-	amount, err := format.ParseAmount(amountStr, int(token.Decimals))
+	// Resolve amount against the remaining mintable supply with token decimals
+	mintable := new(big.Int).Sub(token.MaxSupply, token.TotalSupply)
+	if mintable.Sign() < 0 {
+		mintable.SetInt64(0)
+	}
+	amount, err := format.ResolveAmount(amountStr, int(token.Decimals), mintable, raw)
 	if err != nil {
-		return fmt.Errorf("invalid amount: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid amount: %w", err)
+	}
+	if amount.Sign() == 0 {
+		return errs.New(errs.UserInput, "invalid amount: resolves to zero")
+	}
+	if amount.Cmp(mintable) > 0 {
+		return errs.Errorf(errs.UserInput, "amount exceeds the remaining mintable supply of %s",
+			format.Amount(mintable, int(token.Decimals)))
 	}
 
 	// Display mint info
-	fmt.Printf("Minting %s %s (%s)\n",
-		format.Amount(amount, int(token.Decimals)),
-		format.Magenta(token.TokenSymbol),
+	fmt.Printf("Minting %s (%s)\n",
+		format.AmountExpression(amountStr, amount, int(token.Decimals), format.Magenta(token.TokenSymbol)),
 		token.ZenonTokenStandard.String())
 	fmt.Printf("  Receive address: %s\n", receiveAddress.String())
 	fmt.Println()
@@ -113,7 +132,7 @@ func runMint(cmdCobra *cobra.Command, args []string) error {
 	template := rpcClient.TokenApi.Mint(tokenStandard, amount, receiveAddress)
 
 	// Send transaction
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to mint tokens: %w", err)
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/0x3639/znn_cli_go/pkg/transaction"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
@@ -49,13 +50,13 @@ func runTransferOwnership(cmdCobra *cobra.Command, args []string) error {
 	// Parse token standard
 	tokenStandard, err := types.ParseZTS(tokenStandardStr)
 	if err != nil {
-		return fmt.Errorf("invalid token standard: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid token standard: %w", err)
 	}
 
 	// Parse new owner address
 	newOwnerAddress, err := types.ParseAddress(newOwnerAddressStr)
 	if err != nil {
-		return fmt.Errorf("invalid new owner address: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid new owner address: %w", err)
 	}
 
 	// Load wallet
@@ -70,7 +71,7 @@ func runTransferOwnership(cmdCobra *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmdCobra.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -86,7 +87,7 @@ func runTransferOwnership(cmdCobra *cobra.Command, args []string) error {
 
 	// Verify ownership
 	if token.Owner.String() != address {
-		return fmt.Errorf("you do not own this token. Owner is %s", token.Owner.String())
+		return errs.Errorf(errs.UserInput, "you do not own this token. Owner is %s", token.Owner.String())
 	}
 
 	// Display transfer info
@@ -103,7 +104,7 @@ func runTransferOwnership(cmdCobra *cobra.Command, args []string) error {
 	template := rpcClient.TokenApi.UpdateToken(tokenStandard, newOwnerAddress, token.IsMintable, token.IsBurnable)
 
 	// Send transaction
-	err = transaction.BuildAndSend(rpcClient.RpcClient, parsedAddress, template, keypair)
+	err = transaction.BuildAndSend(cmdCobra.Context(), rpcClient.RpcClient, parsedAddress, template, keypair)
 	if err != nil {
 		return fmt.Errorf("failed to transfer ownership: %w", err)
 	}
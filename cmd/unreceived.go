@@ -43,7 +43,7 @@ func runUnreceived(cmd *cobra.Command, args []string) error {
 	}
 
 	// Connect to node
-	rpcClient, err := client.New(cfg.Node.URL)
+	rpcClient, err := client.New(cmd.Context(), cfg.Node.URL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to node: %w", err)
 	}
@@ -73,6 +73,9 @@ func runUnreceived(cmd *cobra.Command, args []string) error {
 		fmt.Printf("\n%d. Hash: %s\n", i+1, format.Cyan(block.Hash.String()))
 		fmt.Printf("   From: %s\n", format.Cyan(block.Address.String()))
 		fmt.Printf("   Amount: %s %s\n", amount, format.FormatToken(block.Amount, decimals, symbol))
+		if memo := format.Memo(block.Data); memo != "" {
+			fmt.Printf("   Memo: %s\n", memo)
+		}
 	}
 
 	return nil
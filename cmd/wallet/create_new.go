@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/0x3639/znn_cli_go/internal/prompt"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
 	"github.com/spf13/cobra"
@@ -33,7 +34,7 @@ func runCreateNew(cmd *cobra.Command, args []string) error {
 	}
 
 	if passphrase == "" {
-		return fmt.Errorf("passphrase cannot be empty")
+		return errs.New(errs.UserInput, "passphrase cannot be empty")
 	}
 
 	// Get optional wallet name
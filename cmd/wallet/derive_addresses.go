@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/0x3639/znn_cli_go/cmd"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/0x3639/znn_cli_go/pkg/wallet"
 	"github.com/spf13/cobra"
@@ -35,16 +36,16 @@ func runDeriveAddresses(c *cobra.Command, args []string) error {
 	// Parse arguments
 	start, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid start index: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid start index: %w", err)
 	}
 
 	end, err := strconv.Atoi(args[1])
 	if err != nil {
-		return fmt.Errorf("invalid end index: %w", err)
+		return errs.Errorf(errs.UserInput, "invalid end index: %w", err)
 	}
 
 	if start < 0 || end < start {
-		return fmt.Errorf("invalid range: start=%d end=%d", start, end)
+		return errs.Errorf(errs.UserInput, "invalid range: start=%d end=%d", start, end)
 	}
 
 	cfg := cmd.GetConfig()
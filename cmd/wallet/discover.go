@@ -0,0 +1,179 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/0x3639/znn_cli_go/cmd"
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/config"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+)
+
+// discoverCmd finds derived addresses that have on-chain activity
+var discoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Discover used addresses in the wallet",
+	Long: `Walk derived addresses starting at index 0 and report the ones that
+have been used on chain (account height, balances, or pending blocks).
+
+Discovery stops after --gap consecutive unused indices (default: 20).
+
+With --save, the used addresses are stored as known accounts in the config
+file so other commands can refer to them as @label. Labels are built from
+--label (default: the keyStore name) and the index, e.g. main-3.
+
+Examples:
+  znn-cli wallet discover
+  znn-cli wallet discover --gap 50 --save --label treasury
+
+Requires --keyStore flag to specify which wallet to use.`,
+	Args: cobra.NoArgs,
+	RunE: runDiscover,
+}
+
+func init() {
+	discoverCmd.Flags().Int("gap", wallet.DefaultGapLimit, "number of consecutive unused indices before stopping")
+	discoverCmd.Flags().Bool("save", false, "save used addresses as known accounts in the config file")
+	discoverCmd.Flags().String("label", "", "label prefix for saved accounts (default: keyStore name)")
+	walletCmd.AddCommand(discoverCmd)
+}
+
+func runDiscover(c *cobra.Command, args []string) error {
+	gapLimit, _ := c.Flags().GetInt("gap")
+	save, _ := c.Flags().GetBool("save")
+	labelPrefix, _ := c.Flags().GetString("label")
+
+	cfg := cmd.GetConfig()
+	keystoreName := cmd.GetKeyStore()
+	passphrase := cmd.GetPassphrase()
+
+	// Load wallet
+	keyStore, _, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, 0)
+	if err != nil {
+		return err
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(c.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	fmt.Printf("Scanning addresses (gap limit %d)...\n", gapLimit)
+
+	accountInfos := make(map[int]*api.AccountInfo)
+	pendingCounts := make(map[int]int)
+
+	used, scanned, err := wallet.Discover(keyStore, gapLimit, func(index int, address types.Address) (bool, error) {
+		accountInfo, err := rpcClient.LedgerApi.GetAccountInfoByAddress(address)
+		if err != nil {
+			return false, fmt.Errorf("failed to get account info for %s: %w", address, err)
+		}
+
+		unreceived, err := rpcClient.LedgerApi.GetUnreceivedBlocksByAddress(address, 0, 1)
+		if err != nil {
+			return false, fmt.Errorf("failed to get unreceived blocks for %s: %w", address, err)
+		}
+
+		accountInfos[index] = accountInfo
+		pendingCounts[index] = len(unreceived.List)
+		if unreceived.More {
+			pendingCounts[index]++
+		}
+
+		return accountInfo.AccountHeight > 0 || len(unreceived.List) > 0 || hasBalance(accountInfo), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Scanned %d indices\n", scanned)
+	fmt.Println()
+
+	if len(used) == 0 {
+		fmt.Println("No used addresses found")
+		return nil
+	}
+
+	fmt.Printf("Found %s used address(es):\n", format.Green(fmt.Sprintf("%d", len(used))))
+	for _, entry := range used {
+		accountInfo := accountInfos[entry.Index]
+		fmt.Printf("\n  %d\t%s\n", entry.Index, format.Cyan(entry.Address.String()))
+		fmt.Printf("  \tHeight: %d\n", accountInfo.AccountHeight)
+		for _, balanceInfo := range accountInfo.BalanceInfoMap {
+			if balanceInfo.Balance == nil || balanceInfo.Balance.Sign() == 0 {
+				continue
+			}
+			fmt.Printf("  \t%s\n", format.FormatToken(balanceInfo.Balance,
+				int(balanceInfo.TokenInfo.Decimals), balanceInfo.TokenInfo.TokenSymbol))
+		}
+		if pendingCounts[entry.Index] > 0 {
+			fmt.Printf("  \t%s\n", format.Yellow("Has unreceived blocks"))
+		}
+	}
+
+	if !save {
+		return nil
+	}
+
+	if labelPrefix == "" {
+		labelPrefix = keystoreName
+	}
+	if labelPrefix == "" {
+		labelPrefix = "account"
+	}
+
+	return saveDiscovered(used, keystoreName, labelPrefix)
+}
+
+// hasBalance reports whether the account holds a non-zero balance of any token
+func hasBalance(accountInfo *api.AccountInfo) bool {
+	for _, balanceInfo := range accountInfo.BalanceInfoMap {
+		if balanceInfo.Balance != nil && balanceInfo.Balance.Sign() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// saveDiscovered stores the used addresses as known accounts in the config file
+func saveDiscovered(used []wallet.DiscoveredAddress, keystoreName, labelPrefix string) error {
+	path := cmd.GetConfigFile()
+	if path == "" {
+		defaultPath, err := config.DefaultConfigPath()
+		if err != nil {
+			return err
+		}
+		path = defaultPath
+	}
+
+	// Reload the file so flag overrides are not persisted
+	fileCfg, err := config.Load(cmd.GetConfigFile())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	fmt.Println()
+	for _, entry := range used {
+		label := fmt.Sprintf("%s-%d", labelPrefix, entry.Index)
+		fileCfg.AddAccount(config.AccountConfig{
+			Label:    label,
+			Address:  entry.Address.String(),
+			KeyStore: keystoreName,
+			Index:    entry.Index,
+		})
+		fmt.Printf("  Saved %s as %s\n", entry.Address.String(), format.Green("@"+label))
+	}
+
+	if err := config.SaveKey(path, "accounts", fileCfg.Accounts); err != nil {
+		return err
+	}
+
+	format.Success(fmt.Sprintf("Saved %d account(s) to %s", len(used), path))
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/0x3639/znn_cli_go/cmd"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 	"github.com/0x3639/znn_cli_go/pkg/format"
 	"github.com/spf13/cobra"
 )
@@ -38,7 +39,7 @@ func runExport(c *cobra.Command, args []string) error {
 	keystoreName := cmd.GetKeyStore()
 
 	if keystoreName == "" {
-		return fmt.Errorf("--keyStore flag is required to specify which wallet to export")
+		return errs.New(errs.UserInput, "--keyStore flag is required to specify which wallet to export")
 	}
 
 	// Get the source keyStore file path
@@ -46,7 +47,7 @@ func runExport(c *cobra.Command, args []string) error {
 
 	// Check if source exists
 	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-		return fmt.Errorf("keyStore %s not found", keystoreName)
+		return errs.Errorf(errs.UserInput, "keyStore %s not found", keystoreName)
 	}
 
 	// Open source file
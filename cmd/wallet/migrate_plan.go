@@ -0,0 +1,311 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/cmd"
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/migration"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// defaultPlanFile is the default path of the migration plan file
+const defaultPlanFile = "migration-plan.json"
+
+// migratePlanCmd creates a key-rotation migration plan
+var migratePlanCmd = &cobra.Command{
+	Use:     "migratePlan",
+	Aliases: []string{"migrate-plan"},
+	Short:   "Plan the migration of an account to a new key",
+	Long: `Inspect the current address and write an ordered, resumable plan for
+moving it to a new address.
+
+The plan covers:
+  - Token ownership transfers
+  - Reward collection (stake, pillar, sentinel)
+  - Stake revocation at each entry's expiration time
+  - Fusion cancellation at each entry's expiration height, re-fused from the new address
+  - Pillar and sentinel revocation and QSR withdrawal
+  - Delegation of the new address to the same pillar
+  - Transfers of all balances after funds are released, received by the new
+    address before it fuses or delegates
+
+Each step shows the earliest time it can be performed. Steps signed by the
+new address are executed only when the new key is in the same keyStore
+(--to-index, or an @label saved for the same keyStore).
+
+Examples:
+  znn-cli wallet migratePlan --to z1qz...
+  znn-cli wallet migratePlan --to-index 5 --file rotation.json
+  znn-cli wallet migratePlan execute --file rotation.json
+
+Requires --keyStore flag to specify which wallet to use.`,
+	Args: cobra.NoArgs,
+	RunE: runMigratePlan,
+}
+
+// migrateExecuteCmd performs the due steps of a migration plan
+var migrateExecuteCmd = &cobra.Command{
+	Use:   "execute",
+	Short: "Perform the migration steps that are due",
+	Long: `Perform the pending steps of a migration plan that are due now, in order,
+and record progress in the plan file.
+
+Execution stops at the first step that is not yet due or that fails, so it
+can be safely re-run later to resume. Manual steps are reported and can be
+marked as done with --mark-done.
+
+Examples:
+  znn-cli wallet migratePlan execute
+  znn-cli wallet migratePlan execute --file rotation.json --mark-done 12
+
+Requires --keyStore flag to specify which wallet to use.`,
+	Args: cobra.NoArgs,
+	RunE: runMigrateExecute,
+}
+
+// migrateShowCmd displays a migration plan and its progress
+var migrateShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Display a migration plan and its progress",
+	Args:  cobra.NoArgs,
+	RunE:  runMigrateShow,
+}
+
+func init() {
+	migratePlanCmd.PersistentFlags().String("file", defaultPlanFile, "migration plan file")
+	migratePlanCmd.Flags().String("to", "", "new address or @label")
+	migratePlanCmd.Flags().Int("to-index", -1, "index of the new address in the same keyStore")
+	migrateExecuteCmd.Flags().Int("mark-done", 0, "mark a manual step as done by ID")
+
+	migratePlanCmd.AddCommand(migrateExecuteCmd)
+	migratePlanCmd.AddCommand(migrateShowCmd)
+	walletCmd.AddCommand(migratePlanCmd)
+}
+
+func runMigratePlan(c *cobra.Command, args []string) error {
+	planFile, _ := c.Flags().GetString("file")
+	toStr, _ := c.Flags().GetString("to")
+	toIndex, _ := c.Flags().GetInt("to-index")
+
+	cfg := cmd.GetConfig()
+	keystoreName := cmd.GetKeyStore()
+	passphrase := cmd.GetPassphrase()
+	index := cmd.GetIndex()
+
+	if toStr == "" && toIndex < 0 {
+		return errs.New(errs.UserInput, "specify the new address with --to or --to-index")
+	}
+
+	// Load wallet
+	keyStore, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, passphrase, index)
+	if err != nil {
+		return err
+	}
+
+	address, err := wallet.GetAddress(keypair)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the new address and, when possible, its index in this keyStore
+	var target types.Address
+	var targetIndex *int
+	if toIndex >= 0 {
+		addresses, err := wallet.DeriveAddresses(keyStore, toIndex, toIndex)
+		if err != nil {
+			return err
+		}
+		target = types.ParseAddressPanic(addresses[0])
+		targetIndex = &toIndex
+	} else {
+		target, err = cfg.ResolveAddress(toStr)
+		if err != nil {
+			return errs.Errorf(errs.UserInput, "invalid new address: %w", err)
+		}
+		if account, found := cfg.FindAccount(toStr); found && account.KeyStore == keystoreName {
+			accountIndex := account.Index
+			targetIndex = &accountIndex
+		}
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(c.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	fmt.Printf("Inspecting %s...\n", format.Cyan(address))
+
+	plan, err := migration.Inspect(rpcClient, types.ParseAddressPanic(address), target, time.Now())
+	if err != nil {
+		return err
+	}
+	plan.KeyStore = keystoreName
+	plan.SourceIndex = index
+	plan.TargetIndex = targetIndex
+
+	if err := plan.Save(planFile); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	printPlan(plan)
+	fmt.Println()
+	format.Success(fmt.Sprintf("Plan with %d step(s) written to %s", len(plan.Steps), planFile))
+	fmt.Printf("Use %s to perform the steps that are due\n", format.Green("wallet migratePlan execute"))
+
+	return nil
+}
+
+func runMigrateExecute(c *cobra.Command, args []string) error {
+	planFile, _ := c.Flags().GetString("file")
+	markDone, _ := c.Flags().GetInt("mark-done")
+
+	plan, err := migration.Load(planFile)
+	if err != nil {
+		return err
+	}
+
+	if markDone > 0 {
+		step, found := plan.Step(markDone)
+		if !found {
+			return errs.Errorf(errs.UserInput, "no step with ID %d", markDone)
+		}
+		step.MarkDone(time.Now())
+		if err := plan.Save(planFile); err != nil {
+			return err
+		}
+		format.Success(fmt.Sprintf("Step %d marked as done", markDone))
+		return nil
+	}
+
+	cfg := cmd.GetConfig()
+	keystoreName := plan.KeyStore
+	if keystoreName == "" {
+		keystoreName = cmd.GetKeyStore()
+	}
+
+	// Load wallet with the old key
+	keyStore, oldKey, err := wallet.LoadWallet(cfg.Wallet.WalletDir, keystoreName, cmd.GetPassphrase(), plan.SourceIndex)
+	if err != nil {
+		return err
+	}
+
+	address, err := wallet.GetAddress(oldKey)
+	if err != nil {
+		return err
+	}
+	if address != plan.Source {
+		return errs.Errorf(errs.UserInput, "wallet address %s does not match plan source %s", address, plan.Source)
+	}
+
+	executor := &migration.Executor{Plan: plan, OldKey: oldKey}
+	if plan.TargetIndex != nil {
+		newKey, err := wallet.DeriveKeyPair(keyStore, *plan.TargetIndex)
+		if err != nil {
+			return err
+		}
+		executor.NewKey = newKey
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(c.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+	executor.Client = rpcClient.RpcClient
+
+	momentum, err := rpcClient.LedgerApi.GetFrontierMomentum()
+	if err != nil {
+		return fmt.Errorf("failed to get frontier momentum: %w", err)
+	}
+
+	due := plan.Due(time.Now(), momentum.Height)
+	if len(due) == 0 {
+		fmt.Println("No steps are due")
+	}
+
+	var execErr error
+	for _, step := range due {
+		fmt.Printf("%d. %s\n", step.ID, step.Description)
+
+		hashes, err := executor.Execute(c.Context(), step)
+		switch {
+		case errors.Is(err, migration.ErrManualStep):
+			fmt.Printf("   %s Perform this step manually, then run with --mark-done %d\n", format.Yellow("Manual:"), step.ID)
+			continue
+		case errors.Is(err, migration.ErrNewKeyRequired):
+			fmt.Printf("   %s Requires the new key; re-create the plan with --to-index\n", format.Yellow("Skipped:"))
+			continue
+		case err != nil:
+			step.MarkFailed(err, hashes...)
+			execErr = fmt.Errorf("step %d failed: %w", step.ID, err)
+		default:
+			step.MarkDone(time.Now(), hashes...)
+			for _, hash := range hashes {
+				fmt.Printf("   %s\n", format.Cyan(hash))
+			}
+		}
+
+		if err := plan.Save(planFile); err != nil {
+			return err
+		}
+		if execErr != nil {
+			return execErr
+		}
+	}
+
+	fmt.Println()
+	printPlan(plan)
+	return nil
+}
+
+func runMigrateShow(c *cobra.Command, args []string) error {
+	planFile, _ := c.Flags().GetString("file")
+
+	plan, err := migration.Load(planFile)
+	if err != nil {
+		return err
+	}
+
+	printPlan(plan)
+	return nil
+}
+
+// printPlan displays the plan steps with their status and earliest time
+func printPlan(plan *migration.Plan) {
+	fmt.Printf("Migration %s -> %s\n", format.Cyan(plan.Source), format.Cyan(plan.Target))
+	fmt.Printf("%d of %d step(s) remaining\n", plan.Remaining(), len(plan.Steps))
+	fmt.Println()
+
+	for _, step := range plan.Steps {
+		status := string(step.Status)
+		switch step.Status {
+		case migration.StatusDone:
+			status = format.Green(status)
+		case migration.StatusFailed:
+			status = format.Red(status)
+		default:
+			status = format.Yellow(status)
+		}
+
+		fmt.Printf("%d. [%s] %s\n", step.ID, status, step.Description)
+		when := fmt.Sprintf("   Earliest: %s", step.NotBefore.Local().Format("2006-01-02 15:04:05"))
+		if step.NotBeforeHeight > 0 {
+			when += fmt.Sprintf(" (momentum %d)", step.NotBeforeHeight)
+		}
+		fmt.Printf("%s, signed by %s address\n", when, step.Signer)
+		if step.Error != "" {
+			fmt.Printf("   Error: %s\n", step.Error)
+		}
+	}
+}
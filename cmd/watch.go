@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/wallet"
+	"github.com/0x3639/znn_cli_go/pkg/watch"
+	"github.com/spf13/cobra"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// watchCmd monitors addresses and delivers events
+var watchCmd = &cobra.Command{
+	Use:   "watch [address|@label...]",
+	Short: "Watch addresses and notify webhooks or hook commands",
+	Long: `Monitor addresses and report events:
+  unreceived         a new block was sent to the address
+  received           a receive block of the address was confirmed
+  balance            a token balance changed
+  stakeExpired       a stake entry can be revoked
+  fusionCancellable  a fusion entry can be canceled
+
+Each event is POSTed as JSON to every --webhook URL and piped to every
+--exec command on stdin (with ZNN_EVENT_TYPE, ZNN_EVENT_ID and
+ZNN_EVENT_ADDRESS set). Webhooks require a secret (--secret, or secret in
+the config file) and carry an X-Znn-Signature: sha256=<hex HMAC-SHA256 of
+the body> header.
+
+Failed deliveries are retried with backoff and kept in the state file until
+they succeed. The state file also records what has been seen, so a restarted
+watcher neither misses nor repeats events. Events carry a stable "id" that
+receivers can use to deduplicate. On the first run the current pending
+blocks and balances are recorded without notifying.
+
+Addresses, webhooks and hooks default to the watch section of the config
+file. Without addresses, the address selected by --keyStore is watched.
+
+Examples:
+  znn-cli watch z1qq... --webhook https://example.com/hook --secret s3cret
+  znn-cli watch @savings --exec 'logger -t znn'
+  znn-cli watch --keyStore my-wallet --once`,
+	RunE: runWatch,
+}
+
+func init() {
+	watchCmd.Flags().StringSlice("webhook", nil, "webhook URL to POST events to (repeatable)")
+	watchCmd.Flags().String("secret", "", "secret used to sign webhook payloads (required with --webhook)")
+	watchCmd.Flags().StringSlice("exec", nil, "shell command to run per event (repeatable)")
+	watchCmd.Flags().Duration("interval", 0, "poll interval (default from config, or 10s)")
+	watchCmd.Flags().String("state", "", "state file (default ~/.znn/watch-state.json)")
+	watchCmd.Flags().Bool("once", false, "poll and deliver once, then exit")
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	cfg := GetConfig()
+
+	webhookURLs, _ := cmd.Flags().GetStringSlice("webhook")
+	secret, _ := cmd.Flags().GetString("secret")
+	hooks, _ := cmd.Flags().GetStringSlice("exec")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	statePath, _ := cmd.Flags().GetString("state")
+	once, _ := cmd.Flags().GetBool("once")
+
+	// Resolve addresses
+	values := args
+	if len(values) == 0 {
+		values = cfg.Watch.Addresses
+	}
+	var addresses []types.Address
+	for _, value := range values {
+		address, err := cfg.ResolveAddress(value)
+		if err != nil {
+			return errs.Errorf(errs.UserInput, "invalid address %s: %w", value, err)
+		}
+		addresses = append(addresses, address)
+	}
+	if len(addresses) == 0 {
+		_, keypair, err := wallet.LoadWallet(cfg.Wallet.WalletDir, GetKeyStore(), GetPassphrase(), GetIndex())
+		if err != nil {
+			return fmt.Errorf("no addresses to watch: %w", err)
+		}
+		address, err := wallet.GetAddress(keypair)
+		if err != nil {
+			return err
+		}
+		addresses = append(addresses, types.ParseAddressPanic(address))
+	}
+
+	// Build sinks
+	var webhooks []*watch.Webhook
+	if len(webhookURLs) > 0 || len(hooks) > 0 {
+		for _, url := range webhookURLs {
+			webhooks = append(webhooks, &watch.Webhook{URL: url, Secret: secret})
+		}
+	} else {
+		for _, webhook := range cfg.Watch.Webhooks {
+			webhooks = append(webhooks, &watch.Webhook{URL: webhook.URL, Secret: webhook.Secret})
+		}
+		hooks = cfg.Watch.Hooks
+	}
+	var sinks []watch.Sink
+	for _, webhook := range webhooks {
+		if webhook.Secret == "" {
+			return errs.Errorf(errs.UserInput, "webhook %s has no secret to sign payloads with (use --secret or set secret in the config file)", webhook.URL)
+		}
+		sinks = append(sinks, webhook)
+	}
+	for _, hook := range hooks {
+		sinks = append(sinks, &watch.Command{Command: hook})
+	}
+
+	if interval == 0 {
+		interval = cfg.Watch.Interval
+	}
+	if interval == 0 {
+		interval = 10 * time.Second
+	}
+	if interval < time.Second {
+		return errs.New(errs.UserInput, "interval must be at least 1s")
+	}
+
+	if statePath == "" {
+		statePath = cfg.Watch.StateFile
+	}
+	if statePath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory: %w", err)
+		}
+		statePath = filepath.Join(home, ".znn", "watch-state.json")
+	}
+
+	state, err := watch.LoadState(statePath)
+	if err != nil {
+		return err
+	}
+
+	// Connect to node
+	rpcClient, err := client.New(cmd.Context(), cfg.Node.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to node: %w", err)
+	}
+	defer func() { _ = rpcClient.Close() }()
+
+	watcher := &watch.Watcher{
+		Client:    rpcClient.RpcClient,
+		Addresses: addresses,
+		State:     state,
+	}
+	dispatcher := watch.NewDispatcher(sinks)
+	save := func() error { return state.Save(statePath) }
+
+	ctx := cmd.Context()
+
+	fmt.Printf("Watching %d address(es), %d sink(s), every %s\n", len(addresses), len(sinks), interval)
+	fmt.Printf("State: %s\n", statePath)
+	if len(sinks) == 0 {
+		format.Warning("No webhooks or hooks configured; events are only printed")
+	}
+	if len(state.Outbox) > 0 {
+		fmt.Printf("%d undelivered event(s) from a previous run\n", len(state.Outbox))
+	}
+
+	for {
+		events, pollErr := watcher.Poll()
+		for _, event := range events {
+			printWatchEvent(event)
+		}
+
+		// Persist detected events before delivering so a crash cannot lose them
+		state.Enqueue(events, dispatcher.SinkNames())
+		if err := save(); err != nil {
+			return err
+		}
+
+		if pollErr != nil {
+			format.Warning(fmt.Sprintf("Poll failed: %v", pollErr))
+		}
+
+		delivered, err := dispatcher.Flush(ctx, state, save)
+		if err != nil {
+			return err
+		}
+		if delivered > 0 {
+			fmt.Printf("Delivered %d notification(s)\n", delivered)
+		}
+		for _, delivery := range state.Outbox {
+			if ctx.Err() == nil && delivery.LastError != "" {
+				format.Warning(fmt.Sprintf("Delivery of %s pending: %s", delivery.Event.ID, delivery.LastError))
+			}
+		}
+
+		if once {
+			if pollErr != nil {
+				return pollErr
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("Watcher stopped")
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// printWatchEvent prints a one-line summary of an event
+func printWatchEvent(event watch.Event) {
+	line := fmt.Sprintf("%s %s %s", event.Time.Format(time.RFC3339), format.Green(string(event.Type)), format.Cyan(event.Address))
+	if event.Amount != "" {
+		line += fmt.Sprintf(" amount=%s", event.Amount)
+		if event.Symbol != "" {
+			line += " " + event.Symbol
+		}
+	}
+	if event.Previous != "" {
+		line += fmt.Sprintf(" previous=%s", event.Previous)
+	}
+	if event.Hash != "" {
+		line += " hash=" + event.Hash
+	}
+	if event.EntryID != "" {
+		line += " id=" + event.EntryID
+	}
+	fmt.Println(line)
+}
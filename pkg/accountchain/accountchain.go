@@ -0,0 +1,178 @@
+// Package accountchain fetches, exports and verifies the chain of account
+// blocks of an address. Each block must link to the previous one by hash,
+// match its own hash, be signed by the key of the address and acknowledge a
+// momentum no older than the one before it.
+package accountchain
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+)
+
+// PageSize is the number of blocks requested at a time
+const PageSize = 100
+
+// Verifier checks the blocks of one account chain in height order
+type Verifier struct {
+	address types.Address
+	height  uint64
+	// frontier is the hash of the last block checked
+	frontier types.Hash
+	// momentum is the momentum height acknowledged by the last block
+	momentum uint64
+	unsigned int
+}
+
+// NewVerifier creates a verifier for the chain of address
+func NewVerifier(address types.Address) *Verifier {
+	return &Verifier{address: address}
+}
+
+// Check verifies the next block of the chain
+func (v *Verifier) Check(block *nom.AccountBlock) error {
+	next := v.height + 1
+	if block == nil {
+		return fmt.Errorf("block %d: missing", next)
+	}
+	if block.Height != next {
+		return fmt.Errorf("block %d: found height %d instead", next, block.Height)
+	}
+	if block.Address != v.address {
+		return fmt.Errorf("block %d: belongs to %s", next, block.Address)
+	}
+	if block.PreviousHash != v.frontier {
+		return fmt.Errorf("block %d: previous hash %s does not match block %d %s", next, block.PreviousHash, v.height, v.frontier)
+	}
+	if hash := block.ComputeHash(); hash != block.Hash {
+		return fmt.Errorf("block %d: hash %s does not match its contents, which hash to %s", next, block.Hash, hash)
+	}
+	signed, err := checkSignature(block)
+	if err != nil {
+		return fmt.Errorf("block %d: %w", next, err)
+	}
+	if block.MomentumAcknowledged.Height < v.momentum {
+		return fmt.Errorf("block %d: acknowledges momentum %d, older than momentum %d acknowledged by block %d",
+			next, block.MomentumAcknowledged.Height, v.momentum, v.height)
+	}
+
+	if !signed {
+		v.unsigned++
+	}
+	v.height = next
+	v.frontier = block.Hash
+	v.momentum = block.MomentumAcknowledged.Height
+	return nil
+}
+
+// Height returns the height of the last block checked
+func (v *Verifier) Height() uint64 {
+	return v.height
+}
+
+// Frontier returns the hash of the last block checked
+func (v *Verifier) Frontier() types.Hash {
+	return v.frontier
+}
+
+// Unsigned returns the number of genesis and contract blocks checked, which
+// carry no signature
+func (v *Verifier) Unsigned() int {
+	return v.unsigned
+}
+
+// checkSignature verifies the signature of a block against its public key
+// and the public key against the address. Genesis blocks and the blocks of
+// embedded contracts are not signed.
+func checkSignature(block *nom.AccountBlock) (bool, error) {
+	if block.BlockType == nom.BlockTypeGenesisReceive || types.IsEmbeddedAddress(block.Address) {
+		if len(block.PublicKey) != 0 || len(block.Signature) != 0 {
+			return false, fmt.Errorf("unsigned %s block has a public key or signature", blockKind(block))
+		}
+		return false, nil
+	}
+	if len(block.PublicKey) != ed25519.PublicKeySize {
+		return false, fmt.Errorf("invalid public key of %d bytes", len(block.PublicKey))
+	}
+	if owner := types.PubKeyToAddress(block.PublicKey); owner != block.Address {
+		return false, fmt.Errorf("public key belongs to %s, not the account", owner)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(block.PublicKey), block.Hash.Bytes(), block.Signature) {
+		return false, fmt.Errorf("invalid signature")
+	}
+	return true, nil
+}
+
+func blockKind(block *nom.AccountBlock) string {
+	if block.BlockType == nom.BlockTypeGenesisReceive {
+		return "genesis"
+	}
+	return "contract"
+}
+
+// Fetch calls visit with every block of an address in height order
+func Fetch(ctx context.Context, c *rpc_client.RpcClient, address types.Address, visit func(*api.AccountBlock) error) error {
+	for height := uint64(1); ; {
+		if err := ctx.Err(); err != nil {
+			return errs.FromRPC(err)
+		}
+		list, err := c.LedgerApi.GetAccountBlocksByHeight(address, height, PageSize)
+		if err != nil {
+			return fmt.Errorf("failed to get blocks from height %d: %w", height, errs.FromRPC(err))
+		}
+		for _, block := range list.List {
+			if err := visit(block); err != nil {
+				return err
+			}
+		}
+		height += uint64(len(list.List))
+		if len(list.List) < PageSize {
+			return nil
+		}
+	}
+}
+
+// Write writes a block as one line of an export
+func Write(w io.Writer, block *api.AccountBlock) error {
+	data, err := json.Marshal(block)
+	if err != nil {
+		return fmt.Errorf("failed to encode block %d: %w", block.Height, err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write block %d: %w", block.Height, err)
+	}
+	return nil
+}
+
+// Read calls visit with every block of an export
+func Read(r io.Reader, visit func(*api.AccountBlock) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		block := new(api.AccountBlock)
+		if err := json.Unmarshal(scanner.Bytes(), block); err != nil {
+			return fmt.Errorf("failed to parse line %d: %w", line, err)
+		}
+		if err := visit(block); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package accountchain
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/constants"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/testutil"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+)
+
+// publishChain publishes two sends and a receive for the fixture address
+// and returns its blocks
+func publishChain(t *testing.T) (types.Address, []*api.AccountBlock) {
+	t.Helper()
+	node := testutil.NewNode(t)
+	c, err := client.New(t.Context(), node.URL)
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	ks, err := wallet.NewKeyStoreFromMnemonic(testutil.MnemonicFixture)
+	require.NoError(t, err)
+	keypair, err := ks.GetKeyPair(0)
+	require.NoError(t, err)
+	address, err := keypair.GetAddress()
+	require.NoError(t, err)
+	node.SetPlasma(*address, 10*constants.AccountBlockBasePlasma)
+	node.SetBalance(*address, types.ZnnTokenStandard, big.NewInt(10e8))
+
+	for i := 0; i < 2; i++ {
+		template := c.LedgerApi.SendTemplate(testutil.ValidAddress, types.ZnnTokenStandard, big.NewInt(1e8), nil)
+		require.NoError(t, transaction.BuildAndSend(t.Context(), c.RpcClient, *address, template, keypair))
+		node.AddMomentums(2)
+	}
+	node.Send(testutil.ProducerAddress, *address, types.QsrTokenStandard, big.NewInt(5e8))
+	_, err = transaction.ReceiveAll(t.Context(), c.RpcClient, *address, keypair, nil)
+	require.NoError(t, err)
+
+	var blocks []*api.AccountBlock
+	require.NoError(t, Fetch(t.Context(), c.RpcClient, *address, func(block *api.AccountBlock) error {
+		blocks = append(blocks, block)
+		return nil
+	}))
+	return *address, blocks
+}
+
+func verify(address types.Address, blocks []*api.AccountBlock) error {
+	v := NewVerifier(address)
+	for _, block := range blocks {
+		if err := v.Check(&block.AccountBlock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestVerifyFetchedChain(t *testing.T) {
+	address, blocks := publishChain(t)
+	require.Len(t, blocks, 3)
+
+	v := NewVerifier(address)
+	for _, block := range blocks {
+		require.NoError(t, v.Check(&block.AccountBlock))
+	}
+	assert.Equal(t, uint64(3), v.Height())
+	assert.Equal(t, blocks[2].Hash, v.Frontier())
+	assert.Equal(t, 0, v.Unsigned())
+
+	assert.ErrorContains(t, verify(testutil.ValidAddress, blocks), "block 1: belongs to")
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	address, blocks := publishChain(t)
+
+	var export bytes.Buffer
+	for _, block := range blocks {
+		require.NoError(t, Write(&export, block))
+	}
+	assert.Equal(t, 3, strings.Count(export.String(), "\n"))
+
+	var read []*api.AccountBlock
+	require.NoError(t, Read(bytes.NewReader(export.Bytes()), func(block *api.AccountBlock) error {
+		read = append(read, block)
+		return nil
+	}))
+	require.Len(t, read, 3)
+	assert.NoError(t, verify(address, read))
+
+	err := Read(strings.NewReader("{\"height\":1}\nnot json\n"), func(*api.AccountBlock) error { return nil })
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestVerifyTamperedChain(t *testing.T) {
+	address, blocks := publishChain(t)
+	copyBlocks := func() []*api.AccountBlock {
+		copied := make([]*api.AccountBlock, len(blocks))
+		for i, block := range blocks {
+			c := *block
+			c.Signature = append([]byte(nil), block.Signature...)
+			c.PublicKey = append([]byte(nil), block.PublicKey...)
+			copied[i] = &c
+		}
+		return copied
+	}
+
+	tests := []struct {
+		name   string
+		tamper func([]*api.AccountBlock) []*api.AccountBlock
+		err    string
+	}{
+		{"missing block", func(b []*api.AccountBlock) []*api.AccountBlock {
+			return append(b[:1], b[2:]...)
+		}, "block 2: found height 3"},
+		{"changed amount", func(b []*api.AccountBlock) []*api.AccountBlock {
+			b[1].Amount = big.NewInt(2e8)
+			return b
+		}, "block 2: hash"},
+		{"relinked", func(b []*api.AccountBlock) []*api.AccountBlock {
+			b[2].PreviousHash = b[0].Hash
+			return b
+		}, "block 3: previous hash"},
+		{"bad signature", func(b []*api.AccountBlock) []*api.AccountBlock {
+			b[0].Signature[0] ^= 0xff
+			return b
+		}, "block 1: invalid signature"},
+		{"foreign key", func(b []*api.AccountBlock) []*api.AccountBlock {
+			b[0].PublicKey[0] ^= 0xff
+			return b
+		}, "block 1: public key belongs to"},
+		{"unsigned", func(b []*api.AccountBlock) []*api.AccountBlock {
+			b[0].PublicKey = nil
+			return b
+		}, "block 1: invalid public key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, verify(address, tt.tamper(copyBlocks())), tt.err)
+		})
+	}
+}
+
+func TestVerifyMomentumOrder(t *testing.T) {
+	ks, err := wallet.NewKeyStoreFromMnemonic(testutil.MnemonicFixture)
+	require.NoError(t, err)
+	keypair, err := ks.GetKeyPair(0)
+	require.NoError(t, err)
+	address, err := keypair.GetAddress()
+	require.NoError(t, err)
+	publicKey, err := keypair.GetPublicKey()
+	require.NoError(t, err)
+
+	// sign builds a signed block acknowledging a momentum height
+	sign := func(height, momentum uint64, previous types.Hash) *nom.AccountBlock {
+		block := &nom.AccountBlock{
+			Version:              1,
+			ChainIdentifier:      1,
+			BlockType:            nom.BlockTypeUserSend,
+			Height:               height,
+			PreviousHash:         previous,
+			Address:              *address,
+			ToAddress:            testutil.ValidAddress,
+			TokenStandard:        types.ZnnTokenStandard,
+			Amount:               big.NewInt(1),
+			MomentumAcknowledged: types.HashHeight{Height: momentum},
+			PublicKey:            publicKey,
+		}
+		block.Hash = block.ComputeHash()
+		block.Signature, err = keypair.Sign(block.Hash.Bytes())
+		require.NoError(t, err)
+		return block
+	}
+
+	v := NewVerifier(*address)
+	first := sign(1, 10, types.ZeroHash)
+	require.NoError(t, v.Check(first))
+	second := sign(2, 10, first.Hash)
+	require.NoError(t, v.Check(second), "the same momentum may be acknowledged twice")
+	assert.ErrorContains(t, v.Check(sign(3, 9, second.Hash)), "block 3: acknowledges momentum 9, older than momentum 10")
+}
+
+func TestVerifyContractBlocks(t *testing.T) {
+	block := &nom.AccountBlock{
+		Version:         1,
+		ChainIdentifier: 1,
+		BlockType:       nom.BlockTypeContractSend,
+		Height:          1,
+		Address:         types.PlasmaContract,
+		Amount:          big.NewInt(0),
+	}
+	block.Hash = block.ComputeHash()
+
+	v := NewVerifier(types.PlasmaContract)
+	require.NoError(t, v.Check(block))
+	assert.Equal(t, 1, v.Unsigned())
+
+	block.Signature = []byte{1}
+	assert.ErrorContains(t, NewVerifier(types.PlasmaContract).Check(block), "unsigned contract block has a public key or signature")
+}
@@ -0,0 +1,113 @@
+package autopilot
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Audit actions
+const (
+	ActionRevoke  = "revoke"
+	ActionCollect = "collect"
+	ActionReceive = "receive"
+	ActionStake   = "stake"
+)
+
+// AuditEntry records one action taken by the autopilot
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Address string    `json:"address"`
+	Action  string    `json:"action"`
+	// Amount is in base units; Symbol names its token
+	Amount string `json:"amount,omitempty"`
+	Symbol string `json:"symbol,omitempty"`
+	// QsrAmount is the QSR part of collected rewards, in base units
+	QsrAmount string `json:"qsrAmount,omitempty"`
+	// StakeID is the revoked stake entry
+	StakeID string `json:"stakeId,omitempty"`
+	// Months is the duration of a new stake
+	Months int64  `json:"months,omitempty"`
+	Hash   string `json:"hash,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AuditLog appends entries to a JSON lines file
+type AuditLog struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// AuditPath returns the default audit log (~/.znn/stake-autopilot.log)
+func AuditPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".znn", "stake-autopilot.log"), nil
+}
+
+// Append writes an entry to the end of the log
+func (l *AuditLog) Append(entry *AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0750); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	// #nosec G304 - Path is provided by the user or the default config directory
+	file, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// ReadAudit reads every entry of an audit log, returning none if it does not exist
+func ReadAudit(path string) ([]*AuditEntry, error) {
+	// #nosec G304 - Path is provided by the user or the default config directory
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var entries []*AuditEntry
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log line %d: %w", line, err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,258 @@
+// Package autopilot maintains the stakes of an address: it collects and
+// receives stake rewards, revokes expired entries, optionally restakes them,
+// and stakes the ZNN balance above a threshold. Every action is published
+// through transaction.BuildAndSend and recorded in an audit log.
+package autopilot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/0x3639/znn_cli_go/pkg/rewards"
+	"github.com/0x3639/znn_cli_go/pkg/service"
+	"github.com/0x3639/znn_cli_go/pkg/stake"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+)
+
+// Options configures what the autopilot does
+type Options struct {
+	// Threshold is the ZNN balance kept liquid; the balance above it is
+	// staked for Months. Nil disables staking the balance.
+	Threshold *big.Int
+	// Months is the duration of new stakes
+	Months int64
+	// RestakeExpired restakes revoked entries for their original duration
+	RestakeExpired bool
+	// Wait bounds how long to wait for collected rewards and revoked ZNN to arrive
+	Wait time.Duration
+}
+
+// Validate checks that the options are usable
+func (o Options) Validate() error {
+	if o.Months < service.MinStakeMonths || o.Months > service.MaxStakeMonths {
+		return fmt.Errorf("invalid duration: must be between %d and %d months", service.MinStakeMonths, service.MaxStakeMonths)
+	}
+	if o.Threshold != nil && o.Threshold.Sign() < 0 {
+		return fmt.Errorf("threshold must not be negative")
+	}
+	if o.Wait <= 0 {
+		return fmt.Errorf("wait must be positive")
+	}
+	return nil
+}
+
+// Pilot runs the autopilot for one address
+type Pilot struct {
+	Client  *rpc_client.RpcClient
+	Address types.Address
+	KeyPair *wallet.KeyPair
+	Options Options
+	Audit   *AuditLog
+	// Now returns the time recorded in the audit log; defaults to time.Now
+	Now func() time.Time
+}
+
+// Run performs one pass and returns the actions taken. Actions recorded
+// before an error are returned with it.
+func (p *Pilot) Run(ctx context.Context) ([]*AuditEntry, error) {
+	run := &pass{ctx: ctx, pilot: p}
+	err := run.execute()
+	return run.actions, err
+}
+
+// StakeMonths returns the duration of a stake entry in whole months, at least one
+func StakeMonths(entry *embedded.StakeEntry) int64 {
+	months := (entry.ExpirationTimestamp - entry.StartTimestamp) / service.StakeTimeUnit
+	if months < service.MinStakeMonths {
+		return service.MinStakeMonths
+	}
+	if months > service.MaxStakeMonths {
+		return service.MaxStakeMonths
+	}
+	return months
+}
+
+// RestakeAmount returns the part of balance above threshold, or nil when it
+// is below the minimum stake
+func RestakeAmount(balance, threshold *big.Int) *big.Int {
+	if balance == nil || threshold == nil {
+		return nil
+	}
+	amount := new(big.Int).Sub(balance, threshold)
+	if amount.Cmp(big.NewInt(service.MinStakeAmount)) < 0 {
+		return nil
+	}
+	return amount
+}
+
+// pass holds the state of a single run
+type pass struct {
+	ctx     context.Context
+	pilot   *Pilot
+	actions []*AuditEntry
+}
+
+func (r *pass) now() time.Time {
+	if r.pilot.Now != nil {
+		return r.pilot.Now()
+	}
+	return time.Now()
+}
+
+func (r *pass) execute() error {
+	p := r.pilot
+	c := p.Client
+
+	momentum, err := c.LedgerApi.GetFrontierMomentum()
+	if err != nil {
+		return fmt.Errorf("failed to get frontier momentum: %w", err)
+	}
+	entries, err := stake.Entries(c, p.Address)
+	if err != nil {
+		return err
+	}
+	expired := stake.ExpiredEntries(entries, int64(momentum.TimestampUnix))
+
+	// Incoming amounts are measured against what is already waiting
+	pendingZnn, pendingQsr, err := rewards.Pending(c, p.Address)
+	if err != nil {
+		return err
+	}
+	expectZnn := new(big.Int)
+	expectQsr := new(big.Int)
+
+	var revoked []*embedded.StakeEntry
+	for _, entry := range expired {
+		action := &AuditEntry{Action: ActionRevoke, StakeID: entry.Id.String(), Amount: entry.Amount.String(), Symbol: "ZNN"}
+		if err := r.send(action, c.StakeApi.Cancel(entry.Id)); err != nil {
+			return fmt.Errorf("failed to revoke stake %s: %w", entry.Id, err)
+		}
+		expectZnn.Add(expectZnn, entry.Amount)
+		revoked = append(revoked, entry)
+	}
+
+	reward, err := rewards.Stake.Uncollected(c, p.Address)
+	if err != nil {
+		return err
+	}
+	if !reward.IsZero() {
+		action := &AuditEntry{Action: ActionCollect, Amount: reward.Znn.String(), Symbol: "ZNN", QsrAmount: reward.Qsr.String()}
+		if err := r.send(action, rewards.Stake.CollectTemplate(c)); err != nil {
+			return fmt.Errorf("failed to collect stake rewards: %w", err)
+		}
+		expectZnn.Add(expectZnn, reward.Znn)
+		expectQsr.Add(expectQsr, reward.Qsr)
+	}
+
+	if expectZnn.Sign() > 0 || expectQsr.Sign() > 0 {
+		targetZnn := new(big.Int).Add(pendingZnn, expectZnn)
+		targetQsr := new(big.Int).Add(pendingQsr, expectQsr)
+		err := transaction.WaitUntil(r.ctx, p.Options.Wait, transaction.DefaultWaitInterval, func() (bool, error) {
+			znn, qsr, err := rewards.Pending(c, p.Address)
+			if err != nil {
+				return false, err
+			}
+			return znn.Cmp(targetZnn) >= 0 && qsr.Cmp(targetQsr) >= 0, nil
+		})
+		if err != nil {
+			return fmt.Errorf("revoked stakes and rewards have not arrived yet: %w", err)
+		}
+	}
+
+	if err := r.receive(); err != nil {
+		return err
+	}
+
+	balance, err := znnBalance(c, p.Address)
+	if err != nil {
+		return err
+	}
+
+	if p.Options.RestakeExpired {
+		for _, entry := range revoked {
+			if balance.Cmp(entry.Amount) < 0 {
+				return fmt.Errorf("cannot restake stake %s: balance is lower than its amount", entry.Id)
+			}
+			if err := r.stake(entry.Amount, StakeMonths(entry)); err != nil {
+				return err
+			}
+			balance.Sub(balance, entry.Amount)
+		}
+	}
+
+	if amount := RestakeAmount(balance, p.Options.Threshold); amount != nil {
+		if err := r.stake(amount, p.Options.Months); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stake stakes amount for months
+func (r *pass) stake(amount *big.Int, months int64) error {
+	p := r.pilot
+	action := &AuditEntry{Action: ActionStake, Amount: amount.String(), Symbol: "ZNN", Months: months}
+	if err := r.send(action, p.Client.StakeApi.Stake(months*service.StakeTimeUnit, amount)); err != nil {
+		return fmt.Errorf("failed to stake: %w", err)
+	}
+	return nil
+}
+
+// receive receives every unreceived block and records each one
+func (r *pass) receive() error {
+	p := r.pilot
+	_, err := transaction.ReceiveAll(r.ctx, p.Client, p.Address, p.KeyPair, func(block *nom.AccountBlock) {
+		r.record(&AuditEntry{Action: ActionReceive, Hash: block.Hash.String()})
+	})
+	if err != nil {
+		r.record(&AuditEntry{Action: ActionReceive, Error: err.Error()})
+	}
+	return err
+}
+
+// send publishes a block and records the action with its outcome
+func (r *pass) send(action *AuditEntry, template *nom.AccountBlock) error {
+	p := r.pilot
+	err := transaction.BuildAndSend(r.ctx, p.Client, p.Address, template, p.KeyPair)
+	if err != nil {
+		action.Error = err.Error()
+	} else {
+		action.Hash = template.Hash.String()
+	}
+	r.record(action)
+	return err
+}
+
+// record stamps an action and appends it to the audit log and the pass
+func (r *pass) record(action *AuditEntry) {
+	action.Time = r.now().UTC()
+	action.Address = r.pilot.Address.String()
+	r.actions = append(r.actions, action)
+	if r.pilot.Audit != nil {
+		if err := r.pilot.Audit.Append(action); err != nil && action.Error == "" {
+			action.Error = err.Error()
+		}
+	}
+}
+
+// znnBalance returns the ZNN balance of an address
+func znnBalance(c *rpc_client.RpcClient, address types.Address) (*big.Int, error) {
+	info, err := c.LedgerApi.GetAccountInfoByAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account info: %w", err)
+	}
+	balance := new(big.Int)
+	if balanceInfo, found := info.BalanceInfoMap[types.ZnnTokenStandard]; found && balanceInfo.Balance != nil {
+		balance.Set(balanceInfo.Balance)
+	}
+	return balance, nil
+}
@@ -0,0 +1,75 @@
+package autopilot
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+)
+
+func znn(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), big.NewInt(1e8))
+}
+
+func TestStakeMonths(t *testing.T) {
+	entry := func(months int64) *embedded.StakeEntry {
+		return &embedded.StakeEntry{StartTimestamp: 100, ExpirationTimestamp: 100 + months*service.StakeTimeUnit}
+	}
+
+	assert.Equal(t, int64(3), StakeMonths(entry(3)))
+	assert.Equal(t, int64(12), StakeMonths(entry(12)))
+	assert.Equal(t, int64(1), StakeMonths(entry(0)))
+	assert.Equal(t, int64(12), StakeMonths(entry(24)))
+}
+
+func TestRestakeAmount(t *testing.T) {
+	assert.Equal(t, znn(400), RestakeAmount(znn(500), znn(100)))
+	assert.Equal(t, znn(1), RestakeAmount(znn(101), znn(100)))
+	assert.Nil(t, RestakeAmount(znn(100), znn(100)))
+	assert.Nil(t, RestakeAmount(znn(50), znn(100)))
+	assert.Nil(t, RestakeAmount(znn(500), nil))
+}
+
+func TestOptionsValidate(t *testing.T) {
+	valid := Options{Months: 3, Wait: time.Minute, Threshold: znn(10)}
+	assert.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.Months = 13
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.Threshold = big.NewInt(-1)
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.Wait = 0
+	assert.Error(t, invalid.Validate())
+}
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "autopilot.log")
+
+	entries, err := ReadAudit(path)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	log := &AuditLog{Path: path}
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, log.Append(&AuditEntry{Time: now, Action: ActionCollect, Amount: "100", Symbol: "ZNN", QsrAmount: "5"}))
+	require.NoError(t, log.Append(&AuditEntry{Time: now, Action: ActionStake, Amount: "200", Symbol: "ZNN", Months: 3, Error: "boom"}))
+
+	entries, err = ReadAudit(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, ActionCollect, entries[0].Action)
+	assert.Equal(t, "5", entries[0].QsrAmount)
+	assert.True(t, entries[0].Time.Equal(now))
+	assert.Equal(t, int64(3), entries[1].Months)
+	assert.Equal(t, "boom", entries[1].Error)
+}
@@ -3,25 +3,46 @@
 package client
 
 import (
-	"fmt"
+	"context"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/0x3639/znn-sdk-go/rpc_client"
+	"github.com/zenon-network/go-zenon/rpc/server"
+
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 )
 
 // Client wraps the SDK RpcClient with CLI-specific functionality
 type Client struct {
 	*rpc_client.RpcClient
-	url string
+	url   string
+	ctx   context.Context
+	relay *relay
+
+	rawMu sync.Mutex
+	raw   *server.Client
+}
+
+// timeoutKey is the context key of the request timeout
+type timeoutKey struct{}
+
+// WithRequestTimeout returns a context whose clients give up on node
+// requests that are not answered within timeout. Zero waits forever.
+func WithRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, timeout)
+}
+
+// requestTimeout returns the request timeout carried by a context
+func requestTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(timeoutKey{}).(time.Duration)
+	return timeout
 }
 
 // New creates a new RPC client with the specified URL and default options.
 // The client will automatically reconnect on connection loss.
-func New(url string) (*Client, error) {
-	if url == "" {
-		url = "ws://127.0.0.1:35998"
-	}
-
+func New(ctx context.Context, url string) (*Client, error) {
 	opts := rpc_client.DefaultClientOptions()
 	opts.AutoReconnect = true
 	opts.ReconnectDelay = 2 * time.Second
@@ -29,32 +50,44 @@ func New(url string) (*Client, error) {
 	opts.ReconnectAttempts = 10
 	opts.HealthCheckInterval = 15 * time.Second
 
-	client, err := rpc_client.NewRpcClientWithOptions(url, opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to node at %s: %w", url, err)
-	}
-
-	return &Client{
-		RpcClient: client,
-		url:       url,
-	}, nil
+	return NewWithOptions(ctx, url, opts)
 }
 
-// NewWithOptions creates a new RPC client with custom options
-func NewWithOptions(url string, opts rpc_client.ClientOptions) (*Client, error) {
+// NewWithOptions creates a new RPC client with custom options. Once ctx is
+// done, pending and new requests fail at once; requests over WebSocket also
+// fail when the node does not answer within the request timeout of ctx, and
+// are recorded or replayed as set with WithRecorder and WithReplay.
+func NewWithOptions(ctx context.Context, url string, opts rpc_client.ClientOptions) (*Client, error) {
 	if url == "" {
 		url = "ws://127.0.0.1:35998"
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, errs.Errorf(errs.KindOf(err), "failed to connect to node at %s: %w", url, err)
+	}
 
-	client, err := rpc_client.NewRpcClientWithOptions(url, opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to node at %s: %w", url, err)
+	c := &Client{url: url, ctx: ctx}
+	dialURL := url
+	if strings.HasPrefix(url, "ws://") || strings.HasPrefix(url, "wss://") || replayOf(ctx) != nil {
+		r, err := startRelay(ctx, url, requestTimeout(ctx))
+		if err != nil {
+			return nil, errs.Errorf(errs.Network, "failed to connect to node at %s: %w", url, err)
+		}
+		c.relay = r
+		dialURL = r.URL()
 	}
 
-	return &Client{
-		RpcClient: client,
-		url:       url,
-	}, nil
+	client, err := rpc_client.NewRpcClientWithOptions(dialURL, opts)
+	if err != nil {
+		if c.relay != nil {
+			if relayErr := c.relay.err(); relayErr != nil {
+				err = relayErr
+			}
+			c.relay.Close()
+		}
+		return nil, errs.Errorf(errs.KindOf(errs.FromRPC(err)), "failed to connect to node at %s: %w", url, err)
+	}
+	c.RpcClient = client
+	return c, nil
 }
 
 // URL returns the WebSocket URL this client is connected to
@@ -62,8 +95,44 @@ func (c *Client) URL() string {
 	return c.url
 }
 
+// Call invokes an RPC method and decodes its result into result, which must
+// be a pointer. It uses a separate connection, opened on first use, for
+// endpoints whose SDK wrappers do not decode their result.
+func (c *Client) Call(result any, method string, args ...any) error {
+	if err := c.ctx.Err(); err != nil {
+		return errs.FromRPC(err)
+	}
+	c.rawMu.Lock()
+	if c.raw == nil {
+		dialURL := c.url
+		if c.relay != nil {
+			dialURL = c.relay.URL()
+		}
+		raw, err := server.DialContext(c.ctx, dialURL)
+		if err != nil {
+			c.rawMu.Unlock()
+			return errs.Errorf(errs.KindOf(errs.FromRPC(err)), "failed to connect to node at %s: %w", c.url, err)
+		}
+		c.raw = raw
+	}
+	raw := c.raw
+	c.rawMu.Unlock()
+
+	return errs.FromRPC(raw.CallContext(c.ctx, result, method, args...))
+}
+
 // Close stops the client and closes the connection
 func (c *Client) Close() error {
+	c.rawMu.Lock()
+	if c.raw != nil {
+		c.raw.Close()
+		c.raw = nil
+	}
+	c.rawMu.Unlock()
+
 	c.Stop()
+	if c.relay != nil {
+		c.relay.Close()
+	}
 	return nil
 }
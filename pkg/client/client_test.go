@@ -0,0 +1,194 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/logging"
+	"github.com/0x3639/znn_cli_go/pkg/testutil"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	node := testutil.NewNode(t)
+	c, err := New(WithRequestTimeout(t.Context(), 50*time.Millisecond), node.URL)
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	release := node.Stall("ledger.getFrontierMomentum")
+	defer release()
+
+	start := time.Now()
+	_, err = c.LedgerApi.GetFrontierMomentum()
+	require.Error(t, err)
+	assert.Equal(t, errs.Timeout, errs.KindOf(errs.FromRPC(err)))
+	assert.Contains(t, err.Error(), "ledger.getFrontierMomentum")
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// Other requests are still answered, and so is the stalled one once released
+	_, err = c.LedgerApi.GetAccountInfoByAddress(testutil.ValidAddress)
+	assert.NoError(t, err)
+	release()
+	_, err = c.LedgerApi.GetFrontierMomentum()
+	assert.NoError(t, err)
+}
+
+func TestInterrupt(t *testing.T) {
+	node := testutil.NewNode(t)
+	ctx, cancel := context.WithCancel(t.Context())
+	c, err := New(ctx, node.URL)
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	release := node.Stall("ledger.getFrontierMomentum")
+	defer release()
+
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = c.LedgerApi.GetFrontierMomentum()
+	require.Error(t, err)
+	assert.Equal(t, errs.Interrupted, errs.KindOf(errs.FromRPC(err)))
+
+	// Requests made after the interruption fail at once
+	_, err = c.LedgerApi.GetAccountInfoByAddress(testutil.ValidAddress)
+	assert.Equal(t, errs.Interrupted, errs.KindOf(errs.FromRPC(err)))
+
+	var result any
+	err = c.Call(&result, "embedded.pillar.getDepositedQsr", testutil.ValidAddress)
+	assert.Equal(t, errs.Interrupted, errs.KindOf(err))
+
+	_, err = New(ctx, node.URL)
+	assert.Equal(t, errs.Interrupted, errs.KindOf(err))
+}
+
+func TestConnectError(t *testing.T) {
+	node := testutil.NewNode(t)
+	url := node.URL
+	node.Close()
+
+	_, err := New(t.Context(), url)
+	require.Error(t, err)
+	assert.Equal(t, errs.Network, errs.KindOf(err))
+	assert.Contains(t, err.Error(), url)
+}
+
+// syncBuffer is a buffer the relay goroutines can log to
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTraceRPC(t *testing.T) {
+	var out syncBuffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: logging.LevelTrace})))
+	defer slog.SetDefault(previous)
+
+	node := testutil.NewNode(t)
+	c, err := New(t.Context(), node.URL)
+	require.NoError(t, err)
+	_, err = c.LedgerApi.GetFrontierMomentum()
+	require.NoError(t, err)
+	require.NoError(t, c.Close())
+
+	logged := out.String()
+	assert.Contains(t, logged, `msg="rpc request" method=ledger.getFrontierMomentum`)
+	assert.Contains(t, logged, `msg="rpc response" method=ledger.getFrontierMomentum`)
+	assert.Contains(t, logged, "duration=")
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	recorder, err := NewRecorder(path)
+	require.NoError(t, err)
+
+	node := testutil.NewNode(t)
+	c, err := New(WithRecorder(t.Context(), recorder), node.URL)
+	require.NoError(t, err)
+	momentum, err := c.LedgerApi.GetFrontierMomentum()
+	require.NoError(t, err)
+	info, err := c.LedgerApi.GetAccountInfoByAddress(testutil.ValidAddress)
+	require.NoError(t, err)
+	var deposited any
+	require.NoError(t, c.Call(&deposited, "embedded.pillar.getDepositedQsr", testutil.ValidAddress))
+	require.NoError(t, c.Close())
+	require.NoError(t, recorder.Close())
+	node.Close()
+
+	replay, err := LoadReplay(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, replay.Len())
+
+	c, err = New(WithReplay(t.Context(), replay), "ws://127.0.0.1:1")
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	replayed, err := c.LedgerApi.GetAccountInfoByAddress(testutil.ValidAddress)
+	require.NoError(t, err)
+	assert.Equal(t, info, replayed)
+	replayedMomentum, err := c.LedgerApi.GetFrontierMomentum()
+	require.NoError(t, err)
+	assert.Equal(t, momentum.Hash, replayedMomentum.Hash)
+
+	// Repeated requests get the last recorded response
+	replayedMomentum, err = c.LedgerApi.GetFrontierMomentum()
+	require.NoError(t, err)
+	assert.Equal(t, momentum.Height, replayedMomentum.Height)
+
+	var replayedDeposit any
+	require.NoError(t, c.Call(&replayedDeposit, "embedded.pillar.getDepositedQsr", testutil.ValidAddress))
+	assert.Equal(t, deposited, replayedDeposit)
+
+	_, err = c.LedgerApi.GetUnconfirmedBlocksByAddress(testutil.ValidAddress, 0, 10)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the recording has no response to ledger.getUnconfirmedBlocksByAddress")
+}
+
+func TestReplayMatching(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	lines := `{"method":"ledger.getAccountInfoByAddress","params":["z1a"],"result":{"n":1}}
+{"method":"ledger.getAccountInfoByAddress","params":["z1b"],"result":{"n":2}}
+{"method":"ledger.publishRawTransaction","params":[{"signature":"c2ln","hash":"h"}],"result":null}
+`
+	require.NoError(t, os.WriteFile(path, []byte(lines), 0600))
+	replay, err := LoadReplay(path)
+	require.NoError(t, err)
+
+	answer := func(method, params string) string {
+		exchange, found := replay.answer(method, json.RawMessage(params))
+		require.True(t, found)
+		return string(exchange.Result)
+	}
+	assert.Equal(t, `{"n":2}`, answer("ledger.getAccountInfoByAddress", `["z1b"]`), "same parameters first")
+	assert.Equal(t, `{"n":1}`, answer("ledger.getAccountInfoByAddress", `["z1c"]`), "then the same method")
+	assert.Equal(t, `{"n":2}`, answer("ledger.getAccountInfoByAddress", `["z1b"]`), "then the last match")
+	assert.Equal(t, "null", answer("ledger.publishRawTransaction", `[{"hash":"h","signature":"b3RoZXI="}]`), "signatures are ignored")
+
+	_, found := replay.answer("ledger.getFrontierMomentum", nil)
+	assert.False(t, found)
+
+	require.NoError(t, os.WriteFile(path, []byte("{\"result\":1}\n"), 0600))
+	_, err = LoadReplay(path)
+	assert.ErrorContains(t, err, "line 1")
+}
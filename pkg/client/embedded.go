@@ -0,0 +1,36 @@
+package client
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// PillarDepositedQsr returns the QSR an address has deposited in the pillar contract
+func (c *Client) PillarDepositedQsr(address types.Address) (*big.Int, error) {
+	return c.callAmount("embedded.pillar.getDepositedQsr", address.String())
+}
+
+// PillarQsrRegistrationCost returns the QSR currently burned to register a pillar
+func (c *Client) PillarQsrRegistrationCost() (*big.Int, error) {
+	return c.callAmount("embedded.pillar.getQsrRegistrationCost")
+}
+
+// SentinelDepositedQsr returns the QSR an address has deposited in the sentinel contract
+func (c *Client) SentinelDepositedQsr(address types.Address) (*big.Int, error) {
+	return c.callAmount("embedded.sentinel.getDepositedQsr", address.String())
+}
+
+// callAmount calls a method returning an amount encoded as a decimal string
+func (c *Client) callAmount(method string, args ...any) (*big.Int, error) {
+	var result string
+	if err := c.Call(&result, method, args...); err != nil {
+		return nil, err
+	}
+	amount, ok := new(big.Int).SetString(result, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q returned by %s", result, method)
+	}
+	return amount, nil
+}
@@ -0,0 +1,174 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/logging"
+)
+
+// Exchange is a recorded JSON-RPC request and the response of the node.
+// Signatures in the parameters are redacted.
+type Exchange struct {
+	Time     time.Time       `json:"time"`
+	Method   string          `json:"method"`
+	Params   json.RawMessage `json:"params,omitempty"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    json.RawMessage `json:"error,omitempty"`
+	Duration time.Duration   `json:"duration"`
+}
+
+// Recorder appends every exchange of the clients using it to a JSONL file
+type Recorder struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewRecorder creates or truncates the recording file at path
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+	return &Recorder{file: file}, nil
+}
+
+// record writes one exchange
+func (r *Recorder) record(exchange Exchange) error {
+	data, err := json.Marshal(exchange)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the recording file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// Replay answers requests with the responses of a recording. A request gets
+// the first unused exchange with the same method and parameters, else the
+// first unused one with the same method. Once those are used up the last
+// matching exchange is repeated, so that polling loops end.
+type Replay struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+	used      []bool
+}
+
+// LoadReplay reads a recording made with NewRecorder
+func LoadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	r := &Replay{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var exchange Exchange
+		if err := json.Unmarshal(scanner.Bytes(), &exchange); err != nil {
+			return nil, fmt.Errorf("failed to parse recording %s line %d: %w", path, line, err)
+		}
+		if exchange.Method == "" {
+			return nil, fmt.Errorf("failed to parse recording %s line %d: no method", path, line)
+		}
+		exchange.Params = canonicalParams(exchange.Params)
+		r.exchanges = append(r.exchanges, exchange)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	r.used = make([]bool, len(r.exchanges))
+	return r, nil
+}
+
+// Len returns the number of recorded exchanges
+func (r *Replay) Len() int {
+	return len(r.exchanges)
+}
+
+// answer returns the recorded exchange for a request
+func (r *Replay) answer(method string, params json.RawMessage) (Exchange, bool) {
+	params = canonicalParams(params)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	exact, sameMethod := -1, -1
+	lastExact, lastMethod := -1, -1
+	for i, exchange := range r.exchanges {
+		if exchange.Method != method {
+			continue
+		}
+		matches := bytes.Equal(exchange.Params, params)
+		if r.used[i] {
+			lastMethod = i
+			if matches {
+				lastExact = i
+			}
+			continue
+		}
+		if matches && exact < 0 {
+			exact = i
+		}
+		if sameMethod < 0 {
+			sameMethod = i
+		}
+	}
+	for _, i := range []int{exact, sameMethod, lastExact, lastMethod} {
+		if i >= 0 {
+			r.used[i] = true
+			return r.exchanges[i], true
+		}
+	}
+	return Exchange{}, false
+}
+
+// canonicalParams redacts signatures and sorts object keys, so that the
+// parameters of a request compare equal to their recording
+func canonicalParams(params json.RawMessage) json.RawMessage {
+	if len(bytes.TrimSpace(params)) == 0 || bytes.Equal(bytes.TrimSpace(params), []byte("null")) {
+		return nil
+	}
+	return json.RawMessage(logging.Redact(params))
+}
+
+// recorderKey and replayKey are the context keys of a Recorder and a Replay
+type recorderKey struct{}
+type replayKey struct{}
+
+// WithRecorder returns a context whose clients record their exchanges
+func WithRecorder(ctx context.Context, recorder *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, recorder)
+}
+
+// WithReplay returns a context whose clients are answered from a recording
+// instead of a node
+func WithReplay(ctx context.Context, replay *Replay) context.Context {
+	return context.WithValue(ctx, replayKey{}, replay)
+}
+
+func recorderOf(ctx context.Context) *Recorder {
+	recorder, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return recorder
+}
+
+func replayOf(ctx context.Context) *Replay {
+	replay, _ := ctx.Value(replayKey{}).(*Replay)
+	return replay
+}
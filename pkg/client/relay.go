@@ -0,0 +1,484 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/logging"
+)
+
+// relay forwards the JSON-RPC messages of the SDK to the node through a
+// local websocket, since the SDK calls cannot be given a context. It answers
+// requests the node does not answer within the request timeout, and every
+// request once its context is done, so that no call blocks forever. Every
+// message is logged at trace level, and exchanges are recorded to recorder.
+// With a replay the relay answers from the recording and never dials the node.
+type relay struct {
+	ctx      context.Context
+	target   string
+	timeout  time.Duration
+	recorder *Recorder
+	replay   *Replay
+	listener net.Listener
+	server   *http.Server
+	path     string
+	upgrader websocket.Upgrader
+
+	mu       sync.Mutex
+	sessions map[*session]struct{}
+	dialErr  error
+}
+
+// session is one SDK connection and its connection to the node
+type session struct {
+	relay *relay
+	down  *websocket.Conn
+	up    *websocket.Conn
+	stop  func() bool
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	pending map[string]*pendingCall
+}
+
+// pendingCall is a request waiting for the node to answer
+type pendingCall struct {
+	method string
+	params json.RawMessage
+	start  time.Time
+	timer  *time.Timer
+}
+
+// message is the part of a JSON-RPC message the relay looks at
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+// response is a JSON-RPC response replayed from a recording
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// errorReply is a JSON-RPC error response
+type errorReply struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   replyError      `json:"error"`
+}
+
+type replyError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// startRelay starts a relay to the node at target on a loopback port
+func startRelay(ctx context.Context, target string, timeout time.Duration) (*relay, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate relay path: %w", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start relay: %w", err)
+	}
+
+	r := &relay{
+		ctx:      ctx,
+		target:   target,
+		timeout:  timeout,
+		recorder: recorderOf(ctx),
+		replay:   replayOf(ctx),
+		listener: listener,
+		path:     "/" + hex.EncodeToString(token),
+		sessions: make(map[*session]struct{}),
+	}
+	r.server = &http.Server{Handler: r, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = r.server.Serve(listener) }()
+	return r, nil
+}
+
+// URL returns the address the SDK connects to
+func (r *relay) URL() string {
+	return "ws://" + r.listener.Addr().String() + r.path
+}
+
+// err returns the error of the last failed connection to the node
+func (r *relay) err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dialErr
+}
+
+// Close stops the relay and closes every connection
+func (r *relay) Close() {
+	_ = r.server.Close()
+	r.mu.Lock()
+	sessions := r.sessions
+	r.sessions = make(map[*session]struct{})
+	r.mu.Unlock()
+	for s := range sessions {
+		s.close()
+	}
+}
+
+// ServeHTTP connects an SDK connection to the node
+func (r *relay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != r.path {
+		http.NotFound(w, req)
+		return
+	}
+
+	var up *websocket.Conn
+	if r.replay == nil {
+		var err error
+		up, err = r.dial()
+		r.mu.Lock()
+		r.dialErr = err
+		r.mu.Unlock()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+	down, err := r.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		if up != nil {
+			_ = up.Close()
+		}
+		return
+	}
+
+	s := &session{relay: r, down: down, up: up, pending: make(map[string]*pendingCall)}
+	s.stop = context.AfterFunc(r.ctx, s.interrupt)
+	r.mu.Lock()
+	r.sessions[s] = struct{}{}
+	r.mu.Unlock()
+
+	if up != nil {
+		go s.fromNode()
+	}
+	s.toNode()
+}
+
+// dial connects to the node within the request timeout, passing URL
+// credentials as basic auth like the SDK does
+func (r *relay) dial() (*websocket.Conn, error) {
+	endpoint, err := url.Parse(r.target)
+	if err != nil {
+		return nil, err
+	}
+	header := make(http.Header)
+	if endpoint.User != nil {
+		header.Add("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(endpoint.User.String())))
+		endpoint.User = nil
+	}
+	ctx := r.ctx
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	// The handshake does not watch ctx, so its connection is closed when ctx
+	// is done
+	var stopWatch func() bool
+	dialer := *websocket.DefaultDialer
+	dialer.NetDialContext = func(dialCtx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := (&net.Dialer{}).DialContext(dialCtx, network, addr)
+		if err == nil {
+			stopWatch = context.AfterFunc(ctx, func() { _ = conn.Close() })
+		}
+		return conn, err
+	}
+	conn, _, err := dialer.DialContext(ctx, endpoint.String(), header)
+	if stopWatch != nil && !stopWatch() {
+		if conn != nil {
+			_ = conn.Close()
+		}
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		slog.Debug("failed to connect to node", "url", endpoint.Redacted(), "error", err)
+		return nil, err
+	}
+	slog.Debug("connected to node", "url", endpoint.Redacted())
+	return conn, nil
+}
+
+// trace logs a message at trace level with its signatures redacted
+func (r *relay) trace(msg string, data []byte, attrs ...any) {
+	if !slog.Default().Enabled(r.ctx, logging.LevelTrace) {
+		return
+	}
+	slog.Log(r.ctx, logging.LevelTrace, msg, append(attrs, "message", logging.Redact(data))...)
+}
+
+// toNode forwards requests to the node until the SDK disconnects
+func (s *session) toNode() {
+	defer s.close()
+	for {
+		kind, data, err := s.down.ReadMessage()
+		if err != nil {
+			return
+		}
+		calls := parseMessages(data)
+		if s.relay.replay != nil {
+			s.relay.trace("rpc request", data)
+			s.answerFromReplay(calls)
+			continue
+		}
+		if !s.track(calls) {
+			continue
+		}
+		if len(calls) == 1 {
+			s.relay.trace("rpc request", data, "method", calls[0].Method, "id", string(calls[0].ID))
+		} else {
+			s.relay.trace("rpc request", data)
+		}
+		if err := s.up.WriteMessage(kind, data); err != nil {
+			return
+		}
+	}
+}
+
+// fromNode forwards responses and notifications to the SDK until the node
+// disconnects. Responses to requests already answered are dropped.
+func (s *session) fromNode() {
+	defer s.close()
+	for {
+		kind, data, err := s.up.ReadMessage()
+		if err != nil {
+			return
+		}
+		messages := parseMessages(data)
+		forward := len(messages) == 0
+		for _, msg := range messages {
+			if msg.Method != "" || len(msg.ID) == 0 {
+				forward = true
+				s.relay.trace("rpc notification", data, "method", msg.Method)
+			} else if call := s.answered(msg.ID); call != nil {
+				forward = true
+				s.relay.trace("rpc response", data, "method", call.method, "id", string(msg.ID), "duration", time.Since(call.start))
+				s.record(call, msg)
+			} else {
+				s.relay.trace("rpc response dropped", data, "id", string(msg.ID))
+			}
+		}
+		if forward {
+			if err := s.write(kind, data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// track starts the timeout of each request. Once the context is done it
+// answers the requests instead and reports that they must not be forwarded.
+func (s *session) track(calls []message) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.relay.ctx.Err(); err != nil {
+		for _, call := range calls {
+			s.replyDone(call.ID, err)
+		}
+		return false
+	}
+	for _, call := range calls {
+		if call.Method == "" || len(call.ID) == 0 {
+			continue
+		}
+		pending := &pendingCall{method: call.Method, start: time.Now()}
+		if s.relay.recorder != nil {
+			pending.params = call.Params
+		}
+		if s.relay.timeout > 0 {
+			id := call.ID
+			pending.timer = time.AfterFunc(s.relay.timeout, func() { s.expire(id) })
+		}
+		s.pending[string(call.ID)] = pending
+	}
+	return true
+}
+
+// answered removes a request the node answered, returning nil when it was
+// no longer pending
+func (s *session) answered(id json.RawMessage) *pendingCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending, found := s.pending[string(id)]
+	if !found {
+		return nil
+	}
+	if pending.timer != nil {
+		pending.timer.Stop()
+	}
+	delete(s.pending, string(id))
+	return pending
+}
+
+// record writes a request the node answered to the recording
+func (s *session) record(call *pendingCall, msg message) {
+	if s.relay.recorder == nil {
+		return
+	}
+	exchange := Exchange{
+		Time:     call.start,
+		Method:   call.method,
+		Params:   canonicalParams(call.params),
+		Result:   msg.Result,
+		Error:    msg.Error,
+		Duration: time.Since(call.start),
+	}
+	if err := s.relay.recorder.record(exchange); err != nil {
+		slog.Warn("failed to write RPC recording", "method", call.method, "error", err)
+	}
+}
+
+// answerFromReplay answers requests with their recorded responses
+func (s *session) answerFromReplay(calls []message) {
+	if err := s.relay.ctx.Err(); err != nil {
+		for _, call := range calls {
+			s.replyDone(call.ID, err)
+		}
+		return
+	}
+	for _, call := range calls {
+		if call.Method == "" || len(call.ID) == 0 {
+			continue
+		}
+		exchange, found := s.relay.replay.answer(call.Method, call.Params)
+		if !found {
+			s.reply(call.ID, replayMissingCode, fmt.Sprintf("the recording has no response to %s", call.Method))
+			continue
+		}
+		reply := response{Version: "2.0", ID: call.ID, Result: exchange.Result, Error: exchange.Error}
+		if len(reply.Result) == 0 && len(reply.Error) == 0 {
+			reply.Result = json.RawMessage("null")
+		}
+		data, err := json.Marshal(reply)
+		if err != nil {
+			continue
+		}
+		s.relay.trace("rpc response", data, "method", call.Method, "id", string(call.ID), "from", "replay")
+		_ = s.write(websocket.TextMessage, data)
+	}
+}
+
+// expire answers a request the node did not answer in time
+func (s *session) expire(id json.RawMessage) {
+	s.mu.Lock()
+	pending, found := s.pending[string(id)]
+	delete(s.pending, string(id))
+	s.mu.Unlock()
+	if found {
+		slog.Debug("node request timed out", "method", pending.method, "timeout", s.relay.timeout)
+		s.reply(id, errs.RPCTimeoutCode, fmt.Sprintf("node did not answer %s within %s", pending.method, s.relay.timeout))
+	}
+}
+
+// interrupt answers every pending request once the context is done
+func (s *session) interrupt() {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[string]*pendingCall)
+	s.mu.Unlock()
+	for id, call := range pending {
+		if call.timer != nil {
+			call.timer.Stop()
+		}
+		s.replyDone(json.RawMessage(id), s.relay.ctx.Err())
+	}
+}
+
+// replayMissingCode is the error code of requests missing from a replay
+const replayMissingCode = -32000
+
+// replyDone answers a request with the reason the context is done
+func (s *session) replyDone(id json.RawMessage, err error) {
+	if len(id) == 0 {
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		s.reply(id, errs.RPCTimeoutCode, "deadline exceeded")
+		return
+	}
+	s.reply(id, errs.RPCInterruptedCode, "interrupted")
+}
+
+// reply sends an error response to the SDK
+func (s *session) reply(id json.RawMessage, code int, text string) {
+	data, err := json.Marshal(errorReply{Version: "2.0", ID: id, Error: replyError{Code: code, Message: text}})
+	if err != nil {
+		return
+	}
+	s.relay.trace("rpc response", data, "id", string(id), "from", "relay")
+	_ = s.write(websocket.TextMessage, data)
+}
+
+func (s *session) write(kind int, data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.down.WriteMessage(kind, data)
+}
+
+// close closes both connections and stops pending timeouts
+func (s *session) close() {
+	s.stop()
+	_ = s.down.Close()
+	if s.up != nil {
+		_ = s.up.Close()
+	}
+
+	s.mu.Lock()
+	for _, call := range s.pending {
+		if call.timer != nil {
+			call.timer.Stop()
+		}
+	}
+	s.pending = make(map[string]*pendingCall)
+	s.mu.Unlock()
+
+	s.relay.mu.Lock()
+	delete(s.relay.sessions, s)
+	s.relay.mu.Unlock()
+}
+
+// parseMessages decodes a single message or a batch. Messages that cannot
+// be decoded are left to the SDK and the node.
+func parseMessages(data []byte) []message {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var batch []message
+		if err := json.Unmarshal(trimmed, &batch); err != nil {
+			return nil
+		}
+		return batch
+	}
+	var msg message
+	if err := json.Unmarshal(trimmed, &msg); err != nil {
+		return nil
+	}
+	return []message{msg}
+}
@@ -7,36 +7,140 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+	"github.com/zenon-network/go-zenon/common/types"
+	"gopkg.in/yaml.v3"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Node    NodeConfig    `mapstructure:"node"`
-	Wallet  WalletConfig  `mapstructure:"wallet"`
-	Display DisplayConfig `mapstructure:"display"`
+	Node    NodeConfig    `mapstructure:"node" yaml:"node"`
+	Wallet  WalletConfig  `mapstructure:"wallet" yaml:"wallet"`
+	Display DisplayConfig `mapstructure:"display" yaml:"display"`
+
+	// Accounts lists known accounts that commands can reference as @label
+	Accounts []AccountConfig `mapstructure:"accounts" yaml:"accounts,omitempty"`
+
+	// Server contains settings for the local API server (serve command)
+	Server ServerConfig `mapstructure:"server" yaml:"server"`
+
+	// Watch contains settings for the event watcher (watch command)
+	Watch WatchConfig `mapstructure:"watch" yaml:"watch"`
+
+	// Exporter contains settings for the Prometheus exporter (exporter command)
+	Exporter ExporterConfig `mapstructure:"exporter" yaml:"exporter"`
+
+	// Journal contains settings for the local transaction journal
+	Journal JournalConfig `mapstructure:"journal" yaml:"journal"`
+
+	// Confirm contains settings for transaction confirmation prompts
+	Confirm ConfirmConfig `mapstructure:"confirm" yaml:"confirm"`
 }
 
 // NodeConfig contains Zenon node connection settings
 type NodeConfig struct {
-	URL           string        `mapstructure:"url"`
-	AutoReconnect bool          `mapstructure:"auto_reconnect"`
-	Timeout       time.Duration `mapstructure:"timeout"`
+	URL           string        `mapstructure:"url" yaml:"url"`
+	AutoReconnect bool          `mapstructure:"auto_reconnect" yaml:"auto_reconnect"`
+	Timeout       time.Duration `mapstructure:"timeout" yaml:"timeout"`
 }
 
 // WalletConfig contains wallet-related settings
 type WalletConfig struct {
-	DefaultKeyStore string `mapstructure:"default_keystore"`
-	DefaultIndex    int    `mapstructure:"default_index"`
-	WalletDir       string `mapstructure:"wallet_dir"`
+	DefaultKeyStore string `mapstructure:"default_keystore" yaml:"default_keystore"`
+	DefaultIndex    int    `mapstructure:"default_index" yaml:"default_index"`
+	WalletDir       string `mapstructure:"wallet_dir" yaml:"wallet_dir"`
+	// PolicyDir holds the spending policies of keyStores, one <keyStore>.yaml each
+	PolicyDir string `mapstructure:"policy_dir" yaml:"policy_dir"`
 }
 
 // DisplayConfig contains display and output settings
 type DisplayConfig struct {
-	Colors  bool `mapstructure:"colors"`
-	Verbose bool `mapstructure:"verbose"`
+	Colors  bool `mapstructure:"colors" yaml:"colors"`
+	Verbose bool `mapstructure:"verbose" yaml:"verbose"`
+	// LogLevel is the lowest level logged: trace, debug, info, warn or error
+	LogLevel string `mapstructure:"log_level" yaml:"log_level"`
+	// LogFormat is text or json
+	LogFormat string `mapstructure:"log_format" yaml:"log_format"`
+	// LogFile receives the log instead of stderr when set, rotated at
+	// LogMaxSize megabytes keeping LogMaxBackups old files
+	LogFile       string `mapstructure:"log_file" yaml:"log_file"`
+	LogMaxSize    int    `mapstructure:"log_max_size" yaml:"log_max_size"`
+	LogMaxBackups int    `mapstructure:"log_max_backups" yaml:"log_max_backups"`
+	// TraceRPC logs every JSON-RPC request and response
+	TraceRPC bool `mapstructure:"trace_rpc" yaml:"trace_rpc"`
+}
+
+// AccountConfig describes a known account saved in the configuration
+type AccountConfig struct {
+	Label    string `mapstructure:"label" yaml:"label"`
+	Address  string `mapstructure:"address" yaml:"address"`
+	KeyStore string `mapstructure:"keystore" yaml:"keystore"`
+	Index    int    `mapstructure:"index" yaml:"index"`
+}
+
+// ServerConfig contains local API server settings
+type ServerConfig struct {
+	Listen string           `mapstructure:"listen" yaml:"listen"`
+	Tokens []APITokenConfig `mapstructure:"tokens" yaml:"tokens,omitempty"`
+}
+
+// APITokenConfig is a bearer token accepted by the API server and the
+// endpoints it may call. Allow entries are endpoint names, "read" for all
+// read-only endpoints, or "*" for every endpoint.
+type APITokenConfig struct {
+	Name  string   `mapstructure:"name" yaml:"name"`
+	Token string   `mapstructure:"token" yaml:"token"`
+	Allow []string `mapstructure:"allow" yaml:"allow,omitempty"`
+}
+
+// WatchConfig contains event watcher settings. Addresses may be z1...
+// addresses or @labels of known accounts.
+type WatchConfig struct {
+	Addresses []string        `mapstructure:"addresses" yaml:"addresses,omitempty"`
+	Webhooks  []WebhookConfig `mapstructure:"webhooks" yaml:"webhooks,omitempty"`
+	Hooks     []string        `mapstructure:"hooks" yaml:"hooks,omitempty"`
+	Interval  time.Duration   `mapstructure:"interval" yaml:"interval"`
+	StateFile string          `mapstructure:"state_file" yaml:"state_file"`
+}
+
+// WebhookConfig is a webhook URL and the secret used to sign its payloads
+type WebhookConfig struct {
+	URL    string `mapstructure:"url" yaml:"url"`
+	Secret string `mapstructure:"secret" yaml:"secret"`
+}
+
+// ExporterConfig contains Prometheus exporter settings. Addresses may be
+// z1... addresses or @labels; an empty Pillars list exports every pillar.
+type ExporterConfig struct {
+	Listen    string        `mapstructure:"listen" yaml:"listen"`
+	Addresses []string      `mapstructure:"addresses" yaml:"addresses,omitempty"`
+	Pillars   []string      `mapstructure:"pillars" yaml:"pillars,omitempty"`
+	Interval  time.Duration `mapstructure:"interval" yaml:"interval"`
+}
+
+// JournalConfig contains transaction journal settings
+type JournalConfig struct {
+	Path     string `mapstructure:"path" yaml:"path"`
+	Disabled bool   `mapstructure:"disabled" yaml:"disabled"`
+}
+
+// ConfirmConfig contains transaction confirmation settings. With
+// MainnetGuard, --yes does not skip confirmation on mainnet and unattended
+// commands do not send on mainnet.
+type ConfirmConfig struct {
+	MainnetGuard bool `mapstructure:"mainnet_guard" yaml:"mainnet_guard"`
+}
+
+// DefaultConfigPath returns the default configuration file path (~/.znn/cli-config.yaml)
+func DefaultConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".znn", "cli-config.yaml"), nil
 }
 
 // DefaultConfig returns a Config with default values
@@ -52,10 +156,28 @@ func DefaultConfig() *Config {
 			DefaultKeyStore: "",
 			DefaultIndex:    0,
 			WalletDir:       filepath.Join(home, ".znn", "wallet"),
+			PolicyDir:       filepath.Join(home, ".znn", "policy"),
 		},
 		Display: DisplayConfig{
-			Colors:  true,
-			Verbose: false,
+			Colors:        true,
+			Verbose:       false,
+			LogLevel:      "warn",
+			LogFormat:     "text",
+			LogMaxSize:    10,
+			LogMaxBackups: 3,
+		},
+		Server: ServerConfig{
+			Listen: "127.0.0.1:35990",
+		},
+		Exporter: ExporterConfig{
+			Listen:   ":9100",
+			Interval: 30 * time.Second,
+		},
+		Journal: JournalConfig{
+			Path: filepath.Join(home, ".znn", "journal.log"),
+		},
+		Confirm: ConfirmConfig{
+			MainnetGuard: true,
 		},
 	}
 }
@@ -73,8 +195,19 @@ func Load(cfgFile string) (*Config, error) {
 	v.SetDefault("wallet.default_keystore", defaults.Wallet.DefaultKeyStore)
 	v.SetDefault("wallet.default_index", defaults.Wallet.DefaultIndex)
 	v.SetDefault("wallet.wallet_dir", defaults.Wallet.WalletDir)
+	v.SetDefault("wallet.policy_dir", defaults.Wallet.PolicyDir)
 	v.SetDefault("display.colors", defaults.Display.Colors)
 	v.SetDefault("display.verbose", defaults.Display.Verbose)
+	v.SetDefault("display.log_level", defaults.Display.LogLevel)
+	v.SetDefault("display.log_format", defaults.Display.LogFormat)
+	v.SetDefault("display.log_max_size", defaults.Display.LogMaxSize)
+	v.SetDefault("display.log_max_backups", defaults.Display.LogMaxBackups)
+	v.SetDefault("server.listen", defaults.Server.Listen)
+	v.SetDefault("exporter.listen", defaults.Exporter.Listen)
+	v.SetDefault("exporter.interval", defaults.Exporter.Interval)
+	v.SetDefault("journal.path", defaults.Journal.Path)
+	v.SetDefault("journal.disabled", defaults.Journal.Disabled)
+	v.SetDefault("confirm.mainnet_guard", defaults.Confirm.MainnetGuard)
 
 	if cfgFile != "" {
 		// Use config file from the flag
@@ -119,6 +252,25 @@ func (c *Config) Save(path string) error {
 	v.Set("node", c.Node)
 	v.Set("wallet", c.Wallet)
 	v.Set("display", c.Display)
+	if len(c.Accounts) > 0 {
+		v.Set("accounts", c.Accounts)
+	}
+	if c.Server.Listen != "" || len(c.Server.Tokens) > 0 {
+		v.Set("server", c.Server)
+	}
+	if len(c.Watch.Addresses) > 0 || len(c.Watch.Webhooks) > 0 || len(c.Watch.Hooks) > 0 ||
+		c.Watch.Interval != 0 || c.Watch.StateFile != "" {
+		v.Set("watch", c.Watch)
+	}
+	if c.Exporter.Listen != "" || len(c.Exporter.Addresses) > 0 || len(c.Exporter.Pillars) > 0 {
+		v.Set("exporter", c.Exporter)
+	}
+	if c.Journal.Path != "" || c.Journal.Disabled {
+		v.Set("journal", c.Journal)
+	}
+	if !c.Confirm.MainnetGuard {
+		v.Set("confirm", c.Confirm)
+	}
 
 	// Ensure directory exists
 	dir := filepath.Dir(path)
@@ -130,5 +282,144 @@ func (c *Config) Save(path string) error {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
+	// The file may contain API tokens and webhook secrets
+	if err := os.Chmod(path, 0600); err != nil {
+		return fmt.Errorf("failed to set config file permissions: %w", err)
+	}
+
 	return nil
 }
+
+// SaveKey sets a single, possibly dotted, key such as "accounts" or
+// "server.tokens" in the configuration file at path. The rest of the file is
+// kept as written, so settings that only exist in the file or as defaults are
+// not rewritten. The file is created if it does not exist.
+func SaveKey(path, key string, value any) error {
+	var doc yaml.Node
+	// #nosec G304 - Path is the user's config file
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	var encoded yaml.Node
+	if err := encoded.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+
+	node := doc.Content[0]
+	for _, part := range strings.Split(key, ".") {
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("failed to set %s: config file is not a mapping at %s", key, part)
+		}
+		child := mappingValue(node, part)
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, child)
+		}
+		node = child
+	}
+	*node = encoded
+
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	// The file may contain API tokens and webhook secrets
+	if err := os.WriteFile(path, []byte(out.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		return fmt.Errorf("failed to set config file permissions: %w", err)
+	}
+
+	return nil
+}
+
+// mappingValue returns the value of key in a YAML mapping node, matching
+// keys case-insensitively as viper does
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if strings.EqualFold(mapping.Content[i].Value, key) {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// FindAccount returns the known account with the given label.
+// A leading "@" on the label is ignored.
+func (c *Config) FindAccount(label string) (*AccountConfig, bool) {
+	label = strings.TrimPrefix(label, "@")
+	for i := range c.Accounts {
+		if c.Accounts[i].Label == label {
+			return &c.Accounts[i], true
+		}
+	}
+	return nil, false
+}
+
+// AddAccount adds a known account, replacing any existing account with the same label
+func (c *Config) AddAccount(account AccountConfig) {
+	for i := range c.Accounts {
+		if c.Accounts[i].Label == account.Label {
+			c.Accounts[i] = account
+			return
+		}
+	}
+	c.Accounts = append(c.Accounts, account)
+}
+
+// ResolveAddress parses an address argument that is either a z1... address
+// or an @label referring to a known account.
+func (c *Config) ResolveAddress(value string) (types.Address, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "@") {
+		return types.ParseAddress(value)
+	}
+
+	account, found := c.FindAccount(value)
+	if !found {
+		return types.ZeroAddress, fmt.Errorf("unknown account label %s", value)
+	}
+
+	address, err := types.ParseAddress(account.Address)
+	if err != nil {
+		return types.ZeroAddress, fmt.Errorf("invalid address for account %s: %w", value, err)
+	}
+	return address, nil
+}
+
+// AddAPIToken adds an API server token, replacing any existing token with the same name
+func (c *Config) AddAPIToken(token APITokenConfig) {
+	for i := range c.Server.Tokens {
+		if c.Server.Tokens[i].Name == token.Name {
+			c.Server.Tokens[i] = token
+			return
+		}
+	}
+	c.Server.Tokens = append(c.Server.Tokens, token)
+}
+
+// LabelFor returns the label of the known account with the given address, if any
+func (c *Config) LabelFor(address string) string {
+	for _, account := range c.Accounts {
+		if account.Address == address {
+			return account.Label
+		}
+	}
+	return ""
+}
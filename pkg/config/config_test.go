@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `# local node
+node:
+  url: ws://10.0.0.2:35998
+  timeout: 5s
+wallet:
+  default_keystore: main
+  wallet_dir: /srv/znn/wallet
+display:
+  log_level: debug
+watch:
+  state_file: /srv/znn/watch.json
+  interval: 1m
+confirm:
+  mainnet_guard: false
+accounts:
+  - label: savings
+    address: z1qqjnwjjpnue8xmmpanz6csze6tcmtzzdtfsww7
+    keystore: main
+    index: 3
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cli-config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	cfg, err := Load(writeConfig(t, testConfig))
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "saved.yaml")
+	require.NoError(t, cfg.Save(path))
+
+	saved, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, cfg, saved)
+
+	assert.Equal(t, "main", saved.Wallet.DefaultKeyStore)
+	assert.Equal(t, "/srv/znn/wallet", saved.Wallet.WalletDir)
+	assert.Equal(t, "debug", saved.Display.LogLevel)
+	assert.Equal(t, "/srv/znn/watch.json", saved.Watch.StateFile)
+	assert.Equal(t, time.Minute, saved.Watch.Interval)
+	assert.False(t, saved.Confirm.MainnetGuard)
+}
+
+func TestSaveKey(t *testing.T) {
+	path := writeConfig(t, testConfig)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	cfg.AddAccount(AccountConfig{Label: "spending", Address: "z1qxemdeddedxplasmaxxxxxxxxxxxxxxxxsctrp", KeyStore: "main", Index: 4})
+	require.NoError(t, SaveKey(path, "accounts", cfg.Accounts))
+	cfg.AddAPIToken(APITokenConfig{Name: "ci", Token: "secret", Allow: []string{"read"}})
+	require.NoError(t, SaveKey(path, "server.tokens", cfg.Server.Tokens))
+
+	saved, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, cfg, saved)
+
+	// Only the saved keys are written, everything else stays as it was
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# local node")
+	assert.Contains(t, string(data), "timeout: 5s")
+	assert.NotContains(t, string(data), "policy_dir")
+	assert.NotContains(t, string(data), "journal")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestSaveKeyCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "znn", "cli-config.yaml")
+	tokens := []APITokenConfig{{Name: "ci", Token: "secret", Allow: []string{"*"}}}
+	require.NoError(t, SaveKey(path, "server.tokens", tokens))
+
+	saved, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, tokens, saved.Server.Tokens)
+	assert.Equal(t, DefaultConfig().Server.Listen, saved.Server.Listen)
+}
@@ -0,0 +1,164 @@
+// Package confirm builds the summary shown before a block is signed and
+// decides whether the user has to confirm it.
+package confirm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/policy"
+)
+
+// Annotation is the cobra annotation key that marks commands running
+// unattended, such as daemons. They never prompt: blocks they sign are not
+// confirmed one by one, and blocks that need confirmation are rejected.
+const Annotation = "confirm"
+
+// Unattended is the value of Annotation for unattended commands
+const Unattended = "unattended"
+
+// MainnetChainID is the chain identifier of the Zenon mainnet
+const MainnetChainID = 1
+
+// Network describes the node a block is published to
+type Network struct {
+	URL     string
+	ChainID uint64
+}
+
+// Mainnet reports whether the node belongs to mainnet
+func (n Network) Mainnet() bool {
+	return n.ChainID == MainnetChainID
+}
+
+// String returns the network name and node URL
+func (n Network) String() string {
+	name := fmt.Sprintf("chain %d", n.ChainID)
+	if n.Mainnet() {
+		name = "mainnet"
+	}
+	return fmt.Sprintf("%s (%s)", name, n.URL)
+}
+
+// Resolver looks up what a summary shows besides the block itself
+type Resolver struct {
+	// Account returns the keyStore and index of a wallet address
+	Account func(types.Address) (string, int, bool)
+	// Label returns the label of a known account, or an empty string
+	Label func(string) string
+	// Token returns the symbol and decimals of a token
+	Token func(types.ZenonTokenStandard) (string, int, error)
+}
+
+// Summary describes a block before it is signed
+type Summary struct {
+	From    string
+	Account string
+	To      string
+	Token   string
+	Amount  string
+	Action  string
+	Plasma  string
+	Network Network
+}
+
+// Describe builds the summary of a block
+func Describe(block *nom.AccountBlock, network Network, r Resolver) (*Summary, error) {
+	s := &Summary{
+		From:    block.Address.String(),
+		Account: "not in a loaded keyStore",
+		To:      block.ToAddress.String(),
+		Action:  "send",
+		Network: network,
+	}
+	if keyStore, index, found := r.Account(block.Address); found {
+		s.Account = fmt.Sprintf("keyStore %s, index %d", keyStore, index)
+	}
+	if label := r.Label(s.To); label != "" {
+		s.To = fmt.Sprintf("%s (@%s)", s.To, label)
+	}
+
+	if method, args, isContract := policy.DecodeCall(block); isContract {
+		s.Action = method
+		if len(args) > 0 {
+			s.Action = fmt.Sprintf("%s(%s)", method, strings.Join(args, ", "))
+		}
+	} else if len(block.Data) > 0 {
+		s.Action = fmt.Sprintf("send with %d bytes of data", len(block.Data))
+	}
+
+	if block.Amount != nil && block.Amount.Sign() > 0 {
+		symbol, decimals, err := r.Token(block.TokenStandard)
+		if err != nil {
+			return nil, err
+		}
+		s.Token = fmt.Sprintf("%s (%s)", symbol, block.TokenStandard)
+		s.Amount = fmt.Sprintf("%s %s", format.Amount(block.Amount, decimals), symbol)
+	}
+
+	if block.Difficulty > 0 {
+		s.Plasma = fmt.Sprintf("PoW (difficulty %d)", block.Difficulty)
+	} else {
+		s.Plasma = fmt.Sprintf("%d fused plasma", block.FusedPlasma)
+	}
+	return s, nil
+}
+
+// Write renders the summary
+func (s *Summary) Write(w io.Writer) {
+	network := s.Network.String()
+	if s.Network.Mainnet() {
+		network = format.Red(network)
+	}
+	_, _ = fmt.Fprintln(w, "Transaction summary")
+	_, _ = fmt.Fprintf(w, "  From:     %s (%s)\n", s.From, s.Account)
+	_, _ = fmt.Fprintf(w, "  To:       %s\n", s.To)
+	if s.Amount != "" {
+		_, _ = fmt.Fprintf(w, "  Token:    %s\n", s.Token)
+		_, _ = fmt.Fprintf(w, "  Amount:   %s\n", format.Yellow(s.Amount))
+	}
+	_, _ = fmt.Fprintf(w, "  Action:   %s\n", s.Action)
+	_, _ = fmt.Fprintf(w, "  Plasma:   %s\n", s.Plasma)
+	_, _ = fmt.Fprintf(w, "  Network:  %s\n", network)
+}
+
+// Mode holds the settings that decide whether blocks are confirmed
+type Mode struct {
+	// Yes skips confirmation (--yes)
+	Yes bool
+	// MainnetGuard keeps confirmation on mainnet even with --yes
+	MainnetGuard bool
+	// Unattended is set for commands annotated as Unattended
+	Unattended bool
+}
+
+// Required reports whether a send block published to a network has to be
+// confirmed. Blocks a spending policy requires confirmation of always are;
+// --yes and unattended commands skip confirmation except on mainnet while
+// the mainnet guard is on. Unattended commands cannot ask for confirmation,
+// so for them a block that needs it is an errs.Policy error.
+func (m Mode) Required(network Network, policyReasons []string) (bool, error) {
+	var required bool
+	switch {
+	case len(policyReasons) > 0:
+		required = true
+	case m.Yes || m.Unattended:
+		required = m.MainnetGuard && network.Mainnet()
+	default:
+		required = true
+	}
+
+	if required && m.Unattended {
+		if len(policyReasons) > 0 {
+			return false, errs.Errorf(errs.Policy, "%s and an unattended command cannot confirm it", policyReasons[0])
+		}
+		return false, errs.New(errs.Policy, "publishing to mainnet requires confirmation, which an unattended command cannot ask for (confirm.mainnet_guard in the config file)")
+	}
+	return required, nil
+}
@@ -0,0 +1,107 @@
+package confirm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/testutil"
+)
+
+var resolver = Resolver{
+	Account: func(address types.Address) (string, int, bool) {
+		return "main", 3, address == testutil.ValidAddress
+	},
+	Label: func(address string) string {
+		if address == testutil.ProducerAddress.String() {
+			return "treasury"
+		}
+		return ""
+	},
+	Token: func(zts types.ZenonTokenStandard) (string, int, error) {
+		return "ZNN", 8, nil
+	},
+}
+
+func TestDescribe(t *testing.T) {
+	mainnet := Network{URL: "ws://127.0.0.1:35998", ChainID: MainnetChainID}
+	send := &nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       testutil.ValidAddress,
+		ToAddress:     testutil.ProducerAddress,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(150000000),
+		FusedPlasma:   21000,
+	}
+
+	summary, err := Describe(send, mainnet, resolver)
+	require.NoError(t, err)
+	assert.Equal(t, "keyStore main, index 3", summary.Account)
+	assert.Equal(t, testutil.ProducerAddress.String()+" (@treasury)", summary.To)
+	assert.Equal(t, "1.50000000 ZNN", summary.Amount)
+	assert.Equal(t, "send", summary.Action)
+	assert.Equal(t, "21000 fused plasma", summary.Plasma)
+
+	var out bytes.Buffer
+	summary.Write(&out)
+	assert.Contains(t, out.String(), "mainnet (ws://127.0.0.1:35998)")
+
+	fuse := &nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       testutil.ProducerAddress,
+		ToAddress:     types.PlasmaContract,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(0),
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, testutil.ValidAddress),
+		Difficulty:    80000,
+	}
+	summary, err = Describe(fuse, Network{ChainID: 3}, resolver)
+	require.NoError(t, err)
+	assert.Equal(t, "not in a loaded keyStore", summary.Account)
+	assert.Equal(t, "plasma.Fuse(address="+testutil.ValidAddress.String()+")", summary.Action)
+	assert.Empty(t, summary.Amount)
+	assert.Equal(t, "PoW (difficulty 80000)", summary.Plasma)
+}
+
+func TestRequired(t *testing.T) {
+	mainnet := Network{ChainID: MainnetChainID}
+	testnet := Network{ChainID: 3}
+	reasons := []string{"above the confirmation threshold"}
+
+	tests := []struct {
+		name     string
+		mode     Mode
+		network  Network
+		reasons  []string
+		required bool
+		rejected bool
+	}{
+		{"interactive", Mode{MainnetGuard: true}, testnet, nil, true, false},
+		{"yes on testnet", Mode{Yes: true, MainnetGuard: true}, testnet, nil, false, false},
+		{"yes on mainnet", Mode{Yes: true, MainnetGuard: true}, mainnet, nil, true, false},
+		{"yes without guard", Mode{Yes: true}, mainnet, nil, false, false},
+		{"unattended on testnet", Mode{Unattended: true, MainnetGuard: true}, testnet, nil, false, false},
+		{"unattended on mainnet", Mode{Unattended: true, MainnetGuard: true}, mainnet, nil, false, true},
+		{"unattended without guard", Mode{Unattended: true}, mainnet, nil, false, false},
+		{"policy", Mode{Yes: true}, testnet, reasons, true, false},
+		{"policy unattended", Mode{Unattended: true}, testnet, reasons, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			required, err := tt.mode.Required(tt.network, tt.reasons)
+			if tt.rejected {
+				assert.Equal(t, errs.Policy, errs.KindOf(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.required, required)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Package errs defines the kinds of errors the CLI reports, their exit codes
+// and the JSON error object returned by the API server.
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/zenon-network/go-zenon/rpc/server"
+)
+
+// Kind classifies an error
+type Kind string
+
+// Error kinds, with their exit codes
+const (
+	// Internal is any error that has no other kind (exit code 1)
+	Internal Kind = "internal"
+	// UserInput is an invalid argument, flag or file (exit code 2)
+	UserInput Kind = "user_input"
+	// Policy is a block blocked by a spending policy (exit code 3)
+	Policy Kind = "policy"
+	// Wallet is a keyStore that cannot be found, read or decrypted (exit code 4)
+	Wallet Kind = "wallet"
+	// Network is a node that cannot be reached (exit code 5)
+	Network Kind = "network"
+	// Rejected is a request or block the node refused (exit code 6)
+	Rejected Kind = "rejected"
+	// InsufficientFunds is a balance too low for the operation (exit code 7)
+	InsufficientFunds Kind = "insufficient_funds"
+	// Timeout is an operation that did not finish in time (exit code 8)
+	Timeout Kind = "timeout"
+	// NotConfirmed is a transaction the user did not confirm (exit code 9)
+	NotConfirmed Kind = "not_confirmed"
+	// Interrupted is an operation stopped by SIGINT or SIGTERM (exit code 130)
+	Interrupted Kind = "interrupted"
+)
+
+// JSON-RPC error codes of requests the client answers itself instead of the node
+const (
+	// RPCTimeoutCode answers a request the node did not answer in time
+	RPCTimeoutCode = -32090
+	// RPCInterruptedCode answers requests pending when the command is interrupted
+	RPCInterruptedCode = -32091
+)
+
+var exitCodes = map[Kind]int{
+	Internal:          1,
+	UserInput:         2,
+	Policy:            3,
+	Wallet:            4,
+	Network:           5,
+	Rejected:          6,
+	InsufficientFunds: 7,
+	Timeout:           8,
+	NotConfirmed:      9,
+	Interrupted:       130,
+}
+
+// ExitCode returns the process exit code of the kind
+func (k Kind) ExitCode() int {
+	if code, found := exitCodes[k]; found {
+		return code
+	}
+	return exitCodes[Internal]
+}
+
+// Kinded is implemented by errors that carry their kind
+type Kinded interface {
+	error
+	ErrorKind() Kind
+}
+
+// Error is an error with a kind
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorKind returns the kind of the error
+func (e *Error) ErrorKind() Kind {
+	return e.Kind
+}
+
+// New returns an error of a kind with a message
+func New(kind Kind, message string) error {
+	return &Error{Kind: kind, Err: errors.New(message)}
+}
+
+// Errorf returns an error of a kind formatted like fmt.Errorf, including %w
+func Errorf(kind Kind, format string, args ...any) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap gives an error a kind. It returns nil for a nil error.
+func Wrap(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// FromRPC gives the error of a node call its kind: Timeout, Rejected or
+// InsufficientFunds for errors returned by the node, or Network. Errors that
+// already have a kind are returned unchanged.
+func FromRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+	var kinded Kinded
+	if errors.As(err, &kinded) {
+		return err
+	}
+	if kind, found := classify(err); found {
+		return Wrap(kind, err)
+	}
+	return Wrap(Network, err)
+}
+
+// KindOf returns the kind of an error. The outermost kind in the chain wins.
+// Errors without a kind are classified by their cause where possible, and
+// are Internal otherwise.
+func KindOf(err error) Kind {
+	if err == nil {
+		return ""
+	}
+	var kinded Kinded
+	if errors.As(err, &kinded) {
+		return kinded.ErrorKind()
+	}
+	if kind, found := classify(err); found {
+		return kind
+	}
+	return Internal
+}
+
+// ExitCode returns the process exit code of an error, 0 for nil
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return KindOf(err).ExitCode()
+}
+
+// classify recognizes interruptions, timeouts, node errors and connection
+// failures
+func classify(err error) (Kind, bool) {
+	var netErr net.Error
+	var rpcErr server.Error
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return Interrupted, true
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) ||
+		(errors.As(err, &netErr) && netErr.Timeout()):
+		return Timeout, true
+	case errors.As(err, &rpcErr) && rpcErr.ErrorCode() == RPCTimeoutCode:
+		return Timeout, true
+	case errors.As(err, &rpcErr) && rpcErr.ErrorCode() == RPCInterruptedCode:
+		return Interrupted, true
+	case errors.As(err, &rpcErr):
+		if strings.Contains(strings.ToLower(rpcErr.Error()), "insufficient balance") {
+			return InsufficientFunds, true
+		}
+		return Rejected, true
+	case errors.As(err, &opErr) || errors.Is(err, server.ErrClientQuit) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET):
+		return Network, true
+	}
+	return "", false
+}
+
+// Object is the JSON error object
+type Object struct {
+	Error    string `json:"error"`
+	Kind     Kind   `json:"kind"`
+	ExitCode int    `json:"exitCode"`
+}
+
+// ToObject returns the JSON error object of an error
+func ToObject(err error) Object {
+	kind := KindOf(err)
+	return Object{Error: err.Error(), Kind: kind, ExitCode: kind.ExitCode()}
+}
@@ -0,0 +1,76 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenon-network/go-zenon/rpc/server"
+)
+
+// nodeError is an error returned by the node
+type nodeError struct{ message string }
+
+func (e nodeError) Error() string  { return e.message }
+func (e nodeError) ErrorCode() int { return -32000 }
+
+var _ server.Error = nodeError{}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind Kind
+		code int
+	}{
+		{"nil", nil, "", 0},
+		{"plain", errors.New("boom"), Internal, 1},
+		{"user input", New(UserInput, "invalid amount"), UserInput, 2},
+		{"wrapped", fmt.Errorf("failed to send: %w", New(Wallet, "keyStore not found")), Wallet, 4},
+		{"outermost wins", Wrap(InsufficientFunds, New(UserInput, "insufficient ZNN")), InsufficientFunds, 7},
+		{"deadline", fmt.Errorf("failed: %w", context.DeadlineExceeded), Timeout, 8},
+		{"node error", nodeError{"invalid signature"}, Rejected, 6},
+		{"node balance", nodeError{"insufficient balance for transaction"}, InsufficientFunds, 7},
+		{"connection refused", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, Network, 5},
+		{"client quit", server.ErrClientQuit, Network, 5},
+		{"not confirmed", New(NotConfirmed, "transaction not confirmed"), NotConfirmed, 9},
+		{"canceled", fmt.Errorf("failed: %w", context.Canceled), Interrupted, 130},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.kind, KindOf(tt.err))
+			assert.Equal(t, tt.code, ExitCode(tt.err))
+		})
+	}
+}
+
+func TestFromRPC(t *testing.T) {
+	assert.NoError(t, FromRPC(nil))
+	assert.Equal(t, Network, KindOf(FromRPC(errors.New("unexpected EOF"))))
+	assert.Equal(t, Rejected, KindOf(FromRPC(nodeError{"invalid block"})))
+
+	kinded := New(Policy, "blocked")
+	assert.Same(t, kinded, FromRPC(kinded), "errors with a kind are unchanged")
+}
+
+func TestWrap(t *testing.T) {
+	assert.NoError(t, Wrap(UserInput, nil))
+
+	cause := errors.New("bad flag")
+	err := Wrap(UserInput, cause)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "bad flag", err.Error())
+
+	err = Errorf(Timeout, "timed out waiting: %w", context.DeadlineExceeded)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestToObject(t *testing.T) {
+	obj := ToObject(New(InsufficientFunds, "insufficient ZNN balance"))
+	assert.Equal(t, Object{Error: "insufficient ZNN balance", Kind: InsufficientFunds, ExitCode: 7}, obj)
+	assert.Equal(t, 1, Kind("unknown").ExitCode())
+}
@@ -0,0 +1,171 @@
+package exporter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/rewards"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+)
+
+const (
+	// coinDecimals is the number of decimals of ZNN and QSR
+	coinDecimals = 8
+
+	// pageSize is the number of entries requested per page
+	pageSize = 100
+)
+
+// Collector queries the node for the configured addresses and pillars
+type Collector struct {
+	Addresses []types.Address
+	// Pillars limits pillar metrics to these names; empty exports every pillar
+	Pillars []string
+	Now     func() time.Time
+}
+
+// Collect builds a snapshot of the current state. A nil client or a failed
+// frontier momentum query marks the node as unreachable. Other query errors
+// skip the affected metrics and are returned alongside the snapshot.
+func (c *Collector) Collect(client *rpc_client.RpcClient) (*Snapshot, []error) {
+	now := time.Now()
+	if c.Now != nil {
+		now = c.Now()
+	}
+
+	snapshot := NewSnapshot()
+	up := snapshot.Gauge("znn_up", "Whether the node answered the last collection (1) or not (0).")
+	errorsGauge := snapshot.Gauge("znn_exporter_errors", "Number of failed queries in the last collection.")
+	snapshot.Gauge("znn_exporter_last_collection_timestamp_seconds", "Unix time of the last collection.").Add(float64(now.Unix()))
+
+	if client == nil {
+		up.Add(0)
+		errorsGauge.Add(1)
+		return snapshot, []error{fmt.Errorf("node is not connected")}
+	}
+
+	momentum, err := client.LedgerApi.GetFrontierMomentum()
+	if err != nil {
+		up.Add(0)
+		errorsGauge.Add(1)
+		return snapshot, []error{fmt.Errorf("failed to get frontier momentum: %w", err)}
+	}
+	up.Add(1)
+	snapshot.Gauge("znn_momentum_height", "Height of the frontier momentum.").Add(float64(momentum.Height))
+	snapshot.Gauge("znn_momentum_age_seconds", "Seconds since the frontier momentum was produced.").
+		Add(now.Sub(time.Unix(int64(momentum.TimestampUnix), 0)).Seconds())
+
+	var errs []error
+	for _, address := range c.Addresses {
+		errs = append(errs, c.collectAddress(client, snapshot, address)...)
+	}
+	if err := c.collectPillars(client, snapshot); err != nil {
+		errs = append(errs, err)
+	}
+
+	errorsGauge.Add(float64(len(errs)))
+	return snapshot, errs
+}
+
+// collectAddress adds the account metrics of one address
+func (c *Collector) collectAddress(client *rpc_client.RpcClient, snapshot *Snapshot, address types.Address) []error {
+	var errs []error
+	addr := address.String()
+
+	balance := snapshot.Gauge("znn_balance", "Token balance of the address, in whole tokens.")
+	if info, err := client.LedgerApi.GetAccountInfoByAddress(address); err != nil {
+		errs = append(errs, fmt.Errorf("%s: failed to get balances: %w", addr, err))
+	} else {
+		snapshot.Gauge("znn_account_height", "Height of the account chain.").Add(float64(info.AccountHeight), "address", addr)
+		for zts, balanceInfo := range info.BalanceInfoMap {
+			symbol, decimals := "", 0
+			if balanceInfo.TokenInfo != nil {
+				symbol, decimals = balanceInfo.TokenInfo.TokenSymbol, int(balanceInfo.TokenInfo.Decimals)
+			}
+			balance.Add(TokenValue(balanceInfo.Balance, decimals), "address", addr, "token", zts.String(), "symbol", symbol)
+		}
+	}
+
+	if plasma, err := client.PlasmaApi.Get(address); err != nil {
+		errs = append(errs, fmt.Errorf("%s: failed to get plasma: %w", addr, err))
+	} else {
+		snapshot.Gauge("znn_plasma_current", "Current plasma of the address.").Add(float64(plasma.CurrentPlasma), "address", addr)
+		snapshot.Gauge("znn_plasma_max", "Maximum plasma of the address.").Add(float64(plasma.MaxPlasma), "address", addr)
+		snapshot.Gauge("znn_plasma_fused_qsr", "QSR fused for the address.").Add(TokenValue(plasma.QsrAmount, coinDecimals), "address", addr)
+	}
+
+	if stakes, err := client.StakeApi.GetEntriesByAddress(address, 0, 1); err != nil {
+		errs = append(errs, fmt.Errorf("%s: failed to get stakes: %w", addr, err))
+	} else {
+		snapshot.Gauge("znn_staked_znn", "ZNN staked by the address.").Add(TokenValue(stakes.TotalAmount, coinDecimals), "address", addr)
+		snapshot.Gauge("znn_stake_entries", "Number of stake entries of the address.").Add(float64(stakes.Count), "address", addr)
+	}
+
+	uncollected := snapshot.Gauge("znn_uncollected_reward", "Uncollected rewards of the address, in whole tokens.")
+	for _, source := range rewards.Sources {
+		reward, err := source.Uncollected(client, address)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", addr, err))
+			continue
+		}
+		uncollected.Add(TokenValue(reward.Znn, coinDecimals), "address", addr, "source", source.Name, "symbol", "ZNN")
+		uncollected.Add(TokenValue(reward.Qsr, coinDecimals), "address", addr, "source", source.Name, "symbol", "QSR")
+	}
+
+	if sentinel, err := client.SentinelApi.GetByOwner(address); err != nil {
+		errs = append(errs, fmt.Errorf("%s: failed to get sentinel: %w", addr, err))
+	} else {
+		registered, active := 0.0, 0.0
+		if sentinel != nil {
+			registered = 1
+			if sentinel.Active {
+				active = 1
+			}
+		}
+		snapshot.Gauge("znn_sentinel_registered", "Whether the address owns a sentinel.").Add(registered, "address", addr)
+		snapshot.Gauge("znn_sentinel_active", "Whether the sentinel owned by the address is active.").Add(active, "address", addr)
+	}
+
+	return errs
+}
+
+// collectPillars adds the metrics of the selected pillars
+func (c *Collector) collectPillars(client *rpc_client.RpcClient, snapshot *Snapshot) error {
+	var pillars []*embedded.PillarInfo
+	for pageIndex := uint32(0); ; pageIndex++ {
+		list, err := client.PillarApi.GetAll(pageIndex, pageSize)
+		if err != nil {
+			return fmt.Errorf("failed to get pillars: %w", err)
+		}
+		pillars = append(pillars, list.List...)
+		if len(list.List) < pageSize || uint32(len(pillars)) >= list.Count {
+			break
+		}
+	}
+
+	selected := make(map[string]bool, len(c.Pillars))
+	for _, name := range c.Pillars {
+		selected[name] = true
+	}
+
+	produced := snapshot.Gauge("znn_pillar_produced_momentums", "Momentums produced by the pillar in the current epoch.")
+	expected := snapshot.Gauge("znn_pillar_expected_momentums", "Momentums expected from the pillar in the current epoch.")
+	weight := snapshot.Gauge("znn_pillar_weight", "Delegation weight of the pillar, in ZNN.")
+	rank := snapshot.Gauge("znn_pillar_rank", "Rank of the pillar by weight.")
+	for _, pillar := range pillars {
+		if len(selected) > 0 && !selected[pillar.Name] {
+			continue
+		}
+		if pillar.CurrentStats != nil {
+			produced.Add(float64(pillar.CurrentStats.ProducedMomentums), "pillar", pillar.Name)
+			expected.Add(float64(pillar.CurrentStats.ExpectedMomentums), "pillar", pillar.Name)
+		}
+		weight.Add(TokenValue(pillar.Weight, coinDecimals), "pillar", pillar.Name)
+		rank.Add(float64(pillar.Rank), "pillar", pillar.Name)
+	}
+
+	return nil
+}
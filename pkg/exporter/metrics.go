@@ -0,0 +1,130 @@
+// Package exporter collects account and network state from a Zenon node and
+// renders it in the Prometheus text exposition format.
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the content type of the Prometheus text format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Sample is a single gauge value with its label pairs
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+// Family is a named gauge and its samples
+type Family struct {
+	Name    string
+	Help    string
+	Samples []Sample
+}
+
+// Add records a sample. Labels are given as name, value pairs.
+func (f *Family) Add(value float64, labels ...string) {
+	if len(labels)%2 != 0 {
+		panic(fmt.Sprintf("metric %s: labels must be name/value pairs", f.Name))
+	}
+	f.Samples = append(f.Samples, Sample{Labels: labels, Value: value})
+}
+
+// Snapshot is a set of gauge families from one collection
+type Snapshot struct {
+	families []*Family
+	byName   map[string]*Family
+}
+
+// NewSnapshot returns an empty snapshot
+func NewSnapshot() *Snapshot {
+	return &Snapshot{byName: make(map[string]*Family)}
+}
+
+// Gauge returns the family with the given name, creating it if needed
+func (s *Snapshot) Gauge(name, help string) *Family {
+	if family, found := s.byName[name]; found {
+		return family
+	}
+	family := &Family{Name: name, Help: help}
+	s.families = append(s.families, family)
+	s.byName[name] = family
+	return family
+}
+
+// Families returns the families in creation order
+func (s *Snapshot) Families() []*Family {
+	return s.families
+}
+
+// Write renders the snapshot in the Prometheus text format
+func (s *Snapshot) Write(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	for _, family := range s.families {
+		if len(family.Samples) == 0 {
+			continue
+		}
+		fmt.Fprintf(buf, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+		fmt.Fprintf(buf, "# TYPE %s gauge\n", family.Name)
+		for _, sample := range family.Samples {
+			buf.WriteString(family.Name)
+			if len(sample.Labels) > 0 {
+				buf.WriteByte('{')
+				for i := 0; i < len(sample.Labels); i += 2 {
+					if i > 0 {
+						buf.WriteByte(',')
+					}
+					fmt.Fprintf(buf, "%s=\"%s\"", sample.Labels[i], escapeLabel(sample.Labels[i+1]))
+				}
+				buf.WriteByte('}')
+			}
+			buf.WriteByte(' ')
+			buf.WriteString(formatValue(sample.Value))
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Flush()
+}
+
+// TokenValue converts a base-unit amount to a float in whole tokens
+func TokenValue(amount *big.Int, decimals int) float64 {
+	if amount == nil {
+		return 0
+	}
+	value := new(big.Float).SetInt(amount)
+	if decimals > 0 {
+		divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+		value.Quo(value, divisor)
+	}
+	result, _ := value.Float64()
+	return result
+}
+
+// formatValue formats a sample value
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// escapeLabel escapes a label value
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// escapeHelp escapes a help string
+func escapeHelp(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,54 @@
+package exporter
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSnapshotWrite verifies the Prometheus text format
+func TestSnapshotWrite(t *testing.T) {
+	snapshot := NewSnapshot()
+	snapshot.Gauge("znn_up", "Node reachability.").Add(1)
+	balance := snapshot.Gauge("znn_balance", "Token balance.")
+	balance.Add(12.5, "address", "z1qq", "symbol", "ZNN")
+	balance.Add(0.25, "address", "z1qq", "symbol", `A"B\C`)
+	snapshot.Gauge("znn_empty", "Never written.")
+
+	// Gauge returns the existing family
+	assert.Same(t, balance, snapshot.Gauge("znn_balance", "ignored"))
+
+	var buf strings.Builder
+	require.NoError(t, snapshot.Write(&buf))
+
+	expected := `# HELP znn_up Node reachability.
+# TYPE znn_up gauge
+znn_up 1
+# HELP znn_balance Token balance.
+# TYPE znn_balance gauge
+znn_balance{address="z1qq",symbol="ZNN"} 12.5
+znn_balance{address="z1qq",symbol="A\"B\\C"} 0.25
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+// TestTokenValue verifies base-unit conversion
+func TestTokenValue(t *testing.T) {
+	assert.Equal(t, 12.5, TokenValue(big.NewInt(1250000000), 8))
+	assert.Equal(t, 42.0, TokenValue(big.NewInt(42), 0))
+	assert.Equal(t, 0.0, TokenValue(nil, 8))
+}
+
+// TestCollectWithoutNode verifies an unreachable node is reported as down
+func TestCollectWithoutNode(t *testing.T) {
+	snapshot, errs := (&Collector{}).Collect(nil)
+	require.Len(t, errs, 1)
+
+	var buf strings.Builder
+	require.NoError(t, snapshot.Write(&buf))
+	assert.Contains(t, buf.String(), "znn_up 0\n")
+	assert.Contains(t, buf.String(), "znn_exporter_errors 1\n")
+}
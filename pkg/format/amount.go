@@ -0,0 +1,121 @@
+package format
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+)
+
+// AmountMax is the amount expression that resolves to the full available amount
+const AmountMax = "max"
+
+// IsRelativeAmount reports whether an amount expression depends on the
+// available amount (max or a percentage)
+func IsRelativeAmount(expr string) bool {
+	expr = strings.ToLower(strings.TrimSpace(expr))
+	return expr == AmountMax || strings.HasSuffix(expr, "%")
+}
+
+// ResolveAmount resolves an amount expression to base units.
+//
+// Supported expressions:
+//   - "max": the full available amount
+//   - "50%", "12.5%": a percentage of the available amount, rounded down
+//   - "1,000.5" or "1_000.5": a decimal amount with optional thousands separators
+//   - "150000000" with raw=true: an integer amount in base units
+//
+// The available amount is only required for relative expressions, which
+// cannot be combined with raw.
+func ResolveAmount(expr string, decimals int, available *big.Int, raw bool) (*big.Int, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("amount cannot be empty")
+	}
+
+	if IsRelativeAmount(expr) {
+		if raw {
+			return nil, errs.Errorf(errs.UserInput, "%s cannot be combined with --raw", expr)
+		}
+		if available == nil {
+			return nil, fmt.Errorf("%s requires a known available amount", expr)
+		}
+		if strings.EqualFold(expr, AmountMax) {
+			return new(big.Int).Set(available), nil
+		}
+		return resolvePercent(strings.TrimSuffix(expr, "%"), available)
+	}
+
+	expr, err := stripThousands(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if raw {
+		amount, ok := new(big.Int).SetString(expr, 10)
+		if !ok || amount.Sign() < 0 {
+			return nil, fmt.Errorf("invalid raw amount: must be a whole number of base units")
+		}
+		return amount, nil
+	}
+
+	return ParseAmount(expr, decimals)
+}
+
+// AmountExpression formats an amount expression together with its resolved value,
+// e.g. "50% = 12.50000000 QSR (1250000000 base units)"
+func AmountExpression(expr string, amount *big.Int, decimals int, symbol string) string {
+	resolved := fmt.Sprintf("%s %s (%s base units)", Amount(amount, decimals), symbol, amount.String())
+	expr = strings.TrimSpace(expr)
+	if expr == "" || expr == Amount(amount, decimals) {
+		return resolved
+	}
+	return expr + " = " + resolved
+}
+
+// resolvePercent computes a percentage of the available amount, rounded down
+func resolvePercent(value string, available *big.Int) (*big.Int, error) {
+	percent, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return nil, fmt.Errorf("invalid percentage: %s%%", value)
+	}
+	if percent.Sign() <= 0 || percent.Cmp(big.NewRat(100, 1)) > 0 {
+		return nil, fmt.Errorf("percentage must be greater than 0 and at most 100")
+	}
+
+	share := new(big.Rat).Mul(new(big.Rat).SetInt(available), percent)
+	share.Quo(share, big.NewRat(100, 1))
+
+	return new(big.Int).Quo(share.Num(), share.Denom()), nil
+}
+
+// stripThousands removes "," and "_" thousands separators from the integer part
+// of an amount, validating that digits are grouped by three
+func stripThousands(expr string) (string, error) {
+	integerPart, decimalPart, hasDecimals := strings.Cut(expr, ".")
+	if strings.ContainsAny(decimalPart, ",_") {
+		return "", fmt.Errorf("invalid amount: separators are not allowed after the decimal point")
+	}
+	if !strings.ContainsAny(integerPart, ",_") {
+		return expr, nil
+	}
+
+	groups := strings.FieldsFunc(integerPart, func(r rune) bool { return r == ',' || r == '_' })
+	if len(groups) < 2 || strings.HasPrefix(integerPart, ",") || strings.HasPrefix(integerPart, "_") ||
+		strings.HasSuffix(integerPart, ",") || strings.HasSuffix(integerPart, "_") ||
+		strings.Contains(integerPart, ",,") || strings.Contains(integerPart, "__") {
+		return "", fmt.Errorf("invalid thousands separators in amount: %s", expr)
+	}
+	for i, group := range groups {
+		if (i == 0 && len(group) > 3) || (i > 0 && len(group) != 3) {
+			return "", fmt.Errorf("invalid thousands separators in amount: %s", expr)
+		}
+	}
+
+	result := strings.Join(groups, "")
+	if hasDecimals {
+		result += "." + decimalPart
+	}
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package format
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestResolveAmount tests resolving amount expressions against an available amount
+func TestResolveAmount(t *testing.T) {
+	available := big.NewInt(2500000000) // 25 coins
+
+	tests := []struct {
+		name      string
+		expr      string
+		decimals  int
+		available *big.Int
+		raw       bool
+		expected  *big.Int
+		wantErr   bool
+	}{
+		{name: "decimal", expr: "1.5", decimals: 8, expected: big.NewInt(150000000)},
+		{name: "max", expr: "max", decimals: 8, available: available, expected: big.NewInt(2500000000)},
+		{name: "max uppercase", expr: "MAX", decimals: 8, available: available, expected: big.NewInt(2500000000)},
+		{name: "percent", expr: "50%", decimals: 8, available: available, expected: big.NewInt(1250000000)},
+		{name: "fractional percent", expr: "12.5%", decimals: 8, available: available, expected: big.NewInt(312500000)},
+		{name: "percent rounds down", expr: "33%", decimals: 0, available: big.NewInt(10), expected: big.NewInt(3)},
+		{name: "hundred percent", expr: "100%", decimals: 8, available: available, expected: big.NewInt(2500000000)},
+		{name: "thousands comma", expr: "1,000.5", decimals: 8, expected: big.NewInt(100050000000)},
+		{name: "thousands underscore", expr: "12_345", decimals: 0, expected: big.NewInt(12345)},
+		{name: "raw", expr: "150000000", decimals: 8, raw: true, expected: big.NewInt(150000000)},
+		{name: "raw with separators", expr: "1,000", decimals: 8, raw: true, expected: big.NewInt(1000)},
+		{name: "max without available", expr: "max", decimals: 8, wantErr: true},
+		{name: "zero percent", expr: "0%", decimals: 8, available: available, wantErr: true},
+		{name: "over hundred percent", expr: "150%", decimals: 8, available: available, wantErr: true},
+		{name: "invalid percent", expr: "abc%", decimals: 8, available: available, wantErr: true},
+		{name: "raw with decimals", expr: "1.5", decimals: 8, raw: true, wantErr: true},
+		{name: "raw max", expr: "max", decimals: 8, available: available, raw: true, wantErr: true},
+		{name: "raw percent", expr: "50%", decimals: 8, available: available, raw: true, wantErr: true},
+		{name: "bad grouping", expr: "1,00", decimals: 8, wantErr: true},
+		{name: "leading separator", expr: ",100", decimals: 8, wantErr: true},
+		{name: "separator in decimals", expr: "1.000,5", decimals: 8, wantErr: true},
+		{name: "empty", expr: " ", decimals: 8, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ResolveAmount(tt.expr, tt.decimals, tt.available, tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 0, tt.expected.Cmp(result), "expected %s, got %s", tt.expected, result)
+		})
+	}
+}
+
+// TestIsRelativeAmount tests detection of balance-relative expressions
+func TestIsRelativeAmount(t *testing.T) {
+	assert.True(t, IsRelativeAmount("max"))
+	assert.True(t, IsRelativeAmount(" Max "))
+	assert.True(t, IsRelativeAmount("25%"))
+	assert.False(t, IsRelativeAmount("25"))
+	assert.False(t, IsRelativeAmount("1,000"))
+}
+
+// TestAmountExpression tests display of an expression with its resolved amount
+func TestAmountExpression(t *testing.T) {
+	assert.Equal(t, "50% = 12.50000000 QSR (1250000000 base units)",
+		AmountExpression("50%", big.NewInt(1250000000), 8, "QSR"))
+	assert.Equal(t, "1.00000000 ZNN (100000000 base units)",
+		AmountExpression("1.00000000", big.NewInt(100000000), 8, "ZNN"))
+}
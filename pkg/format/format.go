@@ -4,10 +4,13 @@
 package format
 
 import (
+	"encoding/hex"
 	"fmt"
 	"math/big"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
 )
@@ -150,6 +153,30 @@ func FormatToken(amount *big.Int, decimals int, symbol string) string {
 	}
 }
 
+// Memo formats account block data for display.
+// Printable UTF-8 text is returned as is, anything else as 0x-prefixed hex.
+// Returns an empty string for empty data.
+func Memo(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	if utf8.Valid(data) {
+		printable := true
+		for _, r := range string(data) {
+			if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+				printable = false
+				break
+			}
+		}
+		if printable {
+			return string(data)
+		}
+	}
+
+	return "0x" + hex.EncodeToString(data)
+}
+
 // ParseTokenStandard parses a token identifier (ZNN, QSR, or ZTS address)
 // Returns the normalized token identifier
 func ParseTokenStandard(token string) (string, error) {
@@ -502,3 +502,24 @@ func BenchmarkParseAmount(b *testing.B) {
 		_, _ = ParseAmount(input, 8)
 	}
 }
+
+// TestMemo tests display formatting of account block data
+func TestMemo(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{name: "empty", data: nil, expected: ""},
+		{name: "text", data: []byte("invoice #42"), expected: "invoice #42"},
+		{name: "unicode text", data: []byte("paiement reçu"), expected: "paiement reçu"},
+		{name: "binary", data: []byte{0x00, 0xff, 0x10}, expected: "0x00ff10"},
+		{name: "invalid utf8", data: []byte{0xc3, 0x28}, expected: "0xc328"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Memo(tt.data))
+		})
+	}
+}
@@ -0,0 +1,416 @@
+// Package journal keeps an append-only, hash-chained record of every block
+// the CLI publishes. Each entry stores the hash of the previous entry, so
+// editing, removing or reordering entries breaks the chain and is detected
+// by Verify.
+package journal
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+)
+
+// Entry kinds
+const (
+	// KindPublish records a publish attempt and the block
+	KindPublish = "publish"
+	// KindConfirm records that a published block was confirmed by a momentum
+	KindConfirm = "confirm"
+)
+
+// genesis is the previous hash of the first entry
+var genesis = strings.Repeat("0", sha256.Size*2)
+
+// Entry is one line of the journal
+type Entry struct {
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+	// Command is the command path, e.g. "znn-cli send"
+	Command string `json:"command,omitempty"`
+	// Args are the command line arguments with secrets removed
+	Args    []string `json:"args,omitempty"`
+	Address string   `json:"address,omitempty"`
+	// KeyStore is the keyStore that signed the block, if known
+	KeyStore string `json:"keyStore,omitempty"`
+	// Hash is the block hash
+	Hash  string          `json:"hash"`
+	Block json.RawMessage `json:"block,omitempty"`
+	// Published is false when the node rejected the block; Error says why
+	Published bool   `json:"published,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// MomentumHeight is the momentum that confirmed the block
+	MomentumHeight uint64 `json:"momentumHeight,omitempty"`
+	// Prev is the EntryHash of the previous entry
+	Prev      string `json:"prev"`
+	EntryHash string `json:"entryHash"`
+}
+
+// computeHash returns the hash of an entry, covering every field but EntryHash
+func (e *Entry) computeHash() (string, error) {
+	unsigned := *e
+	unsigned.EntryHash = ""
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Journal appends entries to a JSON lines file. Appends hold an exclusive
+// lock on the file, so several processes can share one journal.
+type Journal struct {
+	Path string
+
+	mu sync.Mutex
+	// last is the last entry in the file when it was size bytes long
+	last *Entry
+	size int64
+}
+
+// Append chains an entry to the end of the journal, setting its Seq, Prev
+// and EntryHash, and its Time if unset
+func (j *Journal) Append(entry *Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(j.Path), 0750); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	// #nosec G304 - Path is provided by the user or the default config directory
+	file, err := os.OpenFile(j.Path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if err := lockFile(file); err != nil {
+		return fmt.Errorf("failed to lock journal: %w", err)
+	}
+	defer func() { _ = unlockFile(file) }()
+
+	// Another process may have appended since the last entry was read
+	last, err := j.lastEntry(file)
+	if err != nil {
+		return err
+	}
+
+	entry.Seq = 1
+	entry.Prev = genesis
+	if last != nil {
+		entry.Seq = last.Seq + 1
+		entry.Prev = last.EntryHash
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	hash, err := entry.computeHash()
+	if err != nil {
+		return err
+	}
+	entry.EntryHash = hash
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	j.last = entry
+	j.size = info.Size()
+	return nil
+}
+
+// lastEntry returns the last entry of the locked journal file, reading the
+// file again unless it still has the size it had after the last append
+func (j *Journal) lastEntry(file *os.File) (*Entry, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	if j.last != nil && info.Size() == j.size {
+		return j.last, nil
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	entries, err := read(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return entries[len(entries)-1], nil
+}
+
+// Read reads every entry of a journal, returning none if it does not exist
+func Read(path string) ([]*Entry, error) {
+	// #nosec G304 - Path is provided by the user or the default config directory
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return read(file)
+}
+
+// read parses the entries of a journal
+func read(r io.Reader) ([]*Entry, error) {
+	var entries []*Entry
+	scanner := bufio.NewScanner(r)
+	// Blocks carry up to 16 KiB of data, hex encoded
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse journal line %d: %w", line, err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// Verify checks that entries form an unbroken hash chain starting at the
+// first entry of a journal. It reports the first entry that does not.
+func Verify(entries []*Entry) error {
+	prev := genesis
+	for i, entry := range entries {
+		if entry.Seq != uint64(i+1) {
+			return fmt.Errorf("entry %d: expected sequence number %d, found %d", i+1, i+1, entry.Seq)
+		}
+		if entry.Prev != prev {
+			return fmt.Errorf("entry %d: does not link to the previous entry", entry.Seq)
+		}
+		hash, err := entry.computeHash()
+		if err != nil {
+			return err
+		}
+		if entry.EntryHash != hash {
+			return fmt.Errorf("entry %d: contents do not match its hash", entry.Seq)
+		}
+		prev = entry.EntryHash
+	}
+	return nil
+}
+
+// Find returns the publish entry of a block and its confirmation, if any.
+// A hash prefix of at least 8 characters or a sequence number also matches.
+func Find(entries []*Entry, query string) (*Entry, *Entry, error) {
+	var found *Entry
+	for _, entry := range entries {
+		if entry.Kind != KindPublish {
+			continue
+		}
+		if entry.Hash == query || fmt.Sprint(entry.Seq) == query ||
+			(len(query) >= 8 && strings.HasPrefix(entry.Hash, query)) {
+			if found != nil && found.Hash != entry.Hash {
+				return nil, nil, fmt.Errorf("%q matches more than one journal entry", query)
+			}
+			found = entry
+		}
+	}
+	if found == nil {
+		return nil, nil, fmt.Errorf("no journal entry matches %q", query)
+	}
+	return found, Confirmations(entries)[found.Hash], nil
+}
+
+// Confirmations maps block hashes to their confirmation entries
+func Confirmations(entries []*Entry) map[string]*Entry {
+	confirmed := make(map[string]*Entry)
+	for _, entry := range entries {
+		if entry.Kind == KindConfirm {
+			confirmed[entry.Hash] = entry
+		}
+	}
+	return confirmed
+}
+
+// Pending returns the hashes of published blocks without a confirmation
+func Pending(entries []*Entry) []string {
+	confirmed := Confirmations(entries)
+	seen := make(map[string]bool)
+	var pending []string
+	for _, entry := range entries {
+		if entry.Kind != KindPublish || !entry.Published || confirmed[entry.Hash] != nil || seen[entry.Hash] {
+			continue
+		}
+		seen[entry.Hash] = true
+		pending = append(pending, entry.Hash)
+	}
+	return pending
+}
+
+// Spent returns the amount of a token sent in blocks the node accepted since
+// a time, counting the entries selected by from
+func Spent(entries []*Entry, from func(*Entry) bool, zts types.ZenonTokenStandard, since time.Time) (*big.Int, error) {
+	total := new(big.Int)
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Kind != KindPublish || !entry.Published || entry.Time.Before(since) ||
+			seen[entry.Hash] || !from(entry) {
+			continue
+		}
+		seen[entry.Hash] = true
+		var block nom.AccountBlock
+		if err := json.Unmarshal(entry.Block, &block); err != nil {
+			return nil, fmt.Errorf("failed to parse block of entry %d: %w", entry.Seq, err)
+		}
+		if nom.IsSendBlock(block.BlockType) && block.TokenStandard == zts && block.Amount != nil {
+			total.Add(total, block.Amount)
+		}
+	}
+	return total, nil
+}
+
+// secretFlags are flags whose values are never written to the journal
+var secretFlags = []string{"passphrase", "p", "secret", "mnemonic", "token"}
+
+// Redacted replaces removed secrets
+const Redacted = "[REDACTED]"
+
+// RedactArgs returns command line arguments with the values of secret flags
+// removed, as well as anything that looks like a mnemonic
+func RedactArgs(args []string) []string {
+	redacted := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(strings.Fields(arg)) >= 12 {
+			redacted = append(redacted, Redacted)
+			continue
+		}
+		// -pSECRET
+		if strings.HasPrefix(arg, "-p") && !strings.HasPrefix(arg, "--") && len(arg) > 2 {
+			redacted = append(redacted, "-p"+Redacted)
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || !isSecretFlag(name) {
+			redacted = append(redacted, arg)
+			continue
+		}
+		if hasValue {
+			flag, _, _ := strings.Cut(arg, "=")
+			redacted = append(redacted, flag+"="+Redacted)
+			continue
+		}
+		redacted = append(redacted, arg)
+		if i+1 < len(args) {
+			redacted = append(redacted, Redacted)
+			i++
+		}
+	}
+	return redacted
+}
+
+func isSecretFlag(name string) bool {
+	for _, secret := range secretFlags {
+		if name == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// Recorder writes the publish entries of one command invocation
+type Recorder struct {
+	Journal *Journal
+	Command string
+	// Args must already be redacted
+	Args []string
+	// KeyStore returns the keyStore of an address, or an empty string
+	KeyStore func(types.Address) string
+}
+
+// Record appends a publish entry for a block and the result of publishing it
+func (r *Recorder) Record(block *nom.AccountBlock, publishErr error) error {
+	data, err := json.Marshal(block)
+	if err != nil {
+		return fmt.Errorf("failed to encode block: %w", err)
+	}
+	entry := &Entry{
+		Kind:      KindPublish,
+		Command:   r.Command,
+		Args:      r.Args,
+		Address:   block.Address.String(),
+		Hash:      block.Hash.String(),
+		Block:     data,
+		Published: publishErr == nil,
+	}
+	if r.KeyStore != nil {
+		entry.KeyStore = r.KeyStore(block.Address)
+	}
+	if publishErr != nil {
+		entry.Error = publishErr.Error()
+	}
+	return r.Journal.Append(entry)
+}
+
+// Refresh looks up every published block that is not confirmed yet and
+// appends a confirm entry for each one the node has confirmed. It returns
+// the number of blocks confirmed.
+func Refresh(c *rpc_client.RpcClient, j *Journal) (int, error) {
+	entries, err := Read(j.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	confirmed := 0
+	for _, hash := range Pending(entries) {
+		parsed, err := types.HexToHash(hash)
+		if err != nil {
+			return confirmed, fmt.Errorf("invalid block hash %s in journal: %w", hash, err)
+		}
+		block, err := c.LedgerApi.GetAccountBlockByHash(parsed)
+		if err != nil {
+			return confirmed, fmt.Errorf("failed to get block %s: %w", hash, err)
+		}
+		if block == nil || block.ConfirmationDetail == nil {
+			continue
+		}
+		if err := j.Append(&Entry{
+			Kind:           KindConfirm,
+			Hash:           hash,
+			MomentumHeight: block.ConfirmationDetail.MomentumHeight,
+		}); err != nil {
+			return confirmed, err
+		}
+		confirmed++
+	}
+	return confirmed, nil
+}
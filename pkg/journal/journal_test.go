@@ -0,0 +1,254 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/testutil"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+)
+
+func block(height uint64) *nom.AccountBlock {
+	b := &nom.AccountBlock{
+		Version:         1,
+		ChainIdentifier: 1,
+		BlockType:       nom.BlockTypeUserSend,
+		Height:          height,
+		Address:         testutil.ValidAddress,
+		ToAddress:       testutil.ValidAddress2,
+		TokenStandard:   types.ZnnTokenStandard,
+		Amount:          big.NewInt(1e8),
+	}
+	b.Hash = b.ComputeHash()
+	return b
+}
+
+func TestAppendAndVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.log")
+	recorder := &Recorder{Journal: &Journal{Path: path}, Command: "znn-cli send", Args: []string{"send", "z1...", "1", "ZNN"}}
+
+	require.NoError(t, recorder.Record(block(1), nil))
+	require.NoError(t, recorder.Record(block(2), errors.New("insufficient plasma")))
+
+	// A new journal continues the chain of the existing file
+	require.NoError(t, (&Journal{Path: path}).Append(&Entry{Kind: KindConfirm, Hash: block(1).Hash.String(), MomentumHeight: 10}))
+
+	entries, err := Read(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.NoError(t, Verify(entries))
+
+	assert.Equal(t, uint64(3), entries[2].Seq)
+	assert.True(t, entries[0].Published)
+	assert.False(t, entries[1].Published)
+	assert.Equal(t, "insufficient plasma", entries[1].Error)
+
+	var published nom.AccountBlock
+	require.NoError(t, json.Unmarshal(entries[0].Block, &published))
+	assert.Equal(t, block(1).Hash, published.Hash)
+
+	assert.Empty(t, Pending(entries))
+	assert.Equal(t, uint64(10), Confirmations(entries)[block(1).Hash.String()].MomentumHeight)
+}
+
+func TestAppendTwoWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.log")
+	a := &Journal{Path: path}
+	b := &Journal{Path: path}
+
+	require.NoError(t, a.Append(&Entry{Kind: KindConfirm, Hash: block(1).Hash.String()}))
+	require.NoError(t, b.Append(&Entry{Kind: KindConfirm, Hash: block(2).Hash.String()}))
+	// A continues after the entry B appended instead of its own last entry
+	require.NoError(t, a.Append(&Entry{Kind: KindConfirm, Hash: block(3).Hash.String()}))
+
+	var wg sync.WaitGroup
+	for _, j := range []*Journal{a, b} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				assert.NoError(t, j.Append(&Entry{Kind: KindConfirm, Hash: block(uint64(i)).Hash.String()}))
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries, err := Read(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 43)
+	require.NoError(t, Verify(entries))
+	assert.Equal(t, block(3).Hash.String(), entries[2].Hash)
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.log")
+	j := &Journal{Path: path}
+	for height := uint64(1); height <= 3; height++ {
+		require.NoError(t, (&Recorder{Journal: j, Command: "znn-cli send"}).Record(block(height), nil))
+	}
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.SplitAfter(strings.TrimSpace(string(data)), "\n")
+
+	tests := []struct {
+		name  string
+		lines []string
+		err   string
+	}{
+		{"edited", []string{lines[0], strings.Replace(lines[1], "znn-cli send", "znn-cli sweep", 1), lines[2]}, "entry 2: contents"},
+		{"removed", []string{lines[0], lines[2]}, "entry 2: expected sequence number 2"},
+		{"reordered", []string{lines[1], lines[0], lines[2]}, "entry 1: expected sequence number 1"},
+		{"truncated head", lines[1:], "entry 1: expected sequence number 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := filepath.Join(t.TempDir(), "journal.log")
+			require.NoError(t, os.WriteFile(tampered, []byte(strings.Join(tt.lines, "\n")+"\n"), 0600))
+			entries, err := Read(tampered)
+			require.NoError(t, err)
+			err = Verify(entries)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	j := &Journal{Path: filepath.Join(t.TempDir(), "journal.log")}
+	recorder := &Recorder{Journal: j}
+	require.NoError(t, recorder.Record(block(1), errors.New("timeout")))
+	require.NoError(t, recorder.Record(block(1), nil))
+	require.NoError(t, recorder.Record(block(2), nil))
+	entries, err := Read(j.Path)
+	require.NoError(t, err)
+
+	hash := block(1).Hash.String()
+	entry, confirmation, err := Find(entries, hash[:8])
+	require.NoError(t, err)
+	assert.True(t, entry.Published, "the latest attempt is returned")
+	assert.Nil(t, confirmation)
+
+	entry, _, err = Find(entries, "3")
+	require.NoError(t, err)
+	assert.Equal(t, block(2).Hash.String(), entry.Hash)
+
+	_, _, err = Find(entries, "ffffffffff")
+	assert.Error(t, err)
+	assert.Equal(t, []string{hash, block(2).Hash.String()}, Pending(entries))
+}
+
+func TestRedactArgs(t *testing.T) {
+	mnemonic := testutil.MnemonicFixture
+	args := []string{"send", "z1abc", "1", "ZNN", "-p", "hunter2", "--passphrase=hunter2", "-phunter2",
+		"--secret", "s3cret", "--keyStore", "main", mnemonic}
+	assert.Equal(t, []string{"send", "z1abc", "1", "ZNN", "-p", Redacted, "--passphrase=" + Redacted, "-p" + Redacted,
+		"--secret", Redacted, "--keyStore", "main", Redacted}, RedactArgs(args))
+}
+
+func TestRefresh(t *testing.T) {
+	node := testutil.NewNode(t)
+	c, err := client.New(t.Context(), node.URL)
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	ks, err := wallet.NewKeyStoreFromMnemonic(testutil.MnemonicFixture)
+	require.NoError(t, err)
+	keypair, err := ks.GetKeyPair(0)
+	require.NoError(t, err)
+	address, err := keypair.GetAddress()
+	require.NoError(t, err)
+	node.SetBalance(*address, types.ZnnTokenStandard, big.NewInt(1e8))
+	node.SetPlasma(*address, 21000)
+
+	j := &Journal{Path: filepath.Join(t.TempDir(), "journal.log")}
+	recorder := &Recorder{Journal: j, Command: "znn-cli send"}
+	transaction.SetPublishRecorder(func(block *nom.AccountBlock, err error) {
+		assert.NoError(t, recorder.Record(block, err))
+	})
+	defer transaction.SetPublishRecorder(nil)
+
+	template := c.LedgerApi.SendTemplate(testutil.ValidAddress, types.ZnnTokenStandard, big.NewInt(1e8), nil)
+	require.NoError(t, transaction.BuildAndSend(t.Context(), c.RpcClient, *address, template, keypair))
+
+	confirmed, err := Refresh(c.RpcClient, j)
+	require.NoError(t, err)
+	assert.Equal(t, 0, confirmed)
+
+	node.AddMomentums(1)
+	confirmed, err = Refresh(c.RpcClient, j)
+	require.NoError(t, err)
+	assert.Equal(t, 1, confirmed)
+
+	entries, err := Read(j.Path)
+	require.NoError(t, err)
+	require.NoError(t, Verify(entries))
+	_, confirmation, err := Find(entries, template.Hash.String())
+	require.NoError(t, err)
+	require.NotNil(t, confirmation)
+	assert.Equal(t, node.Height(), confirmation.MomentumHeight)
+}
+
+func TestSpent(t *testing.T) {
+	j := &Journal{Path: filepath.Join(t.TempDir(), "journal.log")}
+	recorder := &Recorder{Journal: j}
+	require.NoError(t, recorder.Record(block(1), nil))
+	require.NoError(t, recorder.Record(block(2), errors.New("insufficient plasma")))
+	require.NoError(t, recorder.Record(block(2), nil))
+	require.NoError(t, recorder.Record(block(2), nil))
+	entries, err := Read(j.Path)
+	require.NoError(t, err)
+
+	fromAddress := func(entry *Entry) bool { return entry.Address == testutil.ValidAddress.String() }
+	since := entries[0].Time.Add(-time.Minute)
+	spent, err := Spent(entries, fromAddress, types.ZnnTokenStandard, since)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(2e8), spent, "rejected and repeated blocks are not counted")
+
+	spent, err = Spent(entries, fromAddress, types.QsrTokenStandard, since)
+	require.NoError(t, err)
+	assert.Zero(t, spent.Sign())
+
+	spent, err = Spent(entries, fromAddress, types.ZnnTokenStandard, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, spent.Sign())
+}
+
+func TestSpentByKeyStore(t *testing.T) {
+	j := &Journal{Path: filepath.Join(t.TempDir(), "journal.log")}
+	keyStores := map[types.Address]string{testutil.ValidAddress: "bot", testutil.ValidAddress2: "bot"}
+	recorder := &Recorder{Journal: j, KeyStore: func(address types.Address) string { return keyStores[address] }}
+
+	other := block(2)
+	other.Address = testutil.ValidAddress2
+	other.Hash = other.ComputeHash()
+	outside := block(3)
+	outside.Address = testutil.ProducerAddress
+	outside.Hash = outside.ComputeHash()
+	require.NoError(t, recorder.Record(block(1), nil))
+	require.NoError(t, recorder.Record(other, nil))
+	require.NoError(t, recorder.Record(outside, nil))
+	entries, err := Read(j.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "bot", entries[1].KeyStore)
+	assert.Empty(t, entries[2].KeyStore)
+	require.NoError(t, Verify(entries))
+
+	spent, err := Spent(entries, func(entry *Entry) bool { return entry.KeyStore == "bot" },
+		types.ZnnTokenStandard, entries[0].Time.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(2e8), spent, "sends from every address of the keyStore are counted")
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package journal
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile blocks until it holds an exclusive lock on file
+func lockFile(file *os.File) error {
+	// #nosec G115 - File descriptors fit in an int
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+}
+
+// unlockFile releases the lock taken by lockFile
+func unlockFile(file *os.File) error {
+	// #nosec G115 - File descriptors fit in an int
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,127 @@
+// Package logging builds the structured logger of the CLI from the display
+// configuration.
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/0x3639/znn_cli_go/pkg/config"
+)
+
+// LevelTrace is below debug and used for JSON-RPC traffic
+const LevelTrace = slog.LevelDebug - 4
+
+// redacted replaces the value of redacted fields
+const redacted = "[redacted]"
+
+// ParseLevel parses a level name: trace, debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "trace":
+		return LevelTrace, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level %q: use trace, debug, info, warn or error", name)
+}
+
+// New creates a logger writing to the log file of cfg, or to stderr when
+// there is none. Verbose lowers the level to debug and TraceRPC to trace.
+// The returned closer closes the log file.
+func New(cfg config.DisplayConfig, stderr io.Writer) (*slog.Logger, io.Closer, error) {
+	level, err := ParseLevel(cfg.LogLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.Verbose && level > slog.LevelDebug {
+		level = slog.LevelDebug
+	}
+	if cfg.TraceRPC {
+		level = LevelTrace
+	}
+
+	out := stderr
+	var closer io.Closer = nopCloser{}
+	if cfg.LogFile != "" {
+		file := &lumberjack.Logger{
+			Filename:   cfg.LogFile,
+			MaxSize:    cfg.LogMaxSize,
+			MaxBackups: cfg.LogMaxBackups,
+		}
+		out, closer = file, file
+	}
+
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: levelNames}
+	switch strings.ToLower(cfg.LogFormat) {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(out, opts)), closer, nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(out, opts)), closer, nil
+	}
+	_ = closer.Close()
+	return nil, nil, fmt.Errorf("invalid log format %q: use text or json", cfg.LogFormat)
+}
+
+// levelNames names the trace level, which slog would print as DEBUG-4
+func levelNames(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) == 0 && attr.Key == slog.LevelKey {
+		if level, ok := attr.Value.Any().(slog.Level); ok && level == LevelTrace {
+			attr.Value = slog.StringValue("TRACE")
+		}
+	}
+	return attr
+}
+
+// Redact returns a JSON message with the value of every signature field
+// replaced, so that logs of signed blocks cannot be replayed. Messages that
+// are not JSON are reduced to their size.
+func Redact(data []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Sprintf("<%d bytes>", len(data))
+	}
+	out, err := json.Marshal(redact(value))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes>", len(data))
+	}
+	return string(out)
+}
+
+func redact(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if strings.EqualFold(key, "signature") {
+				if field != nil {
+					v[key] = redacted
+				}
+				continue
+			}
+			v[key] = redact(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redact(item)
+		}
+	}
+	return value
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
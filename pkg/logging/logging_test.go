@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/0x3639/znn_cli_go/pkg/config"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		level slog.Level
+	}{
+		{"trace", LevelTrace},
+		{"DEBUG", slog.LevelDebug},
+		{"", slog.LevelInfo},
+		{"warning", slog.LevelWarn},
+		{"error", slog.LevelError},
+	}
+	for _, tt := range tests {
+		level, err := ParseLevel(tt.name)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.level, level, tt.name)
+	}
+
+	_, err := ParseLevel("loud")
+	assert.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	var out bytes.Buffer
+	logger, closer, err := New(config.DisplayConfig{LogLevel: "warn", LogFormat: "json"}, &out)
+	require.NoError(t, err)
+	defer func() { _ = closer.Close() }()
+
+	logger.Info("hidden")
+	logger.Warn("shown", "n", 1)
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, "shown", entry["msg"])
+	assert.Equal(t, "WARN", entry["level"])
+
+	_, _, err = New(config.DisplayConfig{LogFormat: "xml"}, &out)
+	assert.Error(t, err)
+	_, _, err = New(config.DisplayConfig{LogLevel: "loud"}, &out)
+	assert.Error(t, err)
+}
+
+func TestNewLevels(t *testing.T) {
+	var out bytes.Buffer
+	logger, _, err := New(config.DisplayConfig{LogLevel: "warn", Verbose: true}, &out)
+	require.NoError(t, err)
+	assert.True(t, logger.Enabled(t.Context(), slog.LevelDebug), "verbose enables debug")
+	assert.False(t, logger.Enabled(t.Context(), LevelTrace))
+
+	logger, _, err = New(config.DisplayConfig{LogLevel: "error", TraceRPC: true}, &out)
+	require.NoError(t, err)
+	logger.Log(t.Context(), LevelTrace, "rpc request")
+	assert.Contains(t, out.String(), "level=TRACE")
+}
+
+func TestNewLogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cli.log")
+	logger, closer, err := New(config.DisplayConfig{LogLevel: "info", LogFile: path, LogMaxSize: 1}, nil)
+	require.NoError(t, err)
+	logger.Info("to file")
+	require.NoError(t, closer.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "msg=\"to file\"")
+}
+
+func TestRedact(t *testing.T) {
+	request := `{"jsonrpc":"2.0","id":3,"method":"ledger.publishRawTransaction","params":[{"amount":"100000000","signature":"c2lnbmF0dXJl","publicKey":"cHVi"}]}`
+	redacted := Redact([]byte(request))
+	assert.NotContains(t, redacted, "c2lnbmF0dXJl")
+	assert.Contains(t, redacted, `"signature":"[redacted]"`)
+	assert.Contains(t, redacted, `"amount":"100000000"`)
+	assert.Contains(t, redacted, `"id":3`)
+
+	assert.Contains(t, Redact([]byte(`{"result":{"signature":null}}`)), `"signature":null`)
+	assert.Equal(t, "<5 bytes>", Redact([]byte("hello")))
+}
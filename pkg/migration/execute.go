@@ -0,0 +1,176 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/0x3639/znn_cli_go/pkg/rewards"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+)
+
+var (
+	// ErrManualStep is returned for steps that must be performed by hand
+	ErrManualStep = errors.New("step must be performed manually")
+
+	// ErrNewKeyRequired is returned for steps signed by the new address when its key is not available
+	ErrNewKeyRequired = errors.New("step must be signed by the new address")
+)
+
+// Executor performs plan steps against a node
+type Executor struct {
+	Client *rpc_client.RpcClient
+	Plan   *Plan
+	OldKey *wallet.KeyPair
+	NewKey *wallet.KeyPair
+}
+
+// Execute performs a single step and returns the hashes of the published blocks
+func (e *Executor) Execute(ctx context.Context, step *Step) ([]string, error) {
+	source, err := types.ParseAddress(e.Plan.Source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source address in plan: %w", err)
+	}
+	target, err := types.ParseAddress(e.Plan.Target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target address in plan: %w", err)
+	}
+
+	if step.Kind == StepManual {
+		return nil, ErrManualStep
+	}
+	if step.Signer == SignerNew && e.NewKey == nil {
+		return nil, ErrNewKeyRequired
+	}
+
+	switch step.Kind {
+	case StepTransferOwnership:
+		zts, err := types.ParseZTS(step.Target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token standard: %w", err)
+		}
+		token, err := e.Client.TokenApi.GetByZts(zts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get token info: %w", err)
+		}
+		return e.send(ctx, source, e.OldKey, e.Client.TokenApi.UpdateToken(zts, target, token.IsMintable, token.IsBurnable))
+
+	case StepCollectRewards:
+		return e.collectRewards(ctx, source)
+
+	case StepRevokeStake:
+		id, err := types.HexToHash(step.Target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stake ID: %w", err)
+		}
+		return e.send(ctx, source, e.OldKey, e.Client.StakeApi.Cancel(id))
+
+	case StepCancelFusion:
+		id, err := types.HexToHash(step.Target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fusion ID: %w", err)
+		}
+		return e.send(ctx, source, e.OldKey, e.Client.PlasmaApi.Cancel(id))
+
+	case StepRevokePillar:
+		return e.send(ctx, source, e.OldKey, e.Client.PillarApi.Revoke())
+
+	case StepWithdrawPillarQsr:
+		return e.send(ctx, source, e.OldKey, e.Client.PillarApi.WithdrawQsr())
+
+	case StepRevokeSentinel:
+		return e.send(ctx, source, e.OldKey, e.Client.SentinelApi.Revoke())
+
+	case StepWithdrawSentinelQsr:
+		return e.send(ctx, source, e.OldKey, e.Client.SentinelApi.WithdrawQsr())
+
+	case StepTransfer:
+		return e.transfer(ctx, source, target)
+
+	case StepReceive:
+		var hashes []string
+		_, err := transaction.ReceiveAll(ctx, e.Client, target, e.NewKey, func(block *nom.AccountBlock) {
+			hashes = append(hashes, block.Hash.String())
+		})
+		return hashes, err
+
+	case StepDelegate:
+		return e.send(ctx, target, e.NewKey, e.Client.PillarApi.Delegate(step.Target))
+
+	case StepFuse:
+		beneficiary, err := types.ParseAddress(step.Target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid beneficiary address: %w", err)
+		}
+		amount, ok := new(big.Int).SetString(step.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid fuse amount: %s", step.Amount)
+		}
+		return e.send(ctx, target, e.NewKey, e.Client.PlasmaApi.Fuse(beneficiary, amount))
+
+	default:
+		return nil, fmt.Errorf("unknown step kind: %s", step.Kind)
+	}
+}
+
+// send builds, signs and publishes a single block
+func (e *Executor) send(ctx context.Context, address types.Address, keypair *wallet.KeyPair, template *nom.AccountBlock) ([]string, error) {
+	if err := transaction.BuildAndSend(ctx, e.Client, address, template, keypair); err != nil {
+		return nil, err
+	}
+	return []string{template.Hash.String()}, nil
+}
+
+// collectRewards collects every reward source with a non-zero uncollected balance
+func (e *Executor) collectRewards(ctx context.Context, source types.Address) ([]string, error) {
+	var hashes []string
+	collected, err := rewards.Collect(ctx, e.Client, source, e.OldKey, rewards.Sources)
+	for _, entry := range collected {
+		hashes = append(hashes, entry.Hash.String())
+	}
+	return hashes, err
+}
+
+// transfer receives pending blocks at source and sends every balance to target
+func (e *Executor) transfer(ctx context.Context, source, target types.Address) ([]string, error) {
+	var hashes []string
+
+	_, err := transaction.ReceiveAll(ctx, e.Client, source, e.OldKey, nil)
+	if err != nil {
+		return hashes, err
+	}
+
+	info, err := e.Client.LedgerApi.GetAccountInfoByAddress(source)
+	if err != nil {
+		return hashes, fmt.Errorf("failed to get account info: %w", err)
+	}
+
+	for zts, balanceInfo := range info.BalanceInfoMap {
+		if balanceInfo.Balance == nil || balanceInfo.Balance.Sign() == 0 {
+			continue
+		}
+
+		template := &nom.AccountBlock{
+			Version:         1,
+			ChainIdentifier: 1,
+			BlockType:       nom.BlockTypeUserSend,
+			ToAddress:       target,
+			Amount:          new(big.Int).Set(balanceInfo.Balance),
+			TokenStandard:   zts,
+			Data:            nil,
+		}
+		sent, err := e.send(ctx, source, e.OldKey, template)
+		hashes = append(hashes, sent...)
+		if err != nil {
+			return hashes, fmt.Errorf("failed to send %s: %w", zts, err)
+		}
+	}
+
+	return hashes, nil
+}
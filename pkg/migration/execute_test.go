@@ -0,0 +1,88 @@
+package migration
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm/constants"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/testutil"
+)
+
+// TestExecuteRefuse verifies the new address receives the transferred QSR
+// before it fuses it again
+func TestExecuteRefuse(t *testing.T) {
+	node := testutil.NewNode(t)
+	c, err := client.New(t.Context(), node.URL)
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	ks, err := wallet.NewKeyStoreFromMnemonic(testutil.MnemonicFixture)
+	require.NoError(t, err)
+	oldKey, err := ks.GetKeyPair(0)
+	require.NoError(t, err)
+	newKey, err := ks.GetKeyPair(1)
+	require.NoError(t, err)
+	source, err := oldKey.GetAddress()
+	require.NoError(t, err)
+	target, err := newKey.GetAddress()
+	require.NoError(t, err)
+
+	amount := new(big.Int).Set(constants.FuseMinAmount)
+	node.SetPlasma(*source, 10*constants.AccountBlockBasePlasma)
+	node.SetPlasma(*target, 10*constants.AccountBlockBasePlasma)
+	entry := &embedded.FusionEntry{
+		QsrAmount:        amount,
+		Beneficiary:      *source,
+		ExpirationHeight: node.Height(),
+		Id:               types.HexToHashPanic("0101010101010101010101010101010101010101010101010101010101010101"),
+	}
+	node.AddFusion(*source, entry)
+
+	now := time.Now()
+	plan := &Plan{Version: PlanVersion, CreatedAt: now, Source: source.String(), Target: target.String()}
+	addFusionSteps(plan, entry, *source, *target, now)
+	addTransfers(plan, now)
+	plan.Sort()
+
+	var kinds []StepKind
+	for _, step := range plan.Steps {
+		kinds = append(kinds, step.Kind)
+	}
+	assert.Equal(t, []StepKind{StepTransfer, StepCancelFusion, StepTransfer, StepReceive, StepFuse}, kinds)
+	assert.Equal(t, SignerNew, plan.Steps[3].Signer)
+
+	executor := &Executor{Client: c.RpcClient, Plan: plan, OldKey: oldKey, NewKey: newKey}
+	var received []string
+	for _, step := range plan.Steps {
+		hashes, err := executor.Execute(t.Context(), step)
+		require.NoError(t, err, "step %d (%s)", step.ID, step.Kind)
+		if step.Kind == StepReceive {
+			received = hashes
+		}
+	}
+
+	// The receive step records the receive blocks it published
+	var receiveBlocks []string
+	for _, block := range node.Blocks(*target) {
+		if nom.IsReceiveBlock(block.BlockType) {
+			receiveBlocks = append(receiveBlocks, block.Hash.String())
+		}
+	}
+	require.NotEmpty(t, received)
+	assert.Equal(t, receiveBlocks, received)
+
+	fusions := node.Fusions(*target)
+	require.Len(t, fusions, 1)
+	assert.Equal(t, amount, fusions[0].QsrAmount)
+	assert.Equal(t, *target, fusions[0].Beneficiary)
+	assert.Empty(t, node.Fusions(*source))
+}
@@ -0,0 +1,428 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/rewards"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+)
+
+// pageSize is the page size used when listing entries during inspection
+const pageSize = 50
+
+// releaseKinds are the steps that return funds to the old address
+var releaseKinds = map[StepKind]bool{
+	StepCollectRewards:      true,
+	StepRevokeStake:         true,
+	StepCancelFusion:        true,
+	StepRevokePillar:        true,
+	StepWithdrawPillarQsr:   true,
+	StepRevokeSentinel:      true,
+	StepWithdrawSentinelQsr: true,
+}
+
+// Inspect queries the stakes, fusions, delegation, tokens, pillars and sentinels
+// of the source address and builds an ordered plan that moves them to target.
+//
+// Parameters:
+//   - c: RPC client for querying the embedded contracts
+//   - source: Address being migrated
+//   - target: New address
+//   - now: Time used as the earliest time for immediate steps
+//
+// Returns the sorted plan or an error if any query fails.
+func Inspect(c *client.Client, source, target types.Address, now time.Time) (*Plan, error) {
+	if source == target {
+		return nil, fmt.Errorf("source and target address are the same")
+	}
+
+	momentum, err := c.LedgerApi.GetFrontierMomentum()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frontier momentum: %w", err)
+	}
+
+	plan := &Plan{
+		Version:   PlanVersion,
+		CreatedAt: now,
+		Source:    source.String(),
+		Target:    target.String(),
+	}
+
+	if err := inspectTokens(c, plan, source, now); err != nil {
+		return nil, err
+	}
+	if err := inspectRewards(c, plan, source, now); err != nil {
+		return nil, err
+	}
+	if err := inspectStakes(c, plan, source, now); err != nil {
+		return nil, err
+	}
+	if err := inspectFusions(c, plan, source, target, now, momentum.Height); err != nil {
+		return nil, err
+	}
+	if err := inspectPillars(c, plan, source, now); err != nil {
+		return nil, err
+	}
+	if err := inspectSentinel(c, plan, source, now); err != nil {
+		return nil, err
+	}
+	if err := inspectDelegation(c, plan, source, now); err != nil {
+		return nil, err
+	}
+
+	addTransfers(plan, now)
+	plan.Sort()
+
+	return plan, nil
+}
+
+// inspectTokens adds an ownership transfer for every token owned by source
+func inspectTokens(c *client.Client, plan *Plan, source types.Address, now time.Time) error {
+	for pageIndex := uint32(0); ; pageIndex++ {
+		tokens, err := c.TokenApi.GetByOwner(source, pageIndex, pageSize)
+		if err != nil {
+			return fmt.Errorf("failed to get owned tokens: %w", err)
+		}
+
+		for _, token := range tokens.List {
+			plan.AddStep(&Step{
+				Kind:        StepTransferOwnership,
+				Signer:      SignerOld,
+				Description: fmt.Sprintf("Transfer ownership of %s (%s)", token.TokenSymbol, token.ZenonTokenStandard),
+				Target:      token.ZenonTokenStandard.String(),
+				NotBefore:   now,
+			})
+		}
+
+		if len(tokens.List) < pageSize {
+			return nil
+		}
+	}
+}
+
+// inspectRewards adds a collect step when any reward source has uncollected rewards
+func inspectRewards(c *client.Client, plan *Plan, source types.Address, now time.Time) error {
+	uncollected, err := rewards.UncollectedAll(c.RpcClient, source, rewards.Sources)
+	if err != nil {
+		return err
+	}
+
+	znn, qsr := rewards.Total(uncollected)
+	if znn.Sign() == 0 && qsr.Sign() == 0 {
+		return nil
+	}
+
+	plan.AddStep(&Step{
+		Kind:   StepCollectRewards,
+		Signer: SignerOld,
+		Description: fmt.Sprintf("Collect rewards (%s ZNN, %s QSR)",
+			format.Amount(znn, format.CoinDecimals), format.Amount(qsr, format.CoinDecimals)),
+		NotBefore: now,
+	})
+	return nil
+}
+
+// inspectStakes adds a revoke step for every stake entry at its expiration time
+func inspectStakes(c *client.Client, plan *Plan, source types.Address, now time.Time) error {
+	count := 0
+	for pageIndex := uint32(0); ; pageIndex++ {
+		stakes, err := c.StakeApi.GetEntriesByAddress(source, pageIndex, pageSize)
+		if err != nil {
+			return fmt.Errorf("failed to get stake entries: %w", err)
+		}
+
+		for _, entry := range stakes.Entries {
+			plan.AddStep(&Step{
+				Kind:        StepRevokeStake,
+				Signer:      SignerOld,
+				Description: fmt.Sprintf("Revoke stake of %s ZNN", format.Amount(entry.Amount, format.CoinDecimals)),
+				Target:      entry.Id.String(),
+				Amount:      entry.Amount.String(),
+				NotBefore:   latest(now, time.Unix(entry.ExpirationTimestamp, 0)),
+			})
+		}
+
+		count += len(stakes.Entries)
+		if len(stakes.Entries) == 0 || count >= stakes.Count {
+			break
+		}
+	}
+
+	// Stake rewards keep accruing until the last entry is revoked
+	if count > 0 {
+		addFinalCollect(plan, StepRevokeStake)
+	}
+	return nil
+}
+
+// inspectFusions adds a cancel step for every fusion entry and re-fuses from
+// the new address when the old address was its own beneficiary
+func inspectFusions(c *client.Client, plan *Plan, source, target types.Address, now time.Time, height uint64) error {
+	count := 0
+	for pageIndex := uint32(0); ; pageIndex++ {
+		fusions, err := c.PlasmaApi.GetEntriesByAddress(source, pageIndex, pageSize)
+		if err != nil {
+			return fmt.Errorf("failed to get fusion entries: %w", err)
+		}
+
+		for _, entry := range fusions.Fusions {
+			addFusionSteps(plan, entry, source, target, EstimateHeightTime(now, height, entry.ExpirationHeight))
+		}
+
+		count += len(fusions.Fusions)
+		if len(fusions.Fusions) == 0 || count >= fusions.Count {
+			return nil
+		}
+	}
+}
+
+// addFusionSteps adds the cancellation of a fusion entry at cancelAt and its
+// re-fusion from the new address once the released QSR has arrived there
+func addFusionSteps(plan *Plan, entry *embedded.FusionEntry, source, target types.Address, cancelAt time.Time) {
+	plan.AddStep(&Step{
+		Kind:   StepCancelFusion,
+		Signer: SignerOld,
+		Description: fmt.Sprintf("Cancel fusion of %s QSR for %s",
+			format.Amount(entry.QsrAmount, format.CoinDecimals), entry.Beneficiary),
+		Target:          entry.Id.String(),
+		Amount:          entry.QsrAmount.String(),
+		NotBefore:       cancelAt,
+		NotBeforeHeight: entry.ExpirationHeight,
+	})
+
+	beneficiary := entry.Beneficiary
+	if beneficiary == source {
+		beneficiary = target
+	}
+	addReceive(plan, cancelAt.Add(2*ReceiveDelay), entry.ExpirationHeight)
+	plan.AddStep(&Step{
+		Kind:   StepFuse,
+		Signer: SignerNew,
+		Description: fmt.Sprintf("Fuse %s QSR for %s from the new address",
+			format.Amount(entry.QsrAmount, format.CoinDecimals), beneficiary),
+		Target:          beneficiary.String(),
+		Amount:          entry.QsrAmount.String(),
+		NotBefore:       cancelAt.Add(2 * ReceiveDelay),
+		NotBeforeHeight: entry.ExpirationHeight,
+	})
+}
+
+// inspectPillars adds revoke and withdraw steps for pillars owned by source
+func inspectPillars(c *client.Client, plan *Plan, source types.Address, now time.Time) error {
+	pillars, err := c.PillarApi.GetByOwner(source)
+	if err != nil {
+		return fmt.Errorf("failed to get owned pillars: %w", err)
+	}
+
+	for _, pillar := range pillars {
+		revokeAt := now
+		if !pillar.CanBeRevoked {
+			revokeAt = now.Add(time.Duration(pillar.RevokeCooldown) * time.Second)
+		}
+
+		plan.AddStep(&Step{
+			Kind:        StepRevokePillar,
+			Signer:      SignerOld,
+			Description: fmt.Sprintf("Revoke pillar %s", pillar.Name),
+			Target:      pillar.Name,
+			NotBefore:   revokeAt,
+		})
+		plan.AddStep(&Step{
+			Kind:        StepWithdrawPillarQsr,
+			Signer:      SignerOld,
+			Description: "Withdraw QSR deposited in the pillar contract",
+			NotBefore:   revokeAt,
+		})
+		plan.AddStep(&Step{
+			Kind:   StepManual,
+			Signer: SignerNew,
+			Description: fmt.Sprintf("Register pillar %s from the new address (znn-cli pillar register %s <producer> <reward>)",
+				pillar.Name, pillar.Name),
+			Target:    pillar.Name,
+			NotBefore: revokeAt.Add(2 * ReceiveDelay),
+		})
+	}
+
+	if len(pillars) > 0 {
+		addFinalCollect(plan, StepRevokePillar)
+		return nil
+	}
+
+	// QSR may still be deposited without a registered pillar
+	deposited, err := c.PillarDepositedQsr(source)
+	if err == nil && deposited != nil && deposited.Sign() > 0 {
+		plan.AddStep(&Step{
+			Kind:        StepWithdrawPillarQsr,
+			Signer:      SignerOld,
+			Description: fmt.Sprintf("Withdraw %s QSR deposited in the pillar contract", format.Amount(deposited, format.CoinDecimals)),
+			NotBefore:   now,
+		})
+	}
+	return nil
+}
+
+// inspectSentinel adds revoke and withdraw steps for a sentinel owned by source
+func inspectSentinel(c *client.Client, plan *Plan, source types.Address, now time.Time) error {
+	sentinel, err := c.SentinelApi.GetByOwner(source)
+	if err != nil {
+		return fmt.Errorf("failed to get sentinel: %w", err)
+	}
+
+	if sentinel != nil && sentinel.Active {
+		revokeAt := now
+		if !sentinel.CanBeRevoked {
+			revokeAt = now.Add(time.Duration(sentinel.RevokeCooldown) * time.Second)
+		}
+
+		plan.AddStep(&Step{
+			Kind:        StepRevokeSentinel,
+			Signer:      SignerOld,
+			Description: "Revoke sentinel",
+			NotBefore:   revokeAt,
+		})
+		plan.AddStep(&Step{
+			Kind:        StepWithdrawSentinelQsr,
+			Signer:      SignerOld,
+			Description: "Withdraw QSR deposited in the sentinel contract",
+			NotBefore:   revokeAt,
+		})
+		plan.AddStep(&Step{
+			Kind:        StepManual,
+			Signer:      SignerNew,
+			Description: "Register a sentinel from the new address (znn-cli sentinel register)",
+			NotBefore:   revokeAt.Add(2 * ReceiveDelay),
+		})
+		addFinalCollect(plan, StepRevokeSentinel)
+		return nil
+	}
+
+	deposited, err := c.SentinelDepositedQsr(source)
+	if err == nil && deposited != nil && deposited.Sign() > 0 {
+		plan.AddStep(&Step{
+			Kind:        StepWithdrawSentinelQsr,
+			Signer:      SignerOld,
+			Description: fmt.Sprintf("Withdraw %s QSR deposited in the sentinel contract", format.Amount(deposited, format.CoinDecimals)),
+			NotBefore:   now,
+		})
+	}
+	return nil
+}
+
+// inspectDelegation adds a delegate step for the new address
+func inspectDelegation(c *client.Client, plan *Plan, source types.Address, now time.Time) error {
+	delegation, err := c.PillarApi.GetDelegatedPillar(source)
+	if err != nil {
+		return fmt.Errorf("failed to get delegated pillar: %w", err)
+	}
+
+	if delegation == nil || delegation.Name == "" {
+		return nil
+	}
+
+	addReceive(plan, now.Add(ReceiveDelay), 0)
+	plan.AddStep(&Step{
+		Kind:        StepDelegate,
+		Signer:      SignerNew,
+		Description: fmt.Sprintf("Delegate the new address to pillar %s", delegation.Name),
+		Target:      delegation.Name,
+		NotBefore:   now.Add(ReceiveDelay),
+	})
+	return nil
+}
+
+// addReceive adds a receive at the new address for the steps it signs at the
+// given time, so that funds transferred earlier are available to them
+func addReceive(plan *Plan, at time.Time, height uint64) {
+	for _, step := range plan.Steps {
+		if step.Kind == StepReceive && step.NotBefore.Equal(at) && step.NotBeforeHeight == height {
+			return
+		}
+	}
+
+	plan.AddStep(&Step{
+		Kind:            StepReceive,
+		Signer:          SignerNew,
+		Description:     "Receive pending blocks at the new address",
+		NotBefore:       at,
+		NotBeforeHeight: height,
+	})
+}
+
+// addFinalCollect adds a reward collection at the time of the last step of the given kind
+func addFinalCollect(plan *Plan, kind StepKind) {
+	var last *Step
+	for _, step := range plan.Steps {
+		if step.Kind == kind && (last == nil || step.NotBefore.After(last.NotBefore)) {
+			last = step
+		}
+	}
+	if last == nil {
+		return
+	}
+
+	for _, step := range plan.Steps {
+		if step.Kind == StepCollectRewards && step.NotBefore.Equal(last.NotBefore) {
+			return
+		}
+	}
+
+	plan.AddStep(&Step{
+		Kind:        StepCollectRewards,
+		Signer:      SignerOld,
+		Description: "Collect remaining rewards",
+		NotBefore:   last.NotBefore,
+	})
+}
+
+// addTransfers adds an immediate transfer of liquid funds plus one transfer
+// after every point in time at which a step releases funds to the old address
+func addTransfers(plan *Plan, now time.Time) {
+	type point struct {
+		at     time.Time
+		height uint64
+	}
+
+	points := []point{{at: now}}
+	seen := map[point]bool{{at: now}: true}
+	for _, step := range plan.Steps {
+		if !releaseKinds[step.Kind] {
+			continue
+		}
+		p := point{at: step.NotBefore.Add(ReceiveDelay), height: step.NotBeforeHeight}
+		if !seen[p] {
+			seen[p] = true
+			points = append(points, p)
+		}
+	}
+
+	for _, p := range points {
+		plan.AddStep(&Step{
+			Kind:            StepTransfer,
+			Signer:          SignerOld,
+			Description:     "Receive pending blocks and send all balances to the new address",
+			NotBefore:       p.at,
+			NotBeforeHeight: p.height,
+		})
+	}
+}
+
+// EstimateHeightTime estimates when the given momentum height will be reached
+func EstimateHeightTime(now time.Time, currentHeight, height uint64) time.Time {
+	if height <= currentHeight {
+		return now
+	}
+	// #nosec G115 - Height differences are far below the int64 range
+	return now.Add(time.Duration(height-currentHeight) * MomentumInterval)
+}
+
+// latest returns the later of two times
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
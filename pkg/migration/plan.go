@@ -0,0 +1,252 @@
+// Package migration plans and tracks the move of a long-lived account to a new key.
+// A plan is an ordered list of steps, each with the earliest time (and momentum
+// height) at which it can be performed. Plans are stored as JSON so that
+// execution can be resumed across runs.
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// PlanVersion is the current plan file format version
+	PlanVersion = 1
+
+	// MomentumInterval is the expected time between momentums, used for estimates
+	MomentumInterval = 10 * time.Second
+
+	// ReceiveDelay is the time to wait after a contract call before its
+	// resulting blocks can be received
+	ReceiveDelay = time.Minute
+)
+
+// StepKind identifies the action performed by a step
+type StepKind string
+
+const (
+	// StepTransferOwnership transfers ownership of a token to the new address
+	StepTransferOwnership StepKind = "transferOwnership"
+	// StepCollectRewards collects uncollected stake, pillar, sentinel and liquidity rewards
+	StepCollectRewards StepKind = "collectRewards"
+	// StepRevokeStake revokes an expired stake entry
+	StepRevokeStake StepKind = "revokeStake"
+	// StepCancelFusion cancels a plasma fusion entry
+	StepCancelFusion StepKind = "cancelFusion"
+	// StepRevokePillar revokes the pillar owned by the old address
+	StepRevokePillar StepKind = "revokePillar"
+	// StepWithdrawPillarQsr withdraws QSR deposited in the pillar contract
+	StepWithdrawPillarQsr StepKind = "withdrawPillarQsr"
+	// StepRevokeSentinel revokes the sentinel owned by the old address
+	StepRevokeSentinel StepKind = "revokeSentinel"
+	// StepWithdrawSentinelQsr withdraws QSR deposited in the sentinel contract
+	StepWithdrawSentinelQsr StepKind = "withdrawSentinelQsr"
+	// StepTransfer receives pending blocks and sends all balances to the new address
+	StepTransfer StepKind = "transfer"
+	// StepReceive receives pending blocks at the new address
+	StepReceive StepKind = "receive"
+	// StepDelegate delegates the new address to a pillar
+	StepDelegate StepKind = "delegate"
+	// StepFuse fuses QSR from the new address for a beneficiary
+	StepFuse StepKind = "fuse"
+	// StepManual is an action the user must perform by hand
+	StepManual StepKind = "manual"
+)
+
+// kindOrder sorts steps that share the same earliest time
+var kindOrder = map[StepKind]int{
+	StepTransferOwnership:   0,
+	StepCollectRewards:      1,
+	StepRevokeStake:         2,
+	StepCancelFusion:        2,
+	StepRevokePillar:        2,
+	StepRevokeSentinel:      2,
+	StepWithdrawPillarQsr:   3,
+	StepWithdrawSentinelQsr: 3,
+	StepTransfer:            4,
+	StepReceive:             5,
+	StepDelegate:            6,
+	StepFuse:                6,
+	StepManual:              7,
+}
+
+// Signer identifies which key signs a step
+type Signer string
+
+const (
+	// SignerOld marks steps signed by the address being migrated
+	SignerOld Signer = "old"
+	// SignerNew marks steps signed by the new address
+	SignerNew Signer = "new"
+)
+
+// Status is the progress state of a step
+type Status string
+
+const (
+	// StatusPending marks a step that has not been performed yet
+	StatusPending Status = "pending"
+	// StatusDone marks a completed step
+	StatusDone Status = "done"
+	// StatusFailed marks a step whose last attempt failed
+	StatusFailed Status = "failed"
+)
+
+// Step is a single action of a migration plan
+type Step struct {
+	ID              int        `json:"id"`
+	Kind            StepKind   `json:"kind"`
+	Signer          Signer     `json:"signer"`
+	Description     string     `json:"description"`
+	Target          string     `json:"target,omitempty"`
+	Amount          string     `json:"amount,omitempty"`
+	NotBefore       time.Time  `json:"notBefore"`
+	NotBeforeHeight uint64     `json:"notBeforeHeight,omitempty"`
+	Status          Status     `json:"status"`
+	Hashes          []string   `json:"hashes,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	CompletedAt     *time.Time `json:"completedAt,omitempty"`
+}
+
+// IsDue reports whether the step can be performed at the given time and momentum height
+func (s *Step) IsDue(now time.Time, height uint64) bool {
+	return !now.Before(s.NotBefore) && height >= s.NotBeforeHeight
+}
+
+// Plan is an ordered, resumable list of migration steps
+type Plan struct {
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"createdAt"`
+	KeyStore    string    `json:"keyStore,omitempty"`
+	Source      string    `json:"source"`
+	SourceIndex int       `json:"sourceIndex"`
+	Target      string    `json:"target"`
+	TargetIndex *int      `json:"targetIndex,omitempty"`
+	Steps       []*Step   `json:"steps"`
+}
+
+// AddStep appends a pending step to the plan
+func (p *Plan) AddStep(step *Step) {
+	step.Status = StatusPending
+	p.Steps = append(p.Steps, step)
+}
+
+// Sort orders steps by earliest time, then momentum height, then kind,
+// and renumbers them from 1.
+func (p *Plan) Sort() {
+	sort.SliceStable(p.Steps, func(i, j int) bool {
+		a, b := p.Steps[i], p.Steps[j]
+		if !a.NotBefore.Equal(b.NotBefore) {
+			return a.NotBefore.Before(b.NotBefore)
+		}
+		if a.NotBeforeHeight != b.NotBeforeHeight {
+			return a.NotBeforeHeight < b.NotBeforeHeight
+		}
+		return kindOrder[a.Kind] < kindOrder[b.Kind]
+	})
+	for i, step := range p.Steps {
+		step.ID = i + 1
+	}
+}
+
+// Step returns the step with the given ID
+func (p *Plan) Step(id int) (*Step, bool) {
+	for _, step := range p.Steps {
+		if step.ID == id {
+			return step, true
+		}
+	}
+	return nil, false
+}
+
+// Due returns the pending steps that can be performed now. Steps are returned
+// in plan order up to the first pending step that is not yet due, so that
+// later steps never run ahead of the steps they depend on.
+func (p *Plan) Due(now time.Time, height uint64) []*Step {
+	var due []*Step
+	for _, step := range p.Steps {
+		if step.Status == StatusDone {
+			continue
+		}
+		if !step.IsDue(now, height) {
+			break
+		}
+		due = append(due, step)
+	}
+	return due
+}
+
+// Remaining returns the number of steps that are not done
+func (p *Plan) Remaining() int {
+	remaining := 0
+	for _, step := range p.Steps {
+		if step.Status != StatusDone {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+// MarkDone records a step as completed
+func (s *Step) MarkDone(now time.Time, hashes ...string) {
+	s.Status = StatusDone
+	s.Error = ""
+	s.Hashes = append(s.Hashes, hashes...)
+	completed := now
+	s.CompletedAt = &completed
+}
+
+// MarkFailed records a failed attempt of a step
+func (s *Step) MarkFailed(err error, hashes ...string) {
+	s.Status = StatusFailed
+	s.Error = err.Error()
+	s.Hashes = append(s.Hashes, hashes...)
+}
+
+// Load reads a plan from a JSON file
+func Load(path string) (*Plan, error) {
+	// #nosec G304 - Path is provided by the user on the command line
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %w", err)
+	}
+
+	if plan.Version != PlanVersion {
+		return nil, fmt.Errorf("unsupported plan version %d", plan.Version)
+	}
+
+	return &plan, nil
+}
+
+// Save writes the plan to a JSON file, replacing it atomically
+func (p *Plan) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create plan directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write plan file: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write plan file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package migration
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPlanSort verifies steps are ordered by time, height and kind, then renumbered
+func TestPlanSort(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	plan := &Plan{}
+	plan.AddStep(&Step{Kind: StepTransfer, NotBefore: now})
+	plan.AddStep(&Step{Kind: StepCancelFusion, NotBefore: now.Add(time.Hour), NotBeforeHeight: 200})
+	plan.AddStep(&Step{Kind: StepCancelFusion, NotBefore: now.Add(time.Hour), NotBeforeHeight: 100})
+	plan.AddStep(&Step{Kind: StepCollectRewards, NotBefore: now})
+	plan.AddStep(&Step{Kind: StepTransferOwnership, NotBefore: now})
+	plan.Sort()
+
+	kinds := make([]StepKind, len(plan.Steps))
+	for i, step := range plan.Steps {
+		assert.Equal(t, i+1, step.ID)
+		assert.Equal(t, StatusPending, step.Status)
+		kinds[i] = step.Kind
+	}
+	assert.Equal(t, []StepKind{
+		StepTransferOwnership,
+		StepCollectRewards,
+		StepTransfer,
+		StepCancelFusion,
+		StepCancelFusion,
+	}, kinds)
+	assert.Equal(t, uint64(100), plan.Steps[3].NotBeforeHeight)
+}
+
+// TestPlanDue verifies due steps stop at the first pending step that is not yet due
+func TestPlanDue(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	plan := &Plan{}
+	plan.AddStep(&Step{Kind: StepCollectRewards, NotBefore: now})
+	plan.AddStep(&Step{Kind: StepTransfer, NotBefore: now})
+	plan.AddStep(&Step{Kind: StepCancelFusion, NotBefore: now, NotBeforeHeight: 500})
+	plan.AddStep(&Step{Kind: StepTransfer, NotBefore: now.Add(time.Minute)})
+	plan.Sort()
+
+	plan.Steps[0].MarkDone(now, "hash")
+
+	due := plan.Due(now, 100)
+	require.Len(t, due, 1)
+	assert.Equal(t, 2, due[0].ID)
+
+	due = plan.Due(now, 500)
+	require.Len(t, due, 2)
+
+	due = plan.Due(now.Add(time.Minute), 500)
+	require.Len(t, due, 3)
+
+	plan.Steps[1].MarkFailed(errors.New("boom"))
+	due = plan.Due(now, 100)
+	require.Len(t, due, 1)
+	assert.Equal(t, StatusFailed, due[0].Status)
+	assert.Equal(t, 3, plan.Remaining())
+}
+
+// TestPlanSaveLoad verifies a plan survives a round trip through its file
+func TestPlanSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	targetIndex := 3
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	plan := &Plan{
+		Version:     PlanVersion,
+		CreatedAt:   now,
+		KeyStore:    "main",
+		Source:      "z1source",
+		Target:      "z1target",
+		TargetIndex: &targetIndex,
+	}
+	plan.AddStep(&Step{Kind: StepFuse, Signer: SignerNew, Target: "z1target", Amount: "1000", NotBefore: now})
+	plan.Sort()
+	plan.Steps[0].MarkDone(now, "abc")
+
+	require.NoError(t, plan.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, plan.Source, loaded.Source)
+	require.NotNil(t, loaded.TargetIndex)
+	assert.Equal(t, targetIndex, *loaded.TargetIndex)
+	require.Len(t, loaded.Steps, 1)
+	assert.Equal(t, StatusDone, loaded.Steps[0].Status)
+	assert.Equal(t, []string{"abc"}, loaded.Steps[0].Hashes)
+	assert.Equal(t, "1000", loaded.Steps[0].Amount)
+
+	plan.Version = PlanVersion + 1
+	require.NoError(t, plan.Save(path))
+	_, err = Load(path)
+	assert.Error(t, err)
+}
+
+// TestEstimateHeightTime verifies momentum height time estimates
+func TestEstimateHeightTime(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, now, EstimateHeightTime(now, 100, 50))
+	assert.Equal(t, now, EstimateHeightTime(now, 100, 100))
+	assert.Equal(t, now.Add(60*MomentumInterval), EstimateHeightTime(now, 100, 160))
+}
@@ -0,0 +1,139 @@
+package pillarmon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultHistorySize is the number of samples kept in the history file
+const DefaultHistorySize = 1000
+
+// Sample is a pillar's state at one point in time
+type Sample struct {
+	Time     time.Time `json:"time"`
+	Momentum uint64    `json:"momentum"`
+	Owner    string    `json:"owner"`
+	Rank     int       `json:"rank"`
+	Produced uint64    `json:"produced"`
+	Expected uint64    `json:"expected"`
+	// Weight is the delegated weight in base units
+	Weight string `json:"weight"`
+}
+
+// Ratio returns the produced/expected ratio, or 1 when nothing was expected
+func (s Sample) Ratio() float64 {
+	if s.Expected == 0 {
+		return 1
+	}
+	return float64(s.Produced) / float64(s.Expected)
+}
+
+// WeightInt returns the weight as an integer, or zero if it is invalid
+func (s Sample) WeightInt() *big.Int {
+	weight, ok := new(big.Int).SetString(s.Weight, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return weight
+}
+
+// History is the stored samples and alert state of one pillar
+type History struct {
+	Pillar  string   `json:"pillar"`
+	Samples []Sample `json:"samples"`
+
+	// LowProduction is set while a low production alert is active
+	LowProduction bool `json:"lowProduction"`
+	// WeightAlertTime is the time of the last weight drop alert
+	WeightAlertTime time.Time `json:"weightAlertTime"`
+}
+
+// Last returns the most recent sample, or nil if there is none
+func (h *History) Last() *Sample {
+	if len(h.Samples) == 0 {
+		return nil
+	}
+	return &h.Samples[len(h.Samples)-1]
+}
+
+// Add appends a sample, keeping at most size samples
+func (h *History) Add(sample Sample, size int) {
+	h.Samples = append(h.Samples, sample)
+	if size > 0 && len(h.Samples) > size {
+		h.Samples = append([]Sample(nil), h.Samples[len(h.Samples)-size:]...)
+	}
+}
+
+// PeakWeight returns the highest weight of the samples taken after since,
+// or nil if there are none
+func (h *History) PeakWeight(since time.Time) *big.Int {
+	var peak *big.Int
+	for _, sample := range h.Samples {
+		if sample.Time.Before(since) {
+			continue
+		}
+		weight := sample.WeightInt()
+		if peak == nil || weight.Cmp(peak) > 0 {
+			peak = weight
+		}
+	}
+	return peak
+}
+
+// HistoryPath returns the default history file of a pillar (~/.znn/pillar-monitor/<name>.json)
+func HistoryPath(pillar string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".znn", "pillar-monitor", filepath.Base(pillar)+".json"), nil
+}
+
+// LoadHistory reads a history file, returning an empty history if it does not exist
+func LoadHistory(path, pillar string) (*History, error) {
+	// #nosec G304 - Path is provided by the user or the default config directory
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &History{Pillar: pillar}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pillar history: %w", err)
+	}
+
+	var history History
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse pillar history: %w", err)
+	}
+	if history.Pillar != pillar {
+		return nil, fmt.Errorf("history file %s belongs to pillar %s", path, history.Pillar)
+	}
+
+	return &history, nil
+}
+
+// Save writes the history file, replacing it atomically
+func (h *History) Save(path string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode pillar history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create pillar history directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write pillar history: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write pillar history: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+// Package pillarmon tracks a pillar's epoch statistics and weight over time
+// and raises alerts when momentum production or delegation drops.
+package pillarmon
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/watch"
+)
+
+const (
+	// EventLowProduction is raised when the production ratio falls below the threshold
+	EventLowProduction watch.EventType = "pillarLowProduction"
+	// EventWeightDrop is raised when the weight drops sharply within the window
+	EventWeightDrop watch.EventType = "pillarWeightDrop"
+	// EventDelegatorsLeft is raised when the weight decreases between two samples
+	EventDelegatorsLeft watch.EventType = "pillarDelegatorsLeft"
+
+	// weightDecimals is the number of decimals of the pillar weight (ZNN)
+	weightDecimals = 8
+)
+
+// Thresholds configures when alerts are raised
+type Thresholds struct {
+	// MinRatio is the minimum produced/expected ratio, between 0 and 1
+	MinRatio float64
+	// MinExpected is the number of expected momentums required before the
+	// ratio is checked, to avoid alerts at the start of an epoch
+	MinExpected uint64
+	// MaxWeightDrop is the weight drop within Window, in percent, that raises an alert
+	MaxWeightDrop float64
+	// Window is the period over which weight drops are measured
+	Window time.Duration
+	// MinDeparture is the weight decrease between samples, in base units,
+	// that is reported as delegators leaving; nil disables the check
+	MinDeparture *big.Int
+}
+
+// DefaultThresholds returns the default alert thresholds
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		MinRatio:      0.9,
+		MinExpected:   10,
+		MaxWeightDrop: 10,
+		Window:        time.Hour,
+		MinDeparture:  big.NewInt(100 * 1e8),
+	}
+}
+
+// Validate checks that the thresholds are usable
+func (t Thresholds) Validate() error {
+	if t.MinRatio < 0 || t.MinRatio > 1 {
+		return fmt.Errorf("minimum ratio must be between 0 and 1")
+	}
+	if t.MaxWeightDrop <= 0 || t.MaxWeightDrop > 100 {
+		return fmt.Errorf("weight drop must be greater than 0 and at most 100 percent")
+	}
+	if t.Window <= 0 {
+		return fmt.Errorf("weight drop window must be positive")
+	}
+	return nil
+}
+
+// Check compares a new sample against the history and returns the alerts it
+// raises. It updates the alert state in the history but does not add the sample.
+func Check(history *History, sample Sample, thresholds Thresholds) []watch.Event {
+	var alerts []watch.Event
+	previous := history.Last()
+
+	// A decreasing expected count means a new epoch started
+	if previous != nil && sample.Expected < previous.Expected {
+		history.LowProduction = false
+	}
+
+	if sample.Expected >= thresholds.MinExpected && sample.Expected > 0 {
+		ratio := sample.Ratio()
+		switch {
+		case ratio < thresholds.MinRatio && !history.LowProduction:
+			history.LowProduction = true
+			alerts = append(alerts, newAlert(history.Pillar, EventLowProduction, sample,
+				fmt.Sprintf("pillar %s produced %d of %d expected momentums (%.2f%%, threshold %.2f%%)",
+					history.Pillar, sample.Produced, sample.Expected, ratio*100, thresholds.MinRatio*100)))
+		case ratio >= thresholds.MinRatio:
+			history.LowProduction = false
+		}
+	}
+
+	weight := sample.WeightInt()
+	if previous != nil && thresholds.MinDeparture != nil && thresholds.MinDeparture.Sign() > 0 {
+		decrease := new(big.Int).Sub(previous.WeightInt(), weight)
+		if decrease.Cmp(thresholds.MinDeparture) >= 0 {
+			alert := newAlert(history.Pillar, EventDelegatorsLeft, sample,
+				fmt.Sprintf("pillar %s lost %s ZNN of delegated weight since %s",
+					history.Pillar, format.Amount(decrease, weightDecimals), previous.Time.Format(time.RFC3339)))
+			alert.Previous = previous.Weight
+			alerts = append(alerts, alert)
+		}
+	}
+
+	peak := history.PeakWeight(sample.Time.Add(-thresholds.Window))
+	if peak != nil && peak.Sign() > 0 && sample.Time.Sub(history.WeightAlertTime) >= thresholds.Window {
+		drop := new(big.Int).Sub(peak, weight)
+		percent, _ := new(big.Rat).SetFrac(new(big.Int).Mul(drop, big.NewInt(100)), peak).Float64()
+		if drop.Sign() > 0 && percent >= thresholds.MaxWeightDrop {
+			history.WeightAlertTime = sample.Time
+			alert := newAlert(history.Pillar, EventWeightDrop, sample,
+				fmt.Sprintf("pillar %s weight dropped %.2f%% within %s, from %s to %s ZNN",
+					history.Pillar, percent, thresholds.Window, format.Amount(peak, weightDecimals), format.Amount(weight, weightDecimals)))
+			alert.Previous = peak.String()
+			alerts = append(alerts, alert)
+		}
+	}
+
+	return alerts
+}
+
+// newAlert builds an alert event for a sample
+func newAlert(pillar string, eventType watch.EventType, sample Sample, message string) watch.Event {
+	return watch.Event{
+		ID:       fmt.Sprintf("%s:%s:%d", eventType, pillar, sample.Momentum),
+		Type:     eventType,
+		Address:  sample.Owner,
+		Time:     sample.Time,
+		Pillar:   pillar,
+		Amount:   sample.Weight,
+		Symbol:   "ZNN",
+		Momentum: sample.Momentum,
+		Message:  message,
+	}
+}
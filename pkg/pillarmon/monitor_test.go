@@ -0,0 +1,98 @@
+package pillarmon
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// sample builds a sample taken minutes after start with a weight in ZNN
+func sample(minutes int, produced, expected uint64, weightZnn int64) Sample {
+	weight := new(big.Int).Mul(big.NewInt(weightZnn), big.NewInt(1e8))
+	return Sample{
+		Time:     start.Add(time.Duration(minutes) * time.Minute),
+		Momentum: uint64(1000 + minutes*6),
+		Produced: produced,
+		Expected: expected,
+		Weight:   weight.String(),
+	}
+}
+
+// TestCheckLowProduction verifies production alerts fire once per episode
+func TestCheckLowProduction(t *testing.T) {
+	history := &History{Pillar: "p"}
+	thresholds := DefaultThresholds()
+
+	// Too few expected momentums to judge
+	assert.Empty(t, Check(history, sample(0, 2, 5, 1000), thresholds))
+	history.Add(sample(0, 2, 5, 1000), 0)
+
+	alerts := Check(history, sample(1, 8, 12, 1000), thresholds)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, EventLowProduction, alerts[0].Type)
+	history.Add(sample(1, 8, 12, 1000), 0)
+
+	// Still low: no repeated alert
+	assert.Empty(t, Check(history, sample(2, 9, 13, 1000), thresholds))
+	history.Add(sample(2, 9, 13, 1000), 0)
+
+	// Recovered, then low again
+	assert.Empty(t, Check(history, sample(3, 20, 21, 1000), thresholds))
+	history.Add(sample(3, 20, 21, 1000), 0)
+	assert.Len(t, Check(history, sample(4, 20, 30, 1000), thresholds), 1)
+}
+
+// TestCheckWeight verifies delegator departure and weight drop alerts
+func TestCheckWeight(t *testing.T) {
+	history := &History{Pillar: "p"}
+	thresholds := DefaultThresholds()
+
+	history.Add(sample(0, 0, 0, 10000), 0)
+
+	// Small decrease below the departure threshold
+	assert.Empty(t, Check(history, sample(10, 0, 0, 9950), thresholds))
+	history.Add(sample(10, 0, 0, 9950), 0)
+
+	// Departure of 500 ZNN, 5.5% below the peak
+	alerts := Check(history, sample(20, 0, 0, 9450), thresholds)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, EventDelegatorsLeft, alerts[0].Type)
+	history.Add(sample(20, 0, 0, 9450), 0)
+
+	// 12% below the peak within the window
+	alerts = Check(history, sample(30, 0, 0, 8800), thresholds)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, EventWeightDrop, alerts[1].Type)
+	history.Add(sample(30, 0, 0, 8800), 0)
+
+	// The weight drop alert is suppressed for the rest of the window
+	thresholds.MinDeparture = nil
+	assert.Empty(t, Check(history, sample(40, 0, 0, 8000), thresholds))
+}
+
+// TestHistorySaveLoad verifies the history file round trip and size limit
+func TestHistorySaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "p.json")
+
+	history, err := LoadHistory(path, "p")
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		history.Add(sample(i, 1, 1, 100), 3)
+	}
+	history.LowProduction = true
+	require.Len(t, history.Samples, 3)
+	require.NoError(t, history.Save(path))
+
+	loaded, err := LoadHistory(path, "p")
+	require.NoError(t, err)
+	assert.Equal(t, history, loaded)
+
+	_, err = LoadHistory(path, "other")
+	assert.Error(t, err)
+}
@@ -0,0 +1,173 @@
+// Package pillarstats ranks, filters and compares pillars for delegators,
+// including an estimate of the yearly ZNN return of delegating to a pillar.
+package pillarstats
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm/constants"
+)
+
+// Sort keys accepted by Sort
+const (
+	SortRank           = "rank"
+	SortWeight         = "weight"
+	SortUptime         = "uptime"
+	SortMomentumReward = "momentum-reward"
+	SortDelegateReward = "delegate-reward"
+	SortAPR            = "apr"
+)
+
+// SortKeys lists the accepted sort keys
+var SortKeys = []string{SortRank, SortWeight, SortUptime, SortMomentumReward, SortDelegateReward, SortAPR}
+
+// Pillar is a pillar with its derived statistics
+type Pillar struct {
+	Info *embedded.PillarInfo
+	// Uptime is the produced/expected momentum ratio of the current epoch, between 0 and 1
+	Uptime float64
+	// APR is the estimated yearly delegator return in percent
+	APR float64
+}
+
+// Filter selects pillars
+type Filter struct {
+	// Search matches pillar names case-insensitively
+	Search string
+	// MinUptime is the minimum uptime in percent
+	MinUptime float64
+}
+
+// Build derives the statistics of every pillar. The APR estimate needs the
+// whole pillar set, since rewards depend on each pillar's share of the total
+// weight and of the expected momentums; epoch selects the reward schedule.
+func Build(pillars []*embedded.PillarInfo, epoch uint64) []*Pillar {
+	totalWeight := new(big.Int)
+	var totalExpected uint64
+	for _, info := range pillars {
+		if info.Weight != nil {
+			totalWeight.Add(totalWeight, info.Weight)
+		}
+		if info.CurrentStats != nil {
+			totalExpected += info.CurrentStats.ExpectedMomentums
+		}
+	}
+
+	result := make([]*Pillar, 0, len(pillars))
+	for _, info := range pillars {
+		result = append(result, &Pillar{
+			Info:   info,
+			Uptime: uptime(info),
+			APR:    estimateAPR(info, totalWeight, totalExpected, epoch),
+		})
+	}
+	return result
+}
+
+// Apply returns the pillars matching the filter
+func (f Filter) Apply(pillars []*Pillar) []*Pillar {
+	search := strings.ToLower(strings.TrimSpace(f.Search))
+	var result []*Pillar
+	for _, pillar := range pillars {
+		if search != "" && !strings.Contains(strings.ToLower(pillar.Info.Name), search) {
+			continue
+		}
+		if pillar.Uptime*100 < f.MinUptime {
+			continue
+		}
+		result = append(result, pillar)
+	}
+	return result
+}
+
+// Sort orders pillars by key. Rank sorts ascending; every other key sorts
+// descending, so the best pillars come first. Ties keep rank order.
+func Sort(pillars []*Pillar, key string) error {
+	var less func(a, b *Pillar) bool
+	switch key {
+	case SortRank, "":
+		less = func(a, b *Pillar) bool { return false }
+	case SortWeight:
+		less = func(a, b *Pillar) bool { return weightOf(a).Cmp(weightOf(b)) > 0 }
+	case SortUptime:
+		less = func(a, b *Pillar) bool { return a.Uptime > b.Uptime }
+	case SortMomentumReward:
+		less = func(a, b *Pillar) bool {
+			return a.Info.GiveMomentumRewardPercentage > b.Info.GiveMomentumRewardPercentage
+		}
+	case SortDelegateReward:
+		less = func(a, b *Pillar) bool {
+			return a.Info.GiveDelegateRewardPercentage > b.Info.GiveDelegateRewardPercentage
+		}
+	case SortAPR:
+		less = func(a, b *Pillar) bool { return a.APR > b.APR }
+	default:
+		return fmt.Errorf("unknown sort key %q (use %s)", key, strings.Join(SortKeys, ", "))
+	}
+
+	sort.SliceStable(pillars, func(i, j int) bool {
+		if less(pillars[i], pillars[j]) {
+			return true
+		}
+		if less(pillars[j], pillars[i]) {
+			return false
+		}
+		return pillars[i].Info.Rank < pillars[j].Info.Rank
+	})
+	return nil
+}
+
+// uptime returns the produced/expected ratio, or 1 when nothing was expected yet
+func uptime(info *embedded.PillarInfo) float64 {
+	if info.CurrentStats == nil || info.CurrentStats.ExpectedMomentums == 0 {
+		return 1
+	}
+	return float64(info.CurrentStats.ProducedMomentums) / float64(info.CurrentStats.ExpectedMomentums)
+}
+
+// weightOf returns the weight of a pillar, treating nil as zero
+func weightOf(pillar *Pillar) *big.Int {
+	if pillar.Info.Weight == nil {
+		return new(big.Int)
+	}
+	return pillar.Info.Weight
+}
+
+// estimateAPR estimates the yearly return of delegating to a pillar, in
+// percent, following the protocol's epoch reward computation:
+//
+//	delegation = delegationRewardPerMomentum * momentumsPerEpoch * uptime * weight / totalWeight
+//	producing  = producingRewardPerMomentum * producedMomentums
+//	delegators = (giveMomentumPercentage * producing + giveDelegatePercentage * delegation) / 100
+//
+// The pillar's share of the epoch's momentums is taken from the current
+// epoch, and uptime and weights are assumed to stay constant for a year.
+func estimateAPR(info *embedded.PillarInfo, totalWeight *big.Int, totalExpected uint64, epoch uint64) float64 {
+	if info.Weight == nil || info.Weight.Sign() == 0 || totalWeight.Sign() == 0 {
+		return 0
+	}
+
+	delegationPerMomentum, producingPerMomentum := constants.PillarRewardPerMomentum(epoch)
+	momentums := float64(constants.MomentumsPerEpoch)
+	rate := uptime(info)
+
+	weight, _ := new(big.Float).SetInt(info.Weight).Float64()
+	total, _ := new(big.Float).SetInt(totalWeight).Float64()
+	delegation := float64(delegationPerMomentum.Int64()) * momentums * rate * weight / total
+
+	// Expected momentums for a full epoch, from the pillar's share of the current epoch
+	var expected float64
+	if info.CurrentStats != nil && totalExpected > 0 {
+		expected = momentums * float64(info.CurrentStats.ExpectedMomentums) / float64(totalExpected)
+	}
+	producing := float64(producingPerMomentum.Int64()) * expected * rate
+
+	toDelegators := (float64(info.GiveMomentumRewardPercentage)*producing +
+		float64(info.GiveDelegateRewardPercentage)*delegation) / 100
+
+	return toDelegators * 365 / weight * 100
+}
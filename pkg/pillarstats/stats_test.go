@@ -0,0 +1,94 @@
+package pillarstats
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+)
+
+// pillar builds a pillar with a weight in ZNN and current epoch stats
+func pillar(name string, rank int, weightZnn int64, produced, expected uint64, giveMomentum, giveDelegate uint8) *embedded.PillarInfo {
+	return &embedded.PillarInfo{
+		Name:                         name,
+		Rank:                         rank,
+		Weight:                       new(big.Int).Mul(big.NewInt(weightZnn), big.NewInt(1e8)),
+		CurrentStats:                 &embedded.PillarStats{ProducedMomentums: produced, ExpectedMomentums: expected},
+		GiveMomentumRewardPercentage: giveMomentum,
+		GiveDelegateRewardPercentage: giveDelegate,
+	}
+}
+
+func testPillars() []*embedded.PillarInfo {
+	return []*embedded.PillarInfo{
+		pillar("Alpha", 0, 400000, 90, 100, 0, 50),
+		pillar("Beta", 1, 300000, 100, 100, 100, 100),
+		pillar("Gamma", 2, 200000, 50, 100, 0, 0),
+		pillar("alphabet", 3, 100000, 0, 0, 20, 80),
+	}
+}
+
+// TestBuildAPR verifies APR estimates follow reward sharing and uptime
+func TestBuildAPR(t *testing.T) {
+	pillars := Build(testPillars(), 0)
+	require.Len(t, pillars, 4)
+
+	assert.InDelta(t, 0.9, pillars[0].Uptime, 1e-9)
+	assert.Equal(t, 1.0, pillars[3].Uptime)
+
+	// Sharing nothing gives delegators nothing
+	assert.Zero(t, pillars[2].APR)
+
+	// Sharing everything beats sharing half the delegation rewards
+	assert.Greater(t, pillars[1].APR, pillars[0].APR)
+	assert.Positive(t, pillars[0].APR)
+
+	// Epoch 0 emits 14400 ZNN per day; Beta shares all delegation rewards
+	// (24%, 30% of the weight) and momentum rewards (50%, a third of the momentums)
+	expected := (14400*0.24*0.3 + 14400*0.5/3) * 365 / 300000 * 100
+	assert.InDelta(t, expected, pillars[1].APR, 0.01)
+}
+
+// TestFilter verifies search and uptime filters
+func TestFilter(t *testing.T) {
+	pillars := Build(testPillars(), 0)
+
+	found := Filter{Search: "ALPHA"}.Apply(pillars)
+	require.Len(t, found, 2)
+	assert.Equal(t, "Alpha", found[0].Info.Name)
+	assert.Equal(t, "alphabet", found[1].Info.Name)
+
+	found = Filter{MinUptime: 95}.Apply(pillars)
+	require.Len(t, found, 2)
+	assert.Equal(t, "Beta", found[0].Info.Name)
+	assert.Equal(t, "alphabet", found[1].Info.Name)
+}
+
+// TestSort verifies sort keys and rank tie-breaking
+func TestSort(t *testing.T) {
+	names := func(pillars []*Pillar) []string {
+		var result []string
+		for _, pillar := range pillars {
+			result = append(result, pillar.Info.Name)
+		}
+		return result
+	}
+
+	pillars := Build(testPillars(), 0)
+
+	require.NoError(t, Sort(pillars, SortUptime))
+	assert.Equal(t, []string{"Beta", "alphabet", "Alpha", "Gamma"}, names(pillars))
+
+	require.NoError(t, Sort(pillars, SortDelegateReward))
+	assert.Equal(t, []string{"Beta", "alphabet", "Alpha", "Gamma"}, names(pillars))
+
+	require.NoError(t, Sort(pillars, SortRank))
+	assert.Equal(t, []string{"Alpha", "Beta", "Gamma", "alphabet"}, names(pillars))
+
+	require.NoError(t, Sort(pillars, SortMomentumReward))
+	assert.Equal(t, []string{"Beta", "alphabet", "Alpha", "Gamma"}, names(pillars))
+
+	assert.Error(t, Sort(pillars, "name"))
+}
@@ -0,0 +1,93 @@
+package plasma
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+)
+
+// FusionRequest is one line of a beneficiaries file
+type FusionRequest struct {
+	Line        int
+	Beneficiary types.Address
+	// Amount is the QSR to fuse, in base units
+	Amount *big.Int
+}
+
+// ParseFusionFile reads a CSV file of "beneficiary,amount" lines. Amounts are
+// whole QSR of at least MinFuseQsr. Blank lines, lines starting with # and a
+// leading "beneficiary,amount" header are skipped. resolve turns the
+// beneficiary column into an address, so it may accept address book labels.
+func ParseFusionFile(r io.Reader, resolve func(string) (types.Address, error)) ([]*FusionRequest, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	var requests []*FusionRequest
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read beneficiaries file: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		beneficiary := strings.TrimSpace(record[0])
+		amount := strings.TrimSpace(record[1])
+		if len(requests) == 0 && strings.EqualFold(beneficiary, "beneficiary") && strings.EqualFold(amount, "amount") {
+			continue
+		}
+
+		address, err := resolve(beneficiary)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid beneficiary %q: %w", line, beneficiary, err)
+		}
+		qsr, err := format.ResolveAmount(amount, format.CoinDecimals, nil, false)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid amount %q: %w", line, amount, err)
+		}
+		if qsr.Cmp(big.NewInt(MinFuseQsr*oneQsr)) < 0 {
+			return nil, fmt.Errorf("line %d: amount must be at least %d QSR", line, MinFuseQsr)
+		}
+		if new(big.Int).Mod(qsr, big.NewInt(oneQsr)).Sign() != 0 {
+			return nil, fmt.Errorf("line %d: amount must be a whole number of QSR", line)
+		}
+
+		requests = append(requests, &FusionRequest{Line: line, Beneficiary: address, Amount: qsr})
+	}
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("beneficiaries file has no entries")
+	}
+	return requests, nil
+}
+
+// TotalQsr returns the QSR fused by all requests, in base units
+func TotalQsr(requests []*FusionRequest) *big.Int {
+	total := new(big.Int)
+	for _, request := range requests {
+		total.Add(total, request.Amount)
+	}
+	return total
+}
+
+// ExpiredFusions returns the entries that can be cancelled at momentum height
+func ExpiredFusions(fusions []*embedded.FusionEntry, height uint64) []*embedded.FusionEntry {
+	var expired []*embedded.FusionEntry
+	for _, fusion := range fusions {
+		if fusion.ExpirationHeight <= height {
+			expired = append(expired, fusion)
+		}
+	}
+	return expired
+}
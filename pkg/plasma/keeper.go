@@ -0,0 +1,204 @@
+package plasma
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+)
+
+// pageSize is the number of fusion entries requested per page
+const pageSize = 50
+
+// Keeper action kinds
+const (
+	ActionFuse   = "fuse"
+	ActionCancel = "cancel"
+)
+
+// Policy configures when the keeper fuses and cancels
+type Policy struct {
+	// MinPlasma is the current plasma below which a beneficiary is topped up
+	MinPlasma uint64
+	// FuseAmount is the QSR fused per top-up, in base units
+	FuseAmount *big.Int
+	// CancelExpired cancels expired fusions of the funding address whose
+	// beneficiary keeps at least MinPlasma without them
+	CancelExpired bool
+}
+
+// Validate checks that the policy is usable
+func (p Policy) Validate() error {
+	if p.FuseAmount == nil || p.FuseAmount.Cmp(big.NewInt(MinFuseQsr*oneQsr)) < 0 {
+		return fmt.Errorf("fuse amount must be at least %d QSR", MinFuseQsr)
+	}
+	if new(big.Int).Mod(p.FuseAmount, big.NewInt(oneQsr)).Sign() != 0 {
+		return fmt.Errorf("fuse amount must be a whole number of QSR")
+	}
+	return nil
+}
+
+// Action is a fusion or cancellation decided by the keeper
+type Action struct {
+	Kind        string
+	Beneficiary types.Address
+	Amount      *big.Int
+	// ID is the fusion entry to cancel
+	ID     types.Hash
+	Reason string
+	// Hash and Err are set once the action is published
+	Hash types.Hash
+	Err  error
+}
+
+// Decide returns the actions that keep every beneficiary at or above
+// policy.MinPlasma. plasma holds the plasma of each beneficiary, fusions the
+// entries of the funding address and height the frontier momentum height.
+// Fusions are only cancelled for beneficiaries that do not need a top-up,
+// and only while the beneficiary's remaining plasma stays above the minimum.
+func Decide(policy Policy, beneficiaries []types.Address, plasma map[types.Address]*embedded.PlasmaInfo, fusions []*embedded.FusionEntry, height uint64) []*Action {
+	var actions []*Action
+	remaining := make(map[types.Address]uint64, len(beneficiaries))
+
+	for _, beneficiary := range beneficiaries {
+		info := plasma[beneficiary]
+		if info == nil {
+			continue
+		}
+		if info.CurrentPlasma < policy.MinPlasma {
+			actions = append(actions, &Action{
+				Kind:        ActionFuse,
+				Beneficiary: beneficiary,
+				Amount:      new(big.Int).Set(policy.FuseAmount),
+				Reason:      fmt.Sprintf("plasma %d is below %d", info.CurrentPlasma, policy.MinPlasma),
+			})
+			continue
+		}
+		remaining[beneficiary] = info.CurrentPlasma
+	}
+
+	if !policy.CancelExpired {
+		return actions
+	}
+
+	for _, fusion := range fusions {
+		current, watched := remaining[fusion.Beneficiary]
+		if !watched || fusion.ExpirationHeight > height || fusion.QsrAmount == nil {
+			continue
+		}
+		fused := new(big.Int).Div(fusion.QsrAmount, big.NewInt(oneQsr)).Uint64() * PlasmaPerQsr
+		if fused > current || current-fused < policy.MinPlasma {
+			continue
+		}
+		remaining[fusion.Beneficiary] = current - fused
+		actions = append(actions, &Action{
+			Kind:        ActionCancel,
+			Beneficiary: fusion.Beneficiary,
+			Amount:      new(big.Int).Set(fusion.QsrAmount),
+			ID:          fusion.Id,
+			Reason:      fmt.Sprintf("plasma stays at %d without it", current-fused),
+		})
+	}
+	return actions
+}
+
+// Keeper tops up the plasma of beneficiaries from a funding address
+type Keeper struct {
+	Client        *rpc_client.RpcClient
+	Funder        types.Address
+	KeyPair       *wallet.KeyPair
+	Beneficiaries []types.Address
+	Policy        Policy
+}
+
+// Run queries the beneficiaries, decides and publishes the actions. Each
+// action carries its own outcome; the error reports failed queries only.
+func (k *Keeper) Run(ctx context.Context) ([]*Action, error) {
+	plasma := make(map[types.Address]*embedded.PlasmaInfo, len(k.Beneficiaries))
+	for _, beneficiary := range k.Beneficiaries {
+		info, err := k.Client.PlasmaApi.Get(beneficiary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get plasma of %s: %w", beneficiary, err)
+		}
+		plasma[beneficiary] = info
+	}
+
+	var fusions []*embedded.FusionEntry
+	var height uint64
+	if k.Policy.CancelExpired {
+		var err error
+		if fusions, err = Fusions(k.Client, k.Funder); err != nil {
+			return nil, err
+		}
+		momentum, err := k.Client.LedgerApi.GetFrontierMomentum()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get frontier momentum: %w", err)
+		}
+		height = momentum.Height
+	}
+
+	actions := Decide(k.Policy, k.Beneficiaries, plasma, fusions, height)
+	if len(actions) == 0 {
+		return nil, nil
+	}
+
+	balance, err := qsrBalance(k.Client, k.Funder)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, action := range actions {
+		switch action.Kind {
+		case ActionFuse:
+			if balance.Cmp(action.Amount) < 0 {
+				action.Err = fmt.Errorf("insufficient QSR balance at %s", k.Funder)
+				continue
+			}
+			template := k.Client.PlasmaApi.Fuse(action.Beneficiary, action.Amount)
+			if action.Err = transaction.BuildAndSend(ctx, k.Client, k.Funder, template, k.KeyPair); action.Err == nil {
+				action.Hash = template.Hash
+				balance.Sub(balance, action.Amount)
+			}
+		case ActionCancel:
+			template := k.Client.PlasmaApi.Cancel(action.ID)
+			if action.Err = transaction.BuildAndSend(ctx, k.Client, k.Funder, template, k.KeyPair); action.Err == nil {
+				action.Hash = template.Hash
+			}
+		}
+	}
+	return actions, nil
+}
+
+// Fusions returns every fusion entry of an address
+func Fusions(c *rpc_client.RpcClient, address types.Address) ([]*embedded.FusionEntry, error) {
+	var fusions []*embedded.FusionEntry
+	for pageIndex := uint32(0); ; pageIndex++ {
+		list, err := c.PlasmaApi.GetEntriesByAddress(address, pageIndex, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fusion entries: %w", err)
+		}
+		fusions = append(fusions, list.Fusions...)
+		if len(list.Fusions) < pageSize || len(fusions) >= list.Count {
+			return fusions, nil
+		}
+	}
+}
+
+// qsrBalance returns the QSR balance of an address
+func qsrBalance(c *rpc_client.RpcClient, address types.Address) (*big.Int, error) {
+	info, err := c.LedgerApi.GetAccountInfoByAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account info: %w", err)
+	}
+	balance := new(big.Int)
+	if balanceInfo, found := info.BalanceInfoMap[types.QsrTokenStandard]; found && balanceInfo.Balance != nil {
+		balance.Set(balanceInfo.Balance)
+	}
+	return balance, nil
+}
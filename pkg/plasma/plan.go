@@ -0,0 +1,139 @@
+// Package plasma estimates the QSR to fuse for a transaction budget and keeps
+// the plasma of beneficiary addresses above a threshold by fusing QSR from a
+// funding address and cancelling expired fusions that are no longer needed.
+package plasma
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm/constants"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+)
+
+const (
+	// PlasmaPerQsr is the plasma generated by each fused QSR
+	PlasmaPerQsr = constants.PlasmaPerFusionUnit
+
+	// MinFuseQsr is the minimum QSR of a fusion
+	MinFuseQsr = 10
+
+	// MaxFusedQsr is the maximum QSR fused for one account
+	MaxFusedQsr = constants.MaxFusionUnitsPerAccount
+
+	// oneQsr is 1 QSR in base units
+	oneQsr = constants.CostPerFusionUnit
+)
+
+// Transaction types accepted by Template
+const (
+	TxSend     = "send"
+	TxReceive  = "receive"
+	TxStake    = "stake"
+	TxFuse     = "fuse"
+	TxDelegate = "delegate"
+	TxCollect  = "collect"
+)
+
+// TxTypes lists the accepted transaction types
+var TxTypes = []string{TxSend, TxReceive, TxStake, TxFuse, TxDelegate, TxCollect}
+
+// Template builds a representative block of a transaction type, used to
+// query its base plasma. memoSize adds that many bytes of data to sends.
+func Template(c *rpc_client.RpcClient, txType string, address types.Address, memoSize int) (*nom.AccountBlock, error) {
+	switch txType {
+	case TxSend:
+		return &nom.AccountBlock{
+			BlockType:     nom.BlockTypeUserSend,
+			ToAddress:     address,
+			TokenStandard: types.ZnnTokenStandard,
+			Amount:        big.NewInt(0),
+			Data:          make([]byte, memoSize),
+		}, nil
+	case TxReceive:
+		return &nom.AccountBlock{BlockType: nom.BlockTypeUserReceive, ToAddress: address}, nil
+	case TxStake:
+		return c.StakeApi.Stake(constants.StakeTimeUnitSec, new(big.Int).Set(constants.StakeMinAmount)), nil
+	case TxFuse:
+		return c.PlasmaApi.Fuse(address, big.NewInt(MinFuseQsr*oneQsr)), nil
+	case TxDelegate:
+		return c.PillarApi.Delegate("pillar"), nil
+	case TxCollect:
+		return c.StakeApi.CollectReward(), nil
+	default:
+		return nil, fmt.Errorf("unknown transaction type %q (use %s)", txType, strings.Join(TxTypes, ", "))
+	}
+}
+
+// BasePlasma returns the plasma one block of template costs
+func BasePlasma(ctx context.Context, c *rpc_client.RpcClient, address types.Address, template *nom.AccountBlock) (uint64, error) {
+	result, err := transaction.RequiredPlasma(ctx, c, address, template)
+	if err != nil {
+		return 0, err
+	}
+	return result.BasePlasma, nil
+}
+
+// Plan is the QSR needed to publish Count blocks of BasePlasma each with
+// fused plasma alone. Plasma spent by a block is only available again once
+// the block is confirmed, so Count is the number of blocks sent in a burst.
+type Plan struct {
+	Count      uint64
+	BasePlasma uint64
+	// Required is the plasma needed for Count blocks
+	Required uint64
+	// Available is the maximum plasma of the already fused QSR
+	Available uint64
+	// FusedQsr is the QSR already fused for the address
+	FusedQsr *big.Int
+	// FuseQsr is the additional QSR to fuse, in base units
+	FuseQsr *big.Int
+	// Capped is set when the per-account fusion limit prevents covering Required
+	Capped bool
+}
+
+// NewPlan computes the QSR to fuse for count blocks of basePlasma on top of
+// the plasma described by info
+func NewPlan(basePlasma, count uint64, info *embedded.PlasmaInfo) *Plan {
+	plan := &Plan{
+		Count:      count,
+		BasePlasma: basePlasma,
+		Required:   basePlasma * count,
+		FusedQsr:   new(big.Int),
+		FuseQsr:    new(big.Int),
+	}
+	if info != nil {
+		plan.Available = info.MaxPlasma
+		if info.QsrAmount != nil {
+			plan.FusedQsr.Set(info.QsrAmount)
+		}
+	}
+	if plan.Required <= plan.Available {
+		return plan
+	}
+
+	shortfall := plan.Required - plan.Available
+	qsr := (shortfall + PlasmaPerQsr - 1) / PlasmaPerQsr
+	if qsr < MinFuseQsr {
+		qsr = MinFuseQsr
+	}
+
+	fused := new(big.Int).Div(plan.FusedQsr, big.NewInt(oneQsr)).Uint64()
+	if fused+qsr > MaxFusedQsr {
+		plan.Capped = true
+		if fused+MinFuseQsr > MaxFusedQsr {
+			return plan
+		}
+		qsr = MaxFusedQsr - fused
+	}
+
+	plan.FuseQsr.Mul(new(big.Int).SetUint64(qsr), big.NewInt(oneQsr))
+	return plan
+}
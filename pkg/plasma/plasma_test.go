@@ -0,0 +1,136 @@
+package plasma
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+)
+
+func qsr(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), big.NewInt(oneQsr))
+}
+
+func TestNewPlan(t *testing.T) {
+	// 10 sends of 21000 plasma without any fused QSR
+	plan := NewPlan(21000, 10, nil)
+	assert.Equal(t, uint64(210000), plan.Required)
+	assert.Equal(t, qsr(100), plan.FuseQsr)
+	assert.False(t, plan.Capped)
+
+	// Already covered by fused QSR
+	plan = NewPlan(21000, 10, &embedded.PlasmaInfo{MaxPlasma: 210000, QsrAmount: qsr(100)})
+	assert.Equal(t, 0, plan.FuseQsr.Sign())
+
+	// Partial shortfall rounds up to a whole QSR
+	plan = NewPlan(21000, 10, &embedded.PlasmaInfo{MaxPlasma: 189000, QsrAmount: qsr(90)})
+	assert.Equal(t, qsr(10), plan.FuseQsr)
+	plan = NewPlan(21068, 10, &embedded.PlasmaInfo{MaxPlasma: 189000, QsrAmount: qsr(90)})
+	assert.Equal(t, qsr(11), plan.FuseQsr)
+
+	// Small shortfalls fuse the minimum
+	plan = NewPlan(21000, 1, &embedded.PlasmaInfo{MaxPlasma: 20000, QsrAmount: qsr(10)})
+	assert.Equal(t, qsr(MinFuseQsr), plan.FuseQsr)
+}
+
+func TestNewPlanCapped(t *testing.T) {
+	plan := NewPlan(21000, 1000, &embedded.PlasmaInfo{MaxPlasma: 4000 * PlasmaPerQsr, QsrAmount: qsr(4000)})
+	assert.True(t, plan.Capped)
+	assert.Equal(t, qsr(MaxFusedQsr-4000), plan.FuseQsr)
+
+	plan = NewPlan(21000, 1000, &embedded.PlasmaInfo{MaxPlasma: 4995 * PlasmaPerQsr, QsrAmount: qsr(4995)})
+	assert.True(t, plan.Capped)
+	assert.Equal(t, 0, plan.FuseQsr.Sign())
+}
+
+func TestPolicyValidate(t *testing.T) {
+	assert.NoError(t, Policy{FuseAmount: qsr(10)}.Validate())
+	assert.Error(t, Policy{}.Validate())
+	assert.Error(t, Policy{FuseAmount: qsr(9)}.Validate())
+	assert.Error(t, Policy{FuseAmount: new(big.Int).Add(qsr(10), big.NewInt(1))}.Validate())
+}
+
+func TestDecide(t *testing.T) {
+	low := types.ParseAddressPanic("z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz")
+	high := types.ParseAddressPanic("z1qqjnwjjpnue8xmmpanz6csze6tcmtzzdtfsww7")
+	policy := Policy{MinPlasma: 42000, FuseAmount: qsr(20), CancelExpired: true}
+
+	plasma := map[types.Address]*embedded.PlasmaInfo{
+		low:  {CurrentPlasma: 21000},
+		high: {CurrentPlasma: 42000 + 30*PlasmaPerQsr},
+	}
+	fusions := []*embedded.FusionEntry{
+		// Not expired yet
+		{Beneficiary: high, QsrAmount: qsr(10), ExpirationHeight: 200, Id: types.HexToHashPanic("0000000000000000000000000000000000000000000000000000000000000001")},
+		// Expired, plasma stays above the minimum
+		{Beneficiary: high, QsrAmount: qsr(20), ExpirationHeight: 100, Id: types.HexToHashPanic("0000000000000000000000000000000000000000000000000000000000000002")},
+		// Expired, but would drop plasma below the minimum after the previous cancel
+		{Beneficiary: high, QsrAmount: qsr(20), ExpirationHeight: 100, Id: types.HexToHashPanic("0000000000000000000000000000000000000000000000000000000000000003")},
+		// Expired, but the beneficiary needs a top-up
+		{Beneficiary: low, QsrAmount: qsr(10), ExpirationHeight: 100, Id: types.HexToHashPanic("0000000000000000000000000000000000000000000000000000000000000004")},
+	}
+
+	actions := Decide(policy, []types.Address{low, high}, plasma, fusions, 150)
+	require.Len(t, actions, 2)
+	assert.Equal(t, ActionFuse, actions[0].Kind)
+	assert.Equal(t, low, actions[0].Beneficiary)
+	assert.Equal(t, qsr(20), actions[0].Amount)
+	assert.Equal(t, ActionCancel, actions[1].Kind)
+	assert.Equal(t, fusions[1].Id, actions[1].ID)
+
+	policy.CancelExpired = false
+	actions = Decide(policy, []types.Address{low, high}, plasma, fusions, 150)
+	require.Len(t, actions, 1)
+	assert.Equal(t, ActionFuse, actions[0].Kind)
+}
+
+func TestParseFusionFile(t *testing.T) {
+	alice := types.PlasmaContract
+	bob := types.StakeContract
+	resolve := func(value string) (types.Address, error) {
+		switch value {
+		case "@alice":
+			return alice, nil
+		case bob.String():
+			return bob, nil
+		}
+		return types.ZeroAddress, fmt.Errorf("unknown address")
+	}
+
+	input := "beneficiary,amount\n# funding round\n\n@alice, 10\n" + bob.String() + ",1,000\n"
+	_, err := ParseFusionFile(strings.NewReader(input), resolve)
+	require.Error(t, err, "unquoted thousands separator adds a field")
+
+	input = "beneficiary,amount\n# funding round\n\n@alice, 10\n" + bob.String() + ",\"1,000\"\n"
+	requests, err := ParseFusionFile(strings.NewReader(input), resolve)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, alice, requests[0].Beneficiary)
+	assert.Equal(t, qsr(10), requests[0].Amount)
+	assert.Equal(t, 4, requests[0].Line)
+	assert.Equal(t, bob, requests[1].Beneficiary)
+	assert.Equal(t, qsr(1000), requests[1].Amount)
+	assert.Equal(t, qsr(1010), TotalQsr(requests))
+
+	for _, bad := range []string{"", "@alice,5\n", "@alice,10.5\n", "@carol,10\n", "@alice,ten\n", "@alice,max\n"} {
+		_, err := ParseFusionFile(strings.NewReader(bad), resolve)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestExpiredFusions(t *testing.T) {
+	fusions := []*embedded.FusionEntry{
+		{ExpirationHeight: 90},
+		{ExpirationHeight: 100},
+		{ExpirationHeight: 101},
+	}
+	expired := ExpiredFusions(fusions, 100)
+	require.Len(t, expired, 2)
+	assert.Equal(t, uint64(90), expired[0].ExpirationHeight)
+	assert.Equal(t, uint64(100), expired[1].ExpirationHeight)
+}
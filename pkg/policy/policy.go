@@ -0,0 +1,337 @@
+// Package policy implements spending policies for keyStores. A policy limits
+// what the CLI will sign with a keyStore: amounts per transaction, rolling
+// daily caps, destinations and embedded contract methods. Amounts above a
+// threshold can require interactive confirmation.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/abi"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"gopkg.in/yaml.v3"
+
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+)
+
+// DailyWindow is the period covered by daily caps
+const DailyWindow = 24 * time.Hour
+
+// Policy is the spending policy of a keyStore, read from <keyStore>.yaml in
+// the policy directory.
+//
+// Token keys are ZNN, QSR or a ZTS. Amounts are decimal amounts of the token.
+// An omitted list places no restriction; an empty list allows nothing.
+type Policy struct {
+	// MaxPerTransaction limits the amount of a token sent by one block
+	MaxPerTransaction map[string]string `yaml:"max_per_transaction"`
+	// DailyCap limits the amount of a token all addresses of the keyStore
+	// send in 24 hours
+	DailyCap map[string]string `yaml:"daily_cap"`
+	// ConfirmAbove requires interactive confirmation of larger amounts
+	ConfirmAbove map[string]string `yaml:"confirm_above"`
+	// AllowedDestinations lists the z1... addresses or @labels that may
+	// receive sends. Embedded contracts are governed by AllowedContractMethods.
+	AllowedDestinations *[]string `yaml:"allowed_destinations"`
+	// AllowedContractMethods lists the embedded contract methods that may be
+	// called, as contract.Method, contract.* or *
+	AllowedContractMethods *[]string `yaml:"allowed_contract_methods"`
+
+	destinations map[types.Address]bool
+}
+
+// Path returns the policy file of a keyStore
+func Path(dir, keyStore string) string {
+	return filepath.Join(dir, keyStore+".yaml")
+}
+
+// Load reads a policy file, returning nil if it does not exist. Destinations
+// are resolved with resolve, which accepts z1... addresses and @labels.
+func Load(path string, resolve func(string) (types.Address, error)) (*Policy, error) {
+	// #nosec G304 - Path is built from the configured policy directory
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+
+	var policy Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", path, err)
+	}
+
+	for _, amounts := range []map[string]string{policy.MaxPerTransaction, policy.DailyCap, policy.ConfirmAbove} {
+		for token := range amounts {
+			if _, err := ParseToken(token); err != nil {
+				return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+			}
+		}
+	}
+	if policy.AllowedDestinations != nil {
+		policy.destinations = make(map[types.Address]bool)
+		for _, destination := range *policy.AllowedDestinations {
+			address, err := resolve(destination)
+			if err != nil {
+				return nil, fmt.Errorf("invalid policy %s: destination %s: %w", path, destination, err)
+			}
+			policy.destinations[address] = true
+		}
+	}
+	if policy.AllowedContractMethods != nil {
+		for _, method := range *policy.AllowedContractMethods {
+			contract, _, _ := strings.Cut(method, ".")
+			if method != "*" && !knownContract(contract) {
+				return nil, fmt.Errorf("invalid policy %s: unknown contract in %s", path, method)
+			}
+		}
+	}
+	return &policy, nil
+}
+
+// ParseToken parses a policy token key: ZNN, QSR or a ZTS
+func ParseToken(token string) (types.ZenonTokenStandard, error) {
+	switch strings.ToUpper(token) {
+	case "ZNN":
+		return types.ZnnTokenStandard, nil
+	case "QSR":
+		return types.QsrTokenStandard, nil
+	}
+	zts, err := types.ParseZTS(token)
+	if err != nil {
+		return types.ZeroTokenStandard, fmt.Errorf("invalid token %s: %w", token, err)
+	}
+	return zts, nil
+}
+
+// amountFor returns the policy amount of a token, if any
+func amountFor(amounts map[string]string, zts types.ZenonTokenStandard) (string, bool) {
+	for token, amount := range amounts {
+		if parsed, err := ParseToken(token); err == nil && parsed == zts {
+			return amount, true
+		}
+	}
+	return "", false
+}
+
+// contracts maps embedded contracts to the name used in policies and their ABI
+var contracts = map[types.Address]struct {
+	name string
+	abi  *abi.ABIContract
+}{
+	types.PlasmaContract:      {"plasma", &definition.ABIPlasma},
+	types.StakeContract:       {"stake", &definition.ABIStake},
+	types.PillarContract:      {"pillar", &definition.ABIPillars},
+	types.SentinelContract:    {"sentinel", &definition.ABISentinel},
+	types.TokenContract:       {"token", &definition.ABIToken},
+	types.LiquidityContract:   {"liquidity", &definition.ABILiquidity},
+	types.SwapContract:        {"swap", &definition.ABISwap},
+	types.SporkContract:       {"spork", &definition.ABISpork},
+	types.HtlcContract:        {"htlc", &definition.ABIHtlc},
+	types.BridgeContract:      {"bridge", &definition.ABIBridge},
+	types.AcceleratorContract: {"accelerator", &definition.ABIAccelerator},
+}
+
+func knownContract(name string) bool {
+	for _, contract := range contracts {
+		if strings.EqualFold(contract.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContractMethod returns the contract.Method called by a send block, and
+// false if the block is not sent to an embedded contract. Unknown methods
+// are reported as contract.unknown.
+func ContractMethod(block *nom.AccountBlock) (string, bool) {
+	method, _, isContract := DecodeCall(block)
+	return method, isContract
+}
+
+// DecodeCall returns the contract.Method called by a send block and its
+// arguments as name=value pairs, and false if the block is not sent to an
+// embedded contract
+func DecodeCall(block *nom.AccountBlock) (string, []string, bool) {
+	contract, found := contracts[block.ToAddress]
+	if !found {
+		return "", nil, false
+	}
+	method, err := contract.abi.MethodById(block.Data)
+	if err != nil {
+		return contract.name + ".unknown", nil, true
+	}
+
+	var args []string
+	values, err := method.Inputs.UnpackValues(block.Data[4:])
+	if err == nil {
+		for i, value := range values {
+			args = append(args, fmt.Sprintf("%s=%v", method.Inputs[i].Name, value))
+		}
+	}
+	return contract.name + "." + method.Name, args, true
+}
+
+// allowsMethod reports whether a contract.Method matches an allow-list entry
+func allowsMethod(allowed []string, method string) bool {
+	contract, _, _ := strings.Cut(method, ".")
+	for _, entry := range allowed {
+		if entry == "*" || strings.EqualFold(entry, method) || strings.EqualFold(entry, contract+".*") {
+			return true
+		}
+	}
+	return false
+}
+
+// Violation is the error returned for a block the policy does not allow
+type Violation struct {
+	KeyStore string
+	Rule     string
+	Reason   string
+}
+
+// Error implements the error interface
+func (v *Violation) Error() string {
+	return fmt.Sprintf("blocked by the spending policy of keyStore %s (%s): %s", v.KeyStore, v.Rule, v.Reason)
+}
+
+// ErrorKind returns errs.Policy
+func (v *Violation) ErrorKind() errs.Kind {
+	return errs.Policy
+}
+
+// Checker checks blocks against the policy of a keyStore
+type Checker struct {
+	KeyStore string
+	Policy   *Policy
+	// Decimals returns the decimals of a token
+	Decimals func(types.ZenonTokenStandard) (int, error)
+	// Spent returns the amount of a token sent from any address of the
+	// keyStore since a time. It is nil when the transaction journal is
+	// disabled.
+	Spent func(zts types.ZenonTokenStandard, since time.Time) (*big.Int, error)
+	// Now returns the current time (time.Now if nil)
+	Now func() time.Time
+}
+
+// Check returns a *Violation if the policy does not allow a block, or the
+// reasons the block needs interactive confirmation. Only send blocks are
+// checked; receiving is always allowed.
+func (c *Checker) Check(block *nom.AccountBlock) ([]string, error) {
+	if !nom.IsSendBlock(block.BlockType) {
+		return nil, nil
+	}
+	p := c.Policy
+
+	if method, isContract := ContractMethod(block); isContract {
+		if p.AllowedContractMethods != nil && !allowsMethod(*p.AllowedContractMethods, method) {
+			return nil, c.violation("allowed_contract_methods", fmt.Sprintf("%s is not an allowed contract method", method))
+		}
+	} else if p.destinations != nil && !p.destinations[block.ToAddress] {
+		return nil, c.violation("allowed_destinations", fmt.Sprintf("%s is not an allowed destination", block.ToAddress))
+	}
+
+	amount := block.Amount
+	if amount == nil || amount.Sign() == 0 {
+		return nil, nil
+	}
+	zts := block.TokenStandard
+
+	if limit, found := amountFor(p.MaxPerTransaction, zts); found {
+		max, decimals, err := c.parse(limit, zts)
+		if err != nil {
+			return nil, err
+		}
+		if amount.Cmp(max) > 0 {
+			return nil, c.violation("max_per_transaction", fmt.Sprintf("sending %s exceeds the limit of %s per transaction",
+				c.describe(amount, decimals, zts), c.describe(max, decimals, zts)))
+		}
+	}
+
+	if limit, found := amountFor(p.DailyCap, zts); found {
+		daily, decimals, err := c.parse(limit, zts)
+		if err != nil {
+			return nil, err
+		}
+		if c.Spent == nil {
+			return nil, c.violation("daily_cap", "daily caps require the transaction journal, which is disabled")
+		}
+		spent, err := c.Spent(zts, c.now().Add(-DailyWindow))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spending from the journal: %w", err)
+		}
+		total := new(big.Int).Add(spent, amount)
+		if total.Cmp(daily) > 0 {
+			return nil, c.violation("daily_cap", fmt.Sprintf("sending %s after %s sent by keyStore %s in the last 24 hours exceeds the daily cap of %s",
+				c.describe(amount, decimals, zts), c.describe(spent, decimals, zts), c.KeyStore, c.describe(daily, decimals, zts)))
+		}
+	}
+
+	var confirm []string
+	if threshold, found := amountFor(p.ConfirmAbove, zts); found {
+		above, decimals, err := c.parse(threshold, zts)
+		if err != nil {
+			return nil, err
+		}
+		if amount.Cmp(above) > 0 {
+			confirm = append(confirm, fmt.Sprintf("sending %s to %s is above the confirmation threshold of %s",
+				c.describe(amount, decimals, zts), block.ToAddress, c.describe(above, decimals, zts)))
+		}
+	}
+	return confirm, nil
+}
+
+// Declined returns the violation of a block whose confirmation was declined
+// or could not be asked for
+func (c *Checker) Declined(reason string) *Violation {
+	return c.violation("confirm_above", reason)
+}
+
+func (c *Checker) violation(rule, reason string) *Violation {
+	return &Violation{KeyStore: c.KeyStore, Rule: rule, Reason: reason}
+}
+
+// parse converts a policy amount of a token to base units
+func (c *Checker) parse(value string, zts types.ZenonTokenStandard) (*big.Int, int, error) {
+	decimals, err := c.Decimals(zts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get decimals of %s: %w", zts, err)
+	}
+	amount, err := format.ResolveAmount(value, decimals, nil, false)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid policy amount %q for %s: %w", value, zts, err)
+	}
+	return amount, decimals, nil
+}
+
+// describe formats an amount of a token
+func (c *Checker) describe(amount *big.Int, decimals int, zts types.ZenonTokenStandard) string {
+	symbol := zts.String()
+	switch zts {
+	case types.ZnnTokenStandard:
+		symbol = "ZNN"
+	case types.QsrTokenStandard:
+		symbol = "QSR"
+	}
+	return format.Amount(amount, decimals) + " " + symbol
+}
+
+func (c *Checker) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
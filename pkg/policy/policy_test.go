@@ -0,0 +1,196 @@
+package policy
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/testutil"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+)
+
+const testPolicy = `
+max_per_transaction:
+  ZNN: "100"
+  qsr: "1,000"
+daily_cap:
+  ZNN: "150"
+confirm_above:
+  ZNN: "50"
+allowed_destinations:
+  - "@treasury"
+  - z1qqjnwjjpnue8xmmpanz6csze6tcmtzzdtfsww7
+allowed_contract_methods:
+  - plasma.Fuse
+  - stake.*
+`
+
+func coins(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), big.NewInt(1e8))
+}
+
+func resolve(value string) (types.Address, error) {
+	if value == "@treasury" {
+		return testutil.ProducerAddress, nil
+	}
+	return types.ParseAddress(value)
+}
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := Path(t.TempDir(), "bot")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func loadChecker(t *testing.T, content string, spent *big.Int) *Checker {
+	t.Helper()
+	p, err := Load(writePolicy(t, content), resolve)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	return &Checker{
+		KeyStore: "bot",
+		Policy:   p,
+		Decimals: func(types.ZenonTokenStandard) (int, error) { return 8, nil },
+		Spent: func(types.ZenonTokenStandard, time.Time) (*big.Int, error) {
+			return spent, nil
+		},
+	}
+}
+
+func send(to types.Address, zts types.ZenonTokenStandard, amount *big.Int, data []byte) *nom.AccountBlock {
+	return &nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       testutil.ValidAddress,
+		ToAddress:     to,
+		TokenStandard: zts,
+		Amount:        amount,
+		Data:          data,
+	}
+}
+
+func TestLoad(t *testing.T) {
+	p, err := Load(filepath.Join(t.TempDir(), "missing.yaml"), resolve)
+	require.NoError(t, err)
+	assert.Nil(t, p, "keyStores without a policy are unrestricted")
+
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"unknown field", "max_per_tx:\n  ZNN: \"1\"\n", "field max_per_tx not found"},
+		{"unknown token", "daily_cap:\n  BTC: \"1\"\n", "invalid token BTC"},
+		{"unknown label", "allowed_destinations: ['@nobody']\n", "destination @nobody"},
+		{"unknown contract", "allowed_contract_methods: [bank.Send]\n", "unknown contract in bank.Send"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writePolicy(t, tt.content), resolve)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	checker := loadChecker(t, testPolicy, coins(60))
+	fuse := definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, testutil.ValidAddress)
+	cancel := definition.ABIPlasma.PackMethodPanic(definition.CancelFuseMethodName, types.ZeroHash)
+
+	tests := []struct {
+		name    string
+		block   *nom.AccountBlock
+		rule    string
+		confirm bool
+	}{
+		{"allowed", send(testutil.ProducerAddress, types.ZnnTokenStandard, coins(10), nil), "", false},
+		{"destination", send(testutil.ValidAddress, types.ZnnTokenStandard, coins(1), nil), "allowed_destinations", false},
+		{"per transaction", send(testutil.ProducerAddress, types.QsrTokenStandard, coins(1001), nil), "max_per_transaction", false},
+		{"daily cap", send(testutil.ProducerAddress, types.ZnnTokenStandard, coins(91), nil), "daily_cap", false},
+		{"confirm", send(testutil.ProducerAddress, types.ZnnTokenStandard, coins(51), nil), "", true},
+		{"contract method", send(types.PlasmaContract, types.QsrTokenStandard, coins(10), fuse), "", false},
+		{"contract wildcard", send(types.StakeContract, types.ZnnTokenStandard, coins(0), []byte{1, 2, 3, 4}), "", false},
+		{"contract denied", send(types.PlasmaContract, types.ZnnTokenStandard, coins(0), cancel), "allowed_contract_methods", false},
+		{"contract not listed", send(types.TokenContract, types.ZnnTokenStandard, coins(1), nil), "allowed_contract_methods", false},
+		{"receive", &nom.AccountBlock{BlockType: nom.BlockTypeUserReceive, Address: testutil.ValidAddress}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confirm, err := checker.Check(tt.block)
+			if tt.rule != "" {
+				var violation *Violation
+				require.True(t, errors.As(err, &violation), "expected a violation, got %v", err)
+				assert.Equal(t, tt.rule, violation.Rule)
+				assert.Equal(t, "bot", violation.KeyStore)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.confirm, len(confirm) > 0)
+		})
+	}
+}
+
+func TestCheckDailyCapWithoutJournal(t *testing.T) {
+	checker := loadChecker(t, "daily_cap:\n  ZNN: \"10\"\n", nil)
+	checker.Spent = nil
+	_, err := checker.Check(send(testutil.ProducerAddress, types.ZnnTokenStandard, coins(1), nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "require the transaction journal")
+
+	// Tokens without a cap do not need the journal
+	_, err = checker.Check(send(testutil.ProducerAddress, types.QsrTokenStandard, coins(1), nil))
+	assert.NoError(t, err)
+}
+
+func TestEmptyAllowList(t *testing.T) {
+	checker := loadChecker(t, "allowed_destinations: []\nallowed_contract_methods: []\n", nil)
+	_, err := checker.Check(send(testutil.ProducerAddress, types.ZnnTokenStandard, coins(1), nil))
+	assert.Error(t, err)
+	_, err = checker.Check(send(types.PlasmaContract, types.QsrTokenStandard, coins(10), nil))
+	assert.Error(t, err)
+}
+
+func TestSignGuard(t *testing.T) {
+	node := testutil.NewNode(t)
+	c, err := client.New(t.Context(), node.URL)
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	ks, err := wallet.NewKeyStoreFromMnemonic(testutil.MnemonicFixture)
+	require.NoError(t, err)
+	keypair, err := ks.GetKeyPair(0)
+	require.NoError(t, err)
+	address, err := keypair.GetAddress()
+	require.NoError(t, err)
+	node.SetBalance(*address, types.ZnnTokenStandard, coins(10))
+	node.SetPlasma(*address, 21000)
+
+	checker := loadChecker(t, "max_per_transaction:\n  ZNN: \"5\"\n", nil)
+	transaction.SetSignGuard(func(block *nom.AccountBlock) error {
+		_, err := checker.Check(block)
+		return err
+	})
+	defer transaction.SetSignGuard(nil)
+
+	template := c.LedgerApi.SendTemplate(testutil.ProducerAddress, types.ZnnTokenStandard, coins(6), nil)
+	err = transaction.BuildAndSend(t.Context(), c.RpcClient, *address, template, keypair)
+	var violation *Violation
+	require.True(t, errors.As(err, &violation), "expected a violation, got %v", err)
+	assert.Empty(t, node.Blocks(*address), "nothing is signed or published")
+
+	template = c.LedgerApi.SendTemplate(testutil.ProducerAddress, types.ZnnTokenStandard, coins(5), nil)
+	require.NoError(t, transaction.BuildAndSend(t.Context(), c.RpcClient, *address, template, keypair))
+	assert.Equal(t, coins(5), node.Balance(*address, types.ZnnTokenStandard))
+}
@@ -0,0 +1,248 @@
+// Package rewards queries, collects and lists the rewards paid by the
+// embedded contracts: stake, pillar, sentinel and liquidity.
+package rewards
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+)
+
+// Source is an embedded contract that pays rewards
+type Source struct {
+	// Name identifies the source, such as "stake"
+	Name string
+
+	uncollected func(c *rpc_client.RpcClient, address types.Address) (*definition.RewardDeposit, error)
+	history     func(c *rpc_client.RpcClient, address types.Address, pageIndex, pageSize uint32) (*embedded.RewardHistoryList, error)
+	collect     func(c *rpc_client.RpcClient) *nom.AccountBlock
+}
+
+var (
+	// Stake pays rewards for staked ZNN
+	Stake = &Source{
+		Name: "stake",
+		uncollected: func(c *rpc_client.RpcClient, address types.Address) (*definition.RewardDeposit, error) {
+			return c.StakeApi.GetUncollectedReward(address)
+		},
+		history: func(c *rpc_client.RpcClient, address types.Address, pageIndex, pageSize uint32) (*embedded.RewardHistoryList, error) {
+			return c.StakeApi.GetFrontierRewardByPage(address, pageIndex, pageSize)
+		},
+		collect: func(c *rpc_client.RpcClient) *nom.AccountBlock { return c.StakeApi.CollectReward() },
+	}
+
+	// Pillar pays momentum and delegation rewards to pillars and delegators
+	Pillar = &Source{
+		Name: "pillar",
+		uncollected: func(c *rpc_client.RpcClient, address types.Address) (*definition.RewardDeposit, error) {
+			return c.PillarApi.GetUncollectedReward(address)
+		},
+		history: func(c *rpc_client.RpcClient, address types.Address, pageIndex, pageSize uint32) (*embedded.RewardHistoryList, error) {
+			return c.PillarApi.GetFrontierRewardByPage(address, pageIndex, pageSize)
+		},
+		collect: func(c *rpc_client.RpcClient) *nom.AccountBlock { return c.PillarApi.CollectReward() },
+	}
+
+	// Sentinel pays rewards to sentinel owners
+	Sentinel = &Source{
+		Name: "sentinel",
+		uncollected: func(c *rpc_client.RpcClient, address types.Address) (*definition.RewardDeposit, error) {
+			return c.SentinelApi.GetUncollectedReward(address)
+		},
+		history: func(c *rpc_client.RpcClient, address types.Address, pageIndex, pageSize uint32) (*embedded.RewardHistoryList, error) {
+			return c.SentinelApi.GetFrontierRewardByPage(address, pageIndex, pageSize)
+		},
+		collect: func(c *rpc_client.RpcClient) *nom.AccountBlock { return c.SentinelApi.CollectReward() },
+	}
+
+	// Liquidity pays rewards for staked liquidity tokens
+	Liquidity = &Source{
+		Name: "liquidity",
+		uncollected: func(c *rpc_client.RpcClient, address types.Address) (*definition.RewardDeposit, error) {
+			return c.LiquidityApi.GetUncollectedReward(address)
+		},
+		history: func(c *rpc_client.RpcClient, address types.Address, pageIndex, pageSize uint32) (*embedded.RewardHistoryList, error) {
+			return c.LiquidityApi.GetFrontierRewardByPage(address, pageIndex, pageSize)
+		},
+		collect: func(c *rpc_client.RpcClient) *nom.AccountBlock { return c.LiquidityApi.CollectReward() },
+	}
+
+	// Sources lists every reward source
+	Sources = []*Source{Stake, Pillar, Sentinel, Liquidity}
+)
+
+// Reward is the uncollected reward of one source
+type Reward struct {
+	Source *Source
+	Znn    *big.Int
+	Qsr    *big.Int
+}
+
+// IsZero reports whether there is nothing to collect
+func (r *Reward) IsZero() bool {
+	return (r.Znn == nil || r.Znn.Sign() == 0) && (r.Qsr == nil || r.Qsr.Sign() == 0)
+}
+
+// Collected is a reward whose collect block was published
+type Collected struct {
+	Reward
+	Hash types.Hash
+}
+
+// Uncollected returns the uncollected reward of an address
+func (s *Source) Uncollected(c *rpc_client.RpcClient, address types.Address) (*Reward, error) {
+	deposit, err := s.uncollected(c, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get uncollected %s rewards: %w", s.Name, err)
+	}
+	reward := &Reward{Source: s, Znn: new(big.Int), Qsr: new(big.Int)}
+	if deposit != nil {
+		if deposit.Znn != nil {
+			reward.Znn.Set(deposit.Znn)
+		}
+		if deposit.Qsr != nil {
+			reward.Qsr.Set(deposit.Qsr)
+		}
+	}
+	return reward, nil
+}
+
+// History returns a page of the per-epoch reward history of an address, most recent first
+func (s *Source) History(c *rpc_client.RpcClient, address types.Address, pageIndex, pageSize uint32) (*embedded.RewardHistoryList, error) {
+	list, err := s.history(c, address, pageIndex, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s reward history: %w", s.Name, err)
+	}
+	return list, nil
+}
+
+// CollectTemplate returns the block that collects the source's rewards
+func (s *Source) CollectTemplate(c *rpc_client.RpcClient) *nom.AccountBlock {
+	return s.collect(c)
+}
+
+// Lookup returns the sources with the given names, or every source when
+// names is empty
+func Lookup(names []string) ([]*Source, error) {
+	if len(names) == 0 {
+		return Sources, nil
+	}
+
+	var selected []*Source
+	for _, name := range names {
+		source := find(strings.ToLower(strings.TrimSpace(name)))
+		if source == nil {
+			return nil, fmt.Errorf("unknown reward source %q (use %s)", name, strings.Join(Names(), ", "))
+		}
+		selected = append(selected, source)
+	}
+	return selected, nil
+}
+
+// Names returns the names of every source
+func Names() []string {
+	names := make([]string, 0, len(Sources))
+	for _, source := range Sources {
+		names = append(names, source.Name)
+	}
+	return names
+}
+
+// UncollectedAll returns the uncollected rewards of an address for each source
+func UncollectedAll(c *rpc_client.RpcClient, address types.Address, sources []*Source) ([]*Reward, error) {
+	rewards := make([]*Reward, 0, len(sources))
+	for _, source := range sources {
+		reward, err := source.Uncollected(c, address)
+		if err != nil {
+			return nil, err
+		}
+		rewards = append(rewards, reward)
+	}
+	return rewards, nil
+}
+
+// Total sums the ZNN and QSR of rewards
+func Total(rewards []*Reward) (*big.Int, *big.Int) {
+	znn := new(big.Int)
+	qsr := new(big.Int)
+	for _, reward := range rewards {
+		if reward.Znn != nil {
+			znn.Add(znn, reward.Znn)
+		}
+		if reward.Qsr != nil {
+			qsr.Add(qsr, reward.Qsr)
+		}
+	}
+	return znn, qsr
+}
+
+// Collect publishes a collect block for every source with a non-zero
+// uncollected reward. The rewards collected before an error are returned
+// with it.
+func Collect(ctx context.Context, c *rpc_client.RpcClient, address types.Address, keypair *wallet.KeyPair, sources []*Source) ([]*Collected, error) {
+	var collected []*Collected
+	for _, source := range sources {
+		reward, err := source.Uncollected(c, address)
+		if err != nil {
+			return collected, err
+		}
+		if reward.IsZero() {
+			continue
+		}
+
+		template := source.CollectTemplate(c)
+		if err := transaction.BuildAndSend(ctx, c, address, template, keypair); err != nil {
+			return collected, fmt.Errorf("failed to collect %s rewards: %w", source.Name, err)
+		}
+		collected = append(collected, &Collected{Reward: *reward, Hash: template.Hash})
+	}
+	return collected, nil
+}
+
+// Pending sums the ZNN and QSR of the unreceived blocks of an address
+func Pending(c *rpc_client.RpcClient, address types.Address) (*big.Int, *big.Int, error) {
+	const pageSize = 50
+
+	znn := new(big.Int)
+	qsr := new(big.Int)
+	for pageIndex := uint32(0); ; pageIndex++ {
+		blocks, err := c.LedgerApi.GetUnreceivedBlocksByAddress(address, pageIndex, pageSize)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get unreceived blocks: %w", err)
+		}
+		for _, block := range blocks.List {
+			if block.Amount == nil {
+				continue
+			}
+			switch block.TokenStandard {
+			case types.ZnnTokenStandard:
+				znn.Add(znn, block.Amount)
+			case types.QsrTokenStandard:
+				qsr.Add(qsr, block.Amount)
+			}
+		}
+		if !blocks.More || len(blocks.List) == 0 {
+			return znn, qsr, nil
+		}
+	}
+}
+
+// find returns the source with the given name
+func find(name string) *Source {
+	for _, source := range Sources {
+		if source.Name == name {
+			return source
+		}
+	}
+	return nil
+}
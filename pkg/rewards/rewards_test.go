@@ -0,0 +1,47 @@
+package rewards
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	all, err := Lookup(nil)
+	require.NoError(t, err)
+	assert.Equal(t, Sources, all)
+
+	selected, err := Lookup([]string{"Liquidity", " stake "})
+	require.NoError(t, err)
+	assert.Equal(t, []*Source{Liquidity, Stake}, selected)
+
+	_, err = Lookup([]string{"bridge"})
+	assert.ErrorContains(t, err, "unknown reward source")
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, []string{"stake", "pillar", "sentinel", "liquidity"}, Names())
+}
+
+func TestRewardIsZero(t *testing.T) {
+	assert.True(t, (&Reward{}).IsZero())
+	assert.True(t, (&Reward{Znn: big.NewInt(0), Qsr: big.NewInt(0)}).IsZero())
+	assert.False(t, (&Reward{Znn: big.NewInt(0), Qsr: big.NewInt(1)}).IsZero())
+	assert.False(t, (&Reward{Znn: big.NewInt(5)}).IsZero())
+}
+
+func TestTotal(t *testing.T) {
+	znn, qsr := Total([]*Reward{
+		{Source: Stake, Znn: big.NewInt(100), Qsr: big.NewInt(10)},
+		{Source: Pillar, Znn: big.NewInt(50)},
+		{Source: Liquidity, Qsr: big.NewInt(5)},
+	})
+	assert.Equal(t, "150", znn.String())
+	assert.Equal(t, "15", qsr.String())
+
+	znn, qsr = Total(nil)
+	assert.Equal(t, 0, znn.Sign())
+	assert.Equal(t, 0, qsr.Sign())
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/0x3639/znn_cli_go/pkg/service"
+)
+
+// idRequest is the body of endpoints that take a hash or entry ID
+type idRequest struct {
+	ID string `json:"id"`
+}
+
+// hashRequest is the body of the receive endpoint
+type hashRequest struct {
+	Hash string `json:"hash"`
+}
+
+func handleBalance(svc *service.Service, r *http.Request) (any, error) {
+	return svc.Balance()
+}
+
+func handleHistory(svc *service.Service, r *http.Request) (any, error) {
+	pageIndex, pageSize, err := page(r)
+	if err != nil {
+		return nil, err
+	}
+	return svc.History(pageIndex, pageSize)
+}
+
+func handleUnreceived(svc *service.Service, r *http.Request) (any, error) {
+	pageIndex, pageSize, err := page(r)
+	if err != nil {
+		return nil, err
+	}
+	return svc.Unreceived(pageIndex, pageSize)
+}
+
+func handleMomentum(svc *service.Service, r *http.Request) (any, error) {
+	return svc.FrontierMomentum()
+}
+
+func handlePlasma(svc *service.Service, r *http.Request) (any, error) {
+	return svc.Plasma()
+}
+
+func handleFusions(svc *service.Service, r *http.Request) (any, error) {
+	pageIndex, pageSize, err := page(r)
+	if err != nil {
+		return nil, err
+	}
+	return svc.Fusions(pageIndex, pageSize)
+}
+
+func handleStakes(svc *service.Service, r *http.Request) (any, error) {
+	pageIndex, pageSize, err := page(r)
+	if err != nil {
+		return nil, err
+	}
+	return svc.Stakes(pageIndex, pageSize)
+}
+
+func handleSend(svc *service.Service, r *http.Request) (any, error) {
+	var req service.SendRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return svc.Send(req)
+}
+
+func handleReceive(svc *service.Service, r *http.Request) (any, error) {
+	var req hashRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return svc.Receive(req.Hash)
+}
+
+func handleReceiveAll(svc *service.Service, r *http.Request) (any, error) {
+	return svc.ReceiveAll()
+}
+
+func handleFuse(svc *service.Service, r *http.Request) (any, error) {
+	var req service.FuseRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return svc.Fuse(req)
+}
+
+func handleCancelFusion(svc *service.Service, r *http.Request) (any, error) {
+	var req idRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return svc.CancelFusion(req.ID)
+}
+
+func handleStake(svc *service.Service, r *http.Request) (any, error) {
+	var req service.StakeRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return svc.Stake(req)
+}
+
+func handleRevokeStake(svc *service.Service, r *http.Request) (any, error) {
+	var req idRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return svc.RevokeStake(req.ID)
+}
+
+func handleCollectStakeRewards(svc *service.Service, r *http.Request) (any, error) {
+	return svc.CollectStakeRewards()
+}
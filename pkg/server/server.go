@@ -0,0 +1,286 @@
+// Package server exposes the wallet service as a local HTTP/JSON API.
+// Requests are authenticated with bearer tokens, and each token is limited
+// to an allow-list of endpoints so that read-only tokens cannot sign.
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/0x3639/znn_cli_go/pkg/config"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/service"
+)
+
+const (
+	// AllowRead grants access to every read-only endpoint
+	AllowRead = "read"
+
+	// AllowAll grants access to every endpoint
+	AllowAll = "*"
+
+	// UnixPrefix marks a listen address as a Unix socket path
+	UnixPrefix = "unix:"
+
+	// maxBodySize limits request bodies (data payloads are at most 16 KB)
+	maxBodySize = 1 << 20
+)
+
+// Endpoint is a single API operation
+type Endpoint struct {
+	// Name is used in token allow-lists
+	Name string
+	// Method and Path route the endpoint
+	Method string
+	Path   string
+	// Signs marks endpoints that publish account blocks
+	Signs bool
+
+	handle func(svc *service.Service, r *http.Request) (any, error)
+}
+
+// Endpoints lists every API endpoint
+var Endpoints = []Endpoint{
+	{Name: "balance", Method: http.MethodGet, Path: "/v1/balance", handle: handleBalance},
+	{Name: "history", Method: http.MethodGet, Path: "/v1/history", handle: handleHistory},
+	{Name: "unreceived", Method: http.MethodGet, Path: "/v1/unreceived", handle: handleUnreceived},
+	{Name: "momentum", Method: http.MethodGet, Path: "/v1/momentum", handle: handleMomentum},
+	{Name: "plasma", Method: http.MethodGet, Path: "/v1/plasma", handle: handlePlasma},
+	{Name: "fusions", Method: http.MethodGet, Path: "/v1/plasma/fusions", handle: handleFusions},
+	{Name: "stakes", Method: http.MethodGet, Path: "/v1/stakes", handle: handleStakes},
+	{Name: "send", Method: http.MethodPost, Path: "/v1/send", Signs: true, handle: handleSend},
+	{Name: "receive", Method: http.MethodPost, Path: "/v1/receive", Signs: true, handle: handleReceive},
+	{Name: "receiveAll", Method: http.MethodPost, Path: "/v1/receive-all", Signs: true, handle: handleReceiveAll},
+	{Name: "fuse", Method: http.MethodPost, Path: "/v1/plasma/fuse", Signs: true, handle: handleFuse},
+	{Name: "cancelFusion", Method: http.MethodPost, Path: "/v1/plasma/cancel", Signs: true, handle: handleCancelFusion},
+	{Name: "stake", Method: http.MethodPost, Path: "/v1/stake", Signs: true, handle: handleStake},
+	{Name: "revokeStake", Method: http.MethodPost, Path: "/v1/stake/revoke", Signs: true, handle: handleRevokeStake},
+	{Name: "collectStakeRewards", Method: http.MethodPost, Path: "/v1/stake/collect", Signs: true, handle: handleCollectStakeRewards},
+}
+
+// Server serves the API for one wallet service
+type Server struct {
+	svc    *service.Service
+	tokens []config.APITokenConfig
+}
+
+// New creates an API server. At least one token is required.
+func New(svc *service.Service, tokens []config.APITokenConfig) (*Server, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no API tokens configured")
+	}
+	for _, token := range tokens {
+		if len(token.Token) < 16 {
+			return nil, fmt.Errorf("API token %q must be at least 16 characters", token.Name)
+		}
+		if err := ValidateAllow(token.Allow); err != nil {
+			return nil, fmt.Errorf("API token %q: %w", token.Name, err)
+		}
+	}
+	return &Server{svc: svc, tokens: tokens}, nil
+}
+
+// Handler returns the HTTP handler serving all endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	for _, endpoint := range Endpoints {
+		mux.Handle(endpoint.Method+" "+endpoint.Path, s.endpointHandler(endpoint))
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, errs.New(errs.UserInput, "unknown endpoint"))
+	})
+	return mux
+}
+
+// endpointHandler authenticates and authorizes a request before running the endpoint
+func (s *Server) endpointHandler(endpoint Endpoint) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := s.authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errs.New(errs.Wallet, "missing or invalid bearer token"))
+			return
+		}
+		if !Allows(token.Allow, endpoint) {
+			writeError(w, http.StatusForbidden, errs.Errorf(errs.Wallet, "token %q is not allowed to call %s", token.Name, endpoint.Name))
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+		result, err := endpoint.handle(s.svc, r)
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+}
+
+// authenticate returns the configured token matching the request's bearer token
+func (s *Server) authenticate(r *http.Request) (config.APITokenConfig, bool) {
+	value, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || value == "" {
+		return config.APITokenConfig{}, false
+	}
+
+	for _, token := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(value), []byte(token.Token)) == 1 {
+			return token, true
+		}
+	}
+	return config.APITokenConfig{}, false
+}
+
+// Allows reports whether an allow-list grants access to an endpoint
+func Allows(allow []string, endpoint Endpoint) bool {
+	for _, entry := range allow {
+		switch {
+		case entry == AllowAll:
+			return true
+		case entry == AllowRead && !endpoint.Signs:
+			return true
+		case entry == endpoint.Name:
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAllow checks that every allow-list entry is "read", "*" or an endpoint name
+func ValidateAllow(allow []string) error {
+	if len(allow) == 0 {
+		return fmt.Errorf("allow-list is empty")
+	}
+	for _, entry := range allow {
+		if entry == AllowRead || entry == AllowAll {
+			continue
+		}
+		known := false
+		for _, endpoint := range Endpoints {
+			if endpoint.Name == entry {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown endpoint %q in allow-list", entry)
+		}
+	}
+	return nil
+}
+
+// Listen opens the listener for an address. Addresses prefixed with "unix:"
+// are Unix socket paths; TCP addresses must be loopback unless allowRemote is set.
+func Listen(address string, allowRemote bool) (net.Listener, error) {
+	if path, found := strings.CutPrefix(address, UnixPrefix); found {
+		// Remove a stale socket left by a previous run
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(path)
+		}
+		listener, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+		}
+		if err := os.Chmod(path, 0600); err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+		}
+		return listener, nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid listen address %s: %w", address, err)
+	}
+	if !allowRemote && !isLoopback(host) {
+		return nil, fmt.Errorf("refusing to listen on non-loopback address %s (use --allow-remote)", address)
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	return listener, nil
+}
+
+// isLoopback reports whether a host is localhost or a loopback IP
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// statusFor maps service errors to HTTP status codes
+func statusFor(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, service.ErrInvalidRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge
+	}
+	switch errs.KindOf(err) {
+	case errs.UserInput, errs.InsufficientFunds:
+		return http.StatusBadRequest
+	case errs.Policy:
+		return http.StatusForbidden
+	case errs.Timeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes the JSON error object of an error
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errs.ToObject(err))
+}
+
+// decode reads a JSON request body into value
+func decode(r *http.Request, value any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return errs.Wrap(errs.UserInput, err)
+		}
+		return fmt.Errorf("%w: invalid JSON body: %v", service.ErrInvalidRequest, err)
+	}
+	return nil
+}
+
+// page reads the page and size query parameters
+func page(r *http.Request) (uint32, uint32, error) {
+	pageIndex, pageSize := uint64(0), uint64(25)
+	var err error
+	if value := r.URL.Query().Get("page"); value != "" {
+		if pageIndex, err = strconv.ParseUint(value, 10, 32); err != nil {
+			return 0, 0, fmt.Errorf("%w: invalid page", service.ErrInvalidRequest)
+		}
+	}
+	if value := r.URL.Query().Get("size"); value != "" {
+		if pageSize, err = strconv.ParseUint(value, 10, 32); err != nil || pageSize == 0 || pageSize > 1024 {
+			return 0, 0, fmt.Errorf("%w: size must be between 1 and 1024", service.ErrInvalidRequest)
+		}
+	}
+	return uint32(pageIndex), uint32(pageSize), nil
+}
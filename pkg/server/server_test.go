@@ -0,0 +1,124 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0x3639/znn_cli_go/pkg/config"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	readToken  = "read-token-0123456789"
+	writeToken = "send-token-0123456789"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	srv, err := New(nil, []config.APITokenConfig{
+		{Name: "monitor", Token: readToken, Allow: []string{AllowRead}},
+		{Name: "payouts", Token: writeToken, Allow: []string{"send"}},
+	})
+	require.NoError(t, err)
+	return srv
+}
+
+// TestHandlerAuthorization verifies bearer token checks and allow-lists
+func TestHandlerAuthorization(t *testing.T) {
+	handler := newTestServer(t).Handler()
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		expected int
+		kind     errs.Kind
+	}{
+		{name: "missing token", method: http.MethodGet, path: "/v1/balance", expected: http.StatusUnauthorized, kind: errs.Wallet},
+		{name: "wrong token", method: http.MethodGet, path: "/v1/balance", token: "wrong-token-0123456789", expected: http.StatusUnauthorized, kind: errs.Wallet},
+		{name: "read token on send", method: http.MethodPost, path: "/v1/send", token: readToken, expected: http.StatusForbidden, kind: errs.Wallet},
+		{name: "read token on fuse", method: http.MethodPost, path: "/v1/plasma/fuse", token: readToken, expected: http.StatusForbidden, kind: errs.Wallet},
+		{name: "send token on balance", method: http.MethodGet, path: "/v1/balance", token: writeToken, expected: http.StatusForbidden, kind: errs.Wallet},
+		{name: "send token on stake", method: http.MethodPost, path: "/v1/stake", token: writeToken, expected: http.StatusForbidden, kind: errs.Wallet},
+		{name: "invalid body", method: http.MethodPost, path: "/v1/send", token: writeToken, expected: http.StatusBadRequest, kind: errs.UserInput},
+		{name: "unknown endpoint", method: http.MethodGet, path: "/v1/unknown", token: readToken, expected: http.StatusNotFound, kind: errs.UserInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{"))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var object errs.Object
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &object))
+			assert.NotEmpty(t, object.Error)
+			assert.Equal(t, tt.kind, object.Kind)
+			assert.Equal(t, tt.kind.ExitCode(), object.ExitCode)
+		})
+	}
+}
+
+// TestAllows verifies allow-list matching
+func TestAllows(t *testing.T) {
+	var send, balance Endpoint
+	for _, endpoint := range Endpoints {
+		switch endpoint.Name {
+		case "send":
+			send = endpoint
+		case "balance":
+			balance = endpoint
+		}
+	}
+
+	assert.True(t, Allows([]string{AllowRead}, balance))
+	assert.False(t, Allows([]string{AllowRead}, send))
+	assert.True(t, Allows([]string{AllowAll}, send))
+	assert.True(t, Allows([]string{"send"}, send))
+	assert.False(t, Allows([]string{"send"}, balance))
+	assert.False(t, Allows(nil, balance))
+}
+
+// TestNewValidation verifies token configuration checks
+func TestNewValidation(t *testing.T) {
+	_, err := New(nil, nil)
+	assert.Error(t, err)
+
+	_, err = New(nil, []config.APITokenConfig{{Name: "short", Token: "abc", Allow: []string{AllowRead}}})
+	assert.Error(t, err)
+
+	_, err = New(nil, []config.APITokenConfig{{Name: "typo", Token: readToken, Allow: []string{"sned"}}})
+	assert.Error(t, err)
+
+	_, err = New(nil, []config.APITokenConfig{{Name: "empty", Token: readToken}})
+	assert.Error(t, err)
+}
+
+// TestListen verifies loopback enforcement and Unix sockets
+func TestListen(t *testing.T) {
+	_, err := Listen("0.0.0.0:0", false)
+	assert.Error(t, err)
+
+	listener, err := Listen("127.0.0.1:0", false)
+	require.NoError(t, err)
+	_ = listener.Close()
+
+	path := filepath.Join(t.TempDir(), "api.sock")
+	listener, err = Listen(UnixPrefix+path, false)
+	require.NoError(t, err)
+	_ = listener.Close()
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+)
+
+// TokenBalance is the balance of one token
+type TokenBalance struct {
+	TokenStandard string `json:"tokenStandard"`
+	Symbol        string `json:"symbol"`
+	Decimals      int    `json:"decimals"`
+	Balance       string `json:"balance"`
+	Formatted     string `json:"formatted"`
+}
+
+// Balance is the account state of an address
+type Balance struct {
+	Address  string         `json:"address"`
+	Height   uint64         `json:"height"`
+	Balances []TokenBalance `json:"balances"`
+}
+
+// Block is a simplified view of an account block
+type Block struct {
+	Hash          string `json:"hash"`
+	Height        uint64 `json:"height"`
+	Direction     string `json:"direction"`
+	Counterparty  string `json:"counterparty"`
+	TokenStandard string `json:"tokenStandard,omitempty"`
+	Symbol        string `json:"symbol,omitempty"`
+	Amount        string `json:"amount,omitempty"`
+	Formatted     string `json:"formatted,omitempty"`
+	Memo          string `json:"memo,omitempty"`
+	Momentum      uint64 `json:"momentum,omitempty"`
+}
+
+// BlockPage is a page of account blocks
+type BlockPage struct {
+	Count  int     `json:"count"`
+	More   bool    `json:"more"`
+	Blocks []Block `json:"blocks"`
+}
+
+// Momentum is the frontier momentum summary
+type Momentum struct {
+	Height    uint64 `json:"height"`
+	Hash      string `json:"hash"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Balance returns the balances of the service address
+func (s *Service) Balance() (*Balance, error) {
+	info, err := s.Client.LedgerApi.GetAccountInfoByAddress(s.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account info: %w", err)
+	}
+
+	result := &Balance{
+		Address:  s.Address.String(),
+		Height:   info.AccountHeight,
+		Balances: []TokenBalance{},
+	}
+	for zts, balanceInfo := range info.BalanceInfoMap {
+		if balanceInfo.TokenInfo == nil || balanceInfo.Balance == nil {
+			continue
+		}
+		decimals := int(balanceInfo.TokenInfo.Decimals)
+		result.Balances = append(result.Balances, TokenBalance{
+			TokenStandard: zts.String(),
+			Symbol:        balanceInfo.TokenInfo.TokenSymbol,
+			Decimals:      decimals,
+			Balance:       balanceInfo.Balance.String(),
+			Formatted:     format.Amount(balanceInfo.Balance, decimals),
+		})
+	}
+	sort.Slice(result.Balances, func(i, j int) bool {
+		return result.Balances[i].TokenStandard < result.Balances[j].TokenStandard
+	})
+
+	return result, nil
+}
+
+// History returns a page of account blocks of the service address, newest first
+func (s *Service) History(pageIndex, pageSize uint32) (*BlockPage, error) {
+	blocks, err := s.Client.LedgerApi.GetAccountBlocksByPage(s.Address, pageIndex, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account blocks: %w", err)
+	}
+	return s.newBlockPage(blocks), nil
+}
+
+// Unreceived returns a page of blocks sent to the service address that are not yet received
+func (s *Service) Unreceived(pageIndex, pageSize uint32) (*BlockPage, error) {
+	blocks, err := s.Client.LedgerApi.GetUnreceivedBlocksByAddress(s.Address, pageIndex, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unreceived blocks: %w", err)
+	}
+	return s.newBlockPage(blocks), nil
+}
+
+// FrontierMomentum returns the latest momentum
+func (s *Service) FrontierMomentum() (*Momentum, error) {
+	momentum, err := s.Client.LedgerApi.GetFrontierMomentum()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frontier momentum: %w", err)
+	}
+
+	return &Momentum{
+		Height:    momentum.Height,
+		Hash:      momentum.Hash.String(),
+		Timestamp: int64(momentum.TimestampUnix),
+	}, nil
+}
+
+// newBlockPage converts an account block list to a block page
+func (s *Service) newBlockPage(list *api.AccountBlockList) *BlockPage {
+	page := &BlockPage{
+		Count:  list.Count,
+		More:   list.More,
+		Blocks: make([]Block, 0, len(list.List)),
+	}
+	for _, block := range list.List {
+		page.Blocks = append(page.Blocks, s.newBlock(block))
+	}
+	return page
+}
+
+// newBlock builds the view of an account block. Receive blocks take their
+// amount, token and memo from the paired send block; send blocks to the
+// service address are unreceived incoming blocks.
+func (s *Service) newBlock(block *api.AccountBlock) Block {
+	view := Block{
+		Hash:   block.Hash.String(),
+		Height: block.Height,
+	}
+	if block.ConfirmationDetail != nil {
+		view.Momentum = block.ConfirmationDetail.MomentumHeight
+	}
+
+	send := block
+	if nom.IsReceiveBlock(block.BlockType) {
+		view.Direction = "received"
+		send = block.PairedAccountBlock
+		if send == nil {
+			return view
+		}
+		view.Counterparty = send.Address.String()
+	} else if send.ToAddress == s.Address {
+		view.Direction = "incoming"
+		view.Counterparty = send.Address.String()
+	} else {
+		view.Direction = "sent"
+		view.Counterparty = send.ToAddress.String()
+	}
+
+	if send.Amount != nil && send.Amount.Sign() > 0 && send.TokenInfo != nil {
+		view.TokenStandard = send.TokenStandard.String()
+		view.Symbol = send.TokenInfo.TokenSymbol
+		view.Amount = send.Amount.String()
+		view.Formatted = format.Amount(send.Amount, int(send.TokenInfo.Decimals))
+	}
+
+	// Data sent to embedded contracts is an encoded method call, not a memo
+	if !types.IsEmbeddedAddress(send.ToAddress) {
+		view.Memo = format.Memo(send.Data)
+	}
+
+	return view
+}
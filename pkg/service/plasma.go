@@ -0,0 +1,134 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+)
+
+const (
+	// MinFuseAmount is the minimum QSR that can be fused (10 QSR)
+	MinFuseAmount = 10 * 1e8
+
+	// oneQsr is 1 QSR in base units
+	oneQsr = 1e8
+)
+
+// FuseRequest describes a QSR fusion for plasma
+type FuseRequest struct {
+	Beneficiary string `json:"beneficiary"`
+	Amount      string `json:"amount"`
+	Raw         bool   `json:"raw,omitempty"`
+}
+
+// Plasma returns the plasma of the service address
+func (s *Service) Plasma() (*embedded.PlasmaInfo, error) {
+	info, err := s.Client.PlasmaApi.Get(s.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plasma info: %w", err)
+	}
+	return info, nil
+}
+
+// Fusions returns a page of fusion entries of the service address
+func (s *Service) Fusions(pageIndex, pageSize uint32) (*embedded.FusionEntryList, error) {
+	list, err := s.Client.PlasmaApi.GetEntriesByAddress(s.Address, pageIndex, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fusion entries: %w", err)
+	}
+	return list, nil
+}
+
+// PrepareFuse validates a fusion against the QSR balance and builds its block.
+// max and percentages are rounded down to a whole QSR.
+func (s *Service) PrepareFuse(req FuseRequest) (*Prepared, error) {
+	beneficiary, err := s.Config.ResolveAddress(req.Beneficiary)
+	if err != nil {
+		return nil, invalid("invalid beneficiary address: %v", err)
+	}
+
+	balance, err := s.balanceOf(types.QsrTokenStandard)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve amount against the QSR balance (QSR has 8 decimals)
+	amount, err := format.ResolveAmount(req.Amount, format.CoinDecimals, balance, req.Raw)
+	if err != nil {
+		return nil, invalid("invalid amount: %v", err)
+	}
+
+	unit := big.NewInt(oneQsr)
+	if format.IsRelativeAmount(req.Amount) {
+		amount.Sub(amount, new(big.Int).Mod(amount, unit))
+	}
+
+	if amount.Cmp(big.NewInt(MinFuseAmount)) < 0 {
+		return nil, invalid("invalid amount: %s QSR. Minimum fuse amount is %s",
+			format.Amount(amount, format.CoinDecimals),
+			format.Amount(big.NewInt(MinFuseAmount), format.CoinDecimals))
+	}
+	if new(big.Int).Mod(amount, unit).Sign() != 0 {
+		return nil, invalid("amount must be a whole number (no decimals)")
+	}
+	if balance.Cmp(amount) < 0 {
+		return nil, insufficient("insufficient QSR balance. You have %s but need %s",
+			format.Amount(balance, format.CoinDecimals),
+			format.Amount(amount, format.CoinDecimals))
+	}
+
+	return &Prepared{
+		Template:   s.Client.PlasmaApi.Fuse(beneficiary, amount),
+		Expression: req.Amount,
+		Amount:     amount,
+		Decimals:   format.CoinDecimals,
+		Symbol:     "QSR",
+	}, nil
+}
+
+// Fuse validates and publishes a QSR fusion
+func (s *Service) Fuse(req FuseRequest) (*TxResult, error) {
+	prepared, err := s.PrepareFuse(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.Submit(prepared)
+}
+
+// CancelFusion cancels an expired fusion entry of the service address
+func (s *Service) CancelFusion(id string) (*TxResult, error) {
+	var fusionID types.Hash
+	if err := fusionID.UnmarshalText([]byte(strings.TrimSpace(id))); err != nil {
+		return nil, invalid("invalid fusion ID: %v", err)
+	}
+
+	momentum, err := s.Client.LedgerApi.GetFrontierMomentum()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frontier momentum: %w", err)
+	}
+
+	for pageIndex := uint32(0); ; pageIndex++ {
+		list, err := s.Fusions(pageIndex, 25)
+		if err != nil {
+			return nil, err
+		}
+		if len(list.Fusions) == 0 {
+			return nil, fmt.Errorf("%w: no fusion entry with ID %s", ErrNotFound, fusionID)
+		}
+
+		for _, entry := range list.Fusions {
+			if entry.Id != fusionID {
+				continue
+			}
+			if entry.ExpirationHeight > momentum.Height {
+				return nil, invalid("fusion entry can be canceled at momentum height %d (current: %d)",
+					entry.ExpirationHeight, momentum.Height)
+			}
+			return s.Submit(&Prepared{Template: s.Client.PlasmaApi.Cancel(fusionID)})
+		}
+	}
+}
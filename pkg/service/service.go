@@ -0,0 +1,134 @@
+// Package service implements wallet and chain operations shared by the CLI
+// commands and the local API server. Each operation validates its input,
+// queries the node and, for state-changing operations, builds, signs and
+// publishes the account block for the service's address.
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/0x3639/znn_cli_go/pkg/config"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+)
+
+var (
+	// ErrInvalidRequest is wrapped by errors caused by invalid input
+	ErrInvalidRequest = errs.New(errs.UserInput, "invalid request")
+
+	// ErrNotFound is wrapped by errors for entries that do not exist
+	ErrNotFound = errs.New(errs.UserInput, "not found")
+)
+
+// Service performs operations for a single wallet address
+type Service struct {
+	Client  *rpc_client.RpcClient
+	Config  *config.Config
+	KeyPair *wallet.KeyPair
+	Address types.Address
+
+	// ctx interrupts node queries and publishing for the lifetime of the service
+	ctx context.Context
+
+	// mu serializes publishing so concurrent callers don't race for the same block height
+	mu sync.Mutex
+}
+
+// New creates a service for the address of the given keypair. Operations
+// in progress are interrupted once ctx is done.
+func New(ctx context.Context, c *rpc_client.RpcClient, cfg *config.Config, keypair *wallet.KeyPair) (*Service, error) {
+	address, err := keypair.GetAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get address: %w", err)
+	}
+
+	return &Service{
+		Client:  c,
+		Config:  cfg,
+		KeyPair: keypair,
+		Address: *address,
+		ctx:     ctx,
+	}, nil
+}
+
+// Prepared is a validated account block that is ready to be published
+type Prepared struct {
+	Template   *nom.AccountBlock
+	Expression string
+	Amount     *big.Int
+	Decimals   int
+	Symbol     string
+}
+
+// Describe formats the amount expression of the prepared block with its resolved value
+func (p *Prepared) Describe() string {
+	if p.Amount == nil {
+		return ""
+	}
+	return format.AmountExpression(p.Expression, p.Amount, p.Decimals, p.Symbol)
+}
+
+// TxResult describes a published account block
+type TxResult struct {
+	Hash      string `json:"hash"`
+	Amount    string `json:"amount,omitempty"`
+	Formatted string `json:"formatted,omitempty"`
+	PoW       bool   `json:"pow"`
+}
+
+// Submit builds, signs and publishes a prepared block
+func (s *Service) Submit(p *Prepared) (*TxResult, error) {
+	if err := s.publish(p.Template); err != nil {
+		return nil, err
+	}
+
+	result := &TxResult{
+		Hash: p.Template.Hash.String(),
+		PoW:  p.Template.Difficulty > 0,
+	}
+	if p.Amount != nil {
+		result.Amount = p.Amount.String()
+		result.Formatted = format.Amount(p.Amount, p.Decimals) + " " + p.Symbol
+	}
+	return result, nil
+}
+
+// publish builds, signs and publishes a template for the service address
+func (s *Service) publish(template *nom.AccountBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return transaction.BuildAndSend(s.ctx, s.Client, s.Address, template, s.KeyPair)
+}
+
+// invalid returns an error wrapping ErrInvalidRequest
+func invalid(msg string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRequest, fmt.Sprintf(msg, args...))
+}
+
+// insufficient returns an invalid request error for a balance that is too low
+func insufficient(msg string, args ...any) error {
+	return errs.Wrap(errs.InsufficientFunds, invalid(msg, args...))
+}
+
+// balanceOf returns the balance of a token at the service address, or zero
+func (s *Service) balanceOf(zts types.ZenonTokenStandard) (*big.Int, error) {
+	info, err := s.Client.LedgerApi.GetAccountInfoByAddress(s.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account info: %w", err)
+	}
+
+	if balanceInfo, found := info.BalanceInfoMap[zts]; found && balanceInfo.Balance != nil {
+		return balanceInfo.Balance, nil
+	}
+	return big.NewInt(0), nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+)
+
+const (
+	// MinStakeAmount is the minimum ZNN that can be staked (1 ZNN)
+	MinStakeAmount = 1 * 1e8
+
+	// StakeTimeUnit is one month in seconds (30 days)
+	StakeTimeUnit = 30 * 24 * 60 * 60
+
+	// MinStakeMonths is the minimum staking duration
+	MinStakeMonths = 1
+
+	// MaxStakeMonths is the maximum staking duration
+	MaxStakeMonths = 12
+)
+
+// StakeRequest describes a ZNN stake
+type StakeRequest struct {
+	Amount string `json:"amount"`
+	Months int64  `json:"months"`
+	Raw    bool   `json:"raw,omitempty"`
+}
+
+// Stakes returns a page of stake entries of the service address
+func (s *Service) Stakes(pageIndex, pageSize uint32) (*embedded.StakeList, error) {
+	list, err := s.Client.StakeApi.GetEntriesByAddress(s.Address, pageIndex, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stake entries: %w", err)
+	}
+	return list, nil
+}
+
+// PrepareStake validates a stake against the ZNN balance and builds its block
+func (s *Service) PrepareStake(req StakeRequest) (*Prepared, error) {
+	if req.Months < MinStakeMonths || req.Months > MaxStakeMonths {
+		return nil, invalid("invalid duration: must be between %d and %d months", MinStakeMonths, MaxStakeMonths)
+	}
+
+	balance, err := s.balanceOf(types.ZnnTokenStandard)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve amount against the ZNN balance (ZNN has 8 decimals)
+	amount, err := format.ResolveAmount(req.Amount, format.CoinDecimals, balance, req.Raw)
+	if err != nil {
+		return nil, invalid("invalid amount: %v", err)
+	}
+	if amount.Cmp(big.NewInt(MinStakeAmount)) < 0 {
+		return nil, invalid("invalid amount: minimum stake amount is %s ZNN",
+			format.Amount(big.NewInt(MinStakeAmount), format.CoinDecimals))
+	}
+	if balance.Cmp(amount) < 0 {
+		return nil, insufficient("insufficient ZNN balance. You have %s but need %s",
+			format.Amount(balance, format.CoinDecimals),
+			format.Amount(amount, format.CoinDecimals))
+	}
+
+	return &Prepared{
+		Template:   s.Client.StakeApi.Stake(req.Months*StakeTimeUnit, amount),
+		Expression: req.Amount,
+		Amount:     amount,
+		Decimals:   format.CoinDecimals,
+		Symbol:     "ZNN",
+	}, nil
+}
+
+// Stake validates and publishes a ZNN stake
+func (s *Service) Stake(req StakeRequest) (*TxResult, error) {
+	prepared, err := s.PrepareStake(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.Submit(prepared)
+}
+
+// RevokeStake revokes an expired stake entry of the service address
+func (s *Service) RevokeStake(id string) (*TxResult, error) {
+	var stakeID types.Hash
+	if err := stakeID.UnmarshalText([]byte(strings.TrimSpace(id))); err != nil {
+		return nil, invalid("invalid stake ID: %v", err)
+	}
+
+	for pageIndex := uint32(0); ; pageIndex++ {
+		list, err := s.Stakes(pageIndex, 25)
+		if err != nil {
+			return nil, err
+		}
+		if len(list.Entries) == 0 {
+			return nil, fmt.Errorf("%w: no stake entry with ID %s", ErrNotFound, stakeID)
+		}
+
+		for _, entry := range list.Entries {
+			if entry.Id != stakeID {
+				continue
+			}
+			if entry.ExpirationTimestamp > time.Now().Unix() {
+				return nil, invalid("stake entry can be revoked at %s",
+					time.Unix(entry.ExpirationTimestamp, 0).Format("2006-01-02 15:04:05"))
+			}
+			return s.Submit(&Prepared{Template: s.Client.StakeApi.Cancel(stakeID)})
+		}
+	}
+}
+
+// CollectStakeRewards collects uncollected stake rewards
+func (s *Service) CollectStakeRewards() (*TxResult, error) {
+	reward, err := s.Client.StakeApi.GetUncollectedReward(s.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get uncollected rewards: %w", err)
+	}
+	if reward.Znn.Sign() == 0 && reward.Qsr.Sign() == 0 {
+		return nil, invalid("nothing to collect")
+	}
+
+	return s.Submit(&Prepared{Template: s.Client.StakeApi.CollectReward()})
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+)
+
+// SendRequest describes a token transfer
+type SendRequest struct {
+	To         string `json:"to"`
+	Amount     string `json:"amount"`
+	Token      string `json:"token"`
+	Raw        bool   `json:"raw,omitempty"`
+	Memo       string `json:"memo,omitempty"`
+	DataHex    string `json:"dataHex,omitempty"`
+	DataBase64 string `json:"dataBase64,omitempty"`
+}
+
+// ReceiveResult describes the outcome of receiving all pending blocks
+type ReceiveResult struct {
+	Received int `json:"received"`
+}
+
+// ParseToken parses ZNN, QSR or a zts1... token standard
+func ParseToken(token string) (types.ZenonTokenStandard, error) {
+	switch strings.ToUpper(strings.TrimSpace(token)) {
+	case "ZNN":
+		return types.ZnnTokenStandard, nil
+	case "QSR":
+		return types.QsrTokenStandard, nil
+	}
+
+	zts, err := types.ParseZTS(strings.TrimSpace(token))
+	if err != nil {
+		return types.ZeroTokenStandard, invalid("invalid token standard (use ZNN/QSR or zts1...): %v", err)
+	}
+	return zts, nil
+}
+
+// PrepareSend validates a transfer against the current balance and builds its block
+func (s *Service) PrepareSend(req SendRequest) (*Prepared, error) {
+	toAddress, err := s.Config.ResolveAddress(req.To)
+	if err != nil {
+		return nil, invalid("invalid destination address: %v", err)
+	}
+
+	tokenStandard, err := ParseToken(req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := transaction.EncodeData(req.Memo, req.DataHex, req.DataBase64)
+	if err != nil {
+		return nil, invalid("%v", err)
+	}
+
+	info, err := s.Client.LedgerApi.GetAccountInfoByAddress(s.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account info: %w", err)
+	}
+
+	balanceInfo, found := info.BalanceInfoMap[tokenStandard]
+	if !found || balanceInfo.TokenInfo == nil {
+		return nil, invalid("you have no balance for token %s", tokenStandard)
+	}
+	decimals := int(balanceInfo.TokenInfo.Decimals)
+	balance := balanceInfo.Balance
+
+	// Resolve amount against the balance
+	amount, err := format.ResolveAmount(req.Amount, decimals, balance, req.Raw)
+	if err != nil {
+		return nil, invalid("invalid amount: %v", err)
+	}
+	if amount.Sign() == 0 {
+		return nil, invalid("invalid amount: resolves to zero")
+	}
+	if balance.Cmp(amount) < 0 {
+		return nil, insufficient("insufficient balance. You have %s but need %s",
+			format.Amount(balance, decimals),
+			format.Amount(amount, decimals))
+	}
+
+	template := &nom.AccountBlock{
+		Version:         1,
+		ChainIdentifier: 1,
+		BlockType:       nom.BlockTypeUserSend,
+		ToAddress:       toAddress,
+		Amount:          amount,
+		TokenStandard:   tokenStandard,
+		Data:            data,
+	}
+
+	return &Prepared{
+		Template:   template,
+		Expression: req.Amount,
+		Amount:     amount,
+		Decimals:   decimals,
+		Symbol:     balanceInfo.TokenInfo.TokenSymbol,
+	}, nil
+}
+
+// RequiredPlasma returns the plasma and PoW requirement of a prepared block
+func (s *Service) RequiredPlasma(p *Prepared) (*embedded.GetRequiredResult, error) {
+	return transaction.RequiredPlasma(s.ctx, s.Client, s.Address, p.Template)
+}
+
+// Send validates and publishes a token transfer
+func (s *Service) Send(req SendRequest) (*TxResult, error) {
+	prepared, err := s.PrepareSend(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.Submit(prepared)
+}
+
+// Receive publishes a receive block for the given send block
+func (s *Service) Receive(hash string) (*TxResult, error) {
+	var blockHash types.Hash
+	if err := blockHash.UnmarshalText([]byte(strings.TrimSpace(hash))); err != nil {
+		return nil, invalid("invalid block hash: %v", err)
+	}
+
+	template := &nom.AccountBlock{
+		Version:         1,
+		ChainIdentifier: 1,
+		BlockType:       nom.BlockTypeUserReceive,
+		FromBlockHash:   blockHash,
+		Data:            nil,
+	}
+	return s.Submit(&Prepared{Template: template})
+}
+
+// ReceiveAll receives every pending block of the service address
+func (s *Service) ReceiveAll() (*ReceiveResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	received, err := transaction.ReceiveAll(s.ctx, s.Client, s.Address, s.KeyPair, nil)
+	return &ReceiveResult{Received: received}, err
+}
@@ -0,0 +1,43 @@
+// Package stake lists the stake entries of an address and selects the ones
+// that can be revoked.
+package stake
+
+import (
+	"fmt"
+
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+)
+
+// pageSize is the number of stake entries requested per page
+const pageSize = 50
+
+// Entries returns every stake entry of an address
+func Entries(c *rpc_client.RpcClient, address types.Address) ([]*embedded.StakeEntry, error) {
+	var entries []*embedded.StakeEntry
+	for pageIndex := uint32(0); ; pageIndex++ {
+		list, err := c.StakeApi.GetEntriesByAddress(address, pageIndex, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stake entries: %w", err)
+		}
+		entries = append(entries, list.Entries...)
+		if len(list.Entries) < pageSize || len(entries) >= list.Count {
+			return entries, nil
+		}
+	}
+}
+
+// ExpiredEntries returns the entries that can be revoked at a momentum
+// timestamp. The stake contract compares expiration against the momentum
+// time, not the local clock.
+func ExpiredEntries(entries []*embedded.StakeEntry, timestamp int64) []*embedded.StakeEntry {
+	var expired []*embedded.StakeEntry
+	for _, entry := range entries {
+		if entry.ExpirationTimestamp <= timestamp {
+			expired = append(expired, entry)
+		}
+	}
+	return expired
+}
@@ -0,0 +1,30 @@
+package stake
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+)
+
+func znn(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), big.NewInt(1e8))
+}
+
+func TestExpiredEntries(t *testing.T) {
+	timestamp := int64(1_000_000)
+	entries := []*embedded.StakeEntry{
+		{Amount: znn(1), ExpirationTimestamp: timestamp - 1},
+		{Amount: znn(2), ExpirationTimestamp: timestamp},
+		{Amount: znn(3), ExpirationTimestamp: timestamp + 1},
+	}
+
+	expired := ExpiredEntries(entries, timestamp)
+	require.Len(t, expired, 2)
+	assert.Equal(t, znn(1), expired[0].Amount)
+	assert.Equal(t, znn(2), expired[1].Amount)
+
+	assert.Empty(t, ExpiredEntries(nil, timestamp))
+}
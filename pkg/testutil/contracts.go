@@ -0,0 +1,443 @@
+package testutil
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm/abi"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+// contractABIs maps the simulated embedded contracts to their ABI
+var contractABIs = map[types.Address]abi.ABIContract{
+	types.PlasmaContract:    definition.ABIPlasma,
+	types.StakeContract:     definition.ABIStake,
+	types.PillarContract:    definition.ABIPillars,
+	types.SentinelContract:  definition.ABISentinel,
+	types.TokenContract:     definition.ABIToken,
+	types.LiquidityContract: definition.ABILiquidity,
+}
+
+// callContract applies the effect of a send block to an embedded contract.
+// It changes nothing when it returns an error; the caller debits the amount
+// sent on success. Responses are delivered to the sender as unreceived blocks.
+func (n *Node) callContract(block *nom.AccountBlock, amount *big.Int) error {
+	contract, found := contractABIs[block.ToAddress]
+	if !found {
+		return fmt.Errorf("mock node does not simulate contract %s", block.ToAddress)
+	}
+	method, err := contract.MethodById(block.Data)
+	if err != nil {
+		return fmt.Errorf("invalid contract call: %w", err)
+	}
+
+	switch block.ToAddress {
+	case types.PlasmaContract:
+		return n.callPlasma(block, method.Name, amount)
+	case types.StakeContract:
+		return n.callStake(block, method.Name, amount)
+	case types.PillarContract:
+		return n.callPillar(block, method.Name, amount)
+	case types.SentinelContract:
+		return n.callSentinel(block, method.Name, amount)
+	case types.TokenContract:
+		return n.callToken(block, method.Name, amount)
+	default:
+		return n.callLiquidity(block, method.Name, amount)
+	}
+}
+
+func (n *Node) callPlasma(block *nom.AccountBlock, method string, amount *big.Int) error {
+	switch method {
+	case definition.FuseMethodName:
+		beneficiary := new(types.Address)
+		if err := definition.ABIPlasma.UnpackMethod(beneficiary, method, block.Data); err != nil {
+			return fmt.Errorf("invalid fuse call: %w", err)
+		}
+		if block.TokenStandard != types.QsrTokenStandard || amount.Cmp(constants.FuseMinAmount) < 0 ||
+			new(big.Int).Mod(amount, big.NewInt(constants.CostPerFusionUnit)).Sign() != 0 {
+			return constants.ErrInvalidTokenOrAmount
+		}
+		n.fusions = append(n.fusions, &fusion{owner: block.Address, entry: &embedded.FusionEntry{
+			QsrAmount:        new(big.Int).Set(amount),
+			Beneficiary:      *beneficiary,
+			ExpirationHeight: n.frontierMomentum().Height + constants.FuseExpiration,
+			Id:               block.Hash,
+		}})
+		return nil
+
+	case definition.CancelFuseMethodName:
+		id := new(types.Hash)
+		if err := definition.ABIPlasma.UnpackMethod(id, method, block.Data); err != nil {
+			return fmt.Errorf("invalid cancel fuse call: %w", err)
+		}
+		if err := requireEmpty(amount); err != nil {
+			return err
+		}
+		for i, f := range n.fusions {
+			if f.owner != block.Address || f.entry.Id != *id {
+				continue
+			}
+			if f.entry.ExpirationHeight > n.frontierMomentum().Height {
+				return fmt.Errorf("fusion entry %s is not expired", id)
+			}
+			n.fusions = append(n.fusions[:i:i], n.fusions[i+1:]...)
+			n.deliver(types.PlasmaContract, block.Address, types.QsrTokenStandard, f.entry.QsrAmount)
+			return nil
+		}
+		return fmt.Errorf("fusion entry %s does not exist", id)
+	}
+	return unsupported(types.PlasmaContract, method)
+}
+
+func (n *Node) callStake(block *nom.AccountBlock, method string, amount *big.Int) error {
+	switch method {
+	case definition.StakeMethodName:
+		var duration int64
+		if err := definition.ABIStake.UnpackMethod(&duration, method, block.Data); err != nil {
+			return fmt.Errorf("invalid stake call: %w", err)
+		}
+		if block.TokenStandard != types.ZnnTokenStandard || amount.Cmp(constants.StakeMinAmount) < 0 {
+			return constants.ErrInvalidTokenOrAmount
+		}
+		if duration < constants.StakeTimeMinSec || duration > constants.StakeTimeMaxSec || duration%constants.StakeTimeUnitSec != 0 {
+			return fmt.Errorf("invalid stake duration %d", duration)
+		}
+		months := duration / constants.StakeTimeUnitSec
+		weighted := new(big.Int).Mul(amount, big.NewInt(9+months))
+		n.stakes = append(n.stakes, &embedded.StakeEntry{
+			Amount:              new(big.Int).Set(amount),
+			WeightedAmount:      weighted.Div(weighted, big.NewInt(10)),
+			StartTimestamp:      n.now.Unix(),
+			ExpirationTimestamp: n.now.Unix() + duration,
+			Address:             block.Address,
+			Id:                  block.Hash,
+		})
+		return nil
+
+	case definition.CancelStakeMethodName:
+		id := new(types.Hash)
+		if err := definition.ABIStake.UnpackMethod(id, method, block.Data); err != nil {
+			return fmt.Errorf("invalid cancel stake call: %w", err)
+		}
+		if err := requireEmpty(amount); err != nil {
+			return err
+		}
+		for i, entry := range n.stakes {
+			if entry.Address != block.Address || entry.Id != *id {
+				continue
+			}
+			if entry.ExpirationTimestamp > n.now.Unix() {
+				return fmt.Errorf("stake entry %s is not expired", id)
+			}
+			n.stakes = append(n.stakes[:i:i], n.stakes[i+1:]...)
+			n.deliver(types.StakeContract, block.Address, types.ZnnTokenStandard, entry.Amount)
+			return nil
+		}
+		return fmt.Errorf("stake entry %s does not exist", id)
+
+	case definition.CollectRewardMethodName:
+		return n.collectReward(types.StakeContract, block.Address, amount)
+	}
+	return unsupported(types.StakeContract, method)
+}
+
+func (n *Node) callPillar(block *nom.AccountBlock, method string, amount *big.Int) error {
+	switch method {
+	case definition.RegisterMethodName:
+		param := new(definition.RegisterParam)
+		if err := definition.ABIPillars.UnpackMethod(param, method, block.Data); err != nil {
+			return fmt.Errorf("invalid register call: %w", err)
+		}
+		if block.TokenStandard != types.ZnnTokenStandard || amount.Cmp(constants.PillarStakeAmount) != 0 {
+			return constants.ErrInvalidTokenOrAmount
+		}
+		if n.pillarByName(param.Name) != nil {
+			return fmt.Errorf("pillar name %s is taken", param.Name)
+		}
+		deposit := n.deposit(types.PillarContract, block.Address)
+		cost := n.pillarRegistrationCost()
+		if deposit.Cmp(cost) < 0 {
+			return fmt.Errorf("not enough QSR deposited: need %s", cost)
+		}
+		deposit.Sub(deposit, cost)
+		n.pillars = append(n.pillars, &embedded.PillarInfo{
+			Name:                         param.Name,
+			Rank:                         len(n.pillars),
+			StakeAddress:                 block.Address,
+			BlockProducingAddress:        param.ProducerAddress,
+			RewardWithdrawAddress:        param.RewardAddress,
+			GiveMomentumRewardPercentage: param.GiveBlockRewardPercentage,
+			GiveDelegateRewardPercentage: param.GiveDelegateRewardPercentage,
+			CurrentStats:                 &embedded.PillarStats{},
+			Weight:                       new(big.Int).Set(amount),
+		})
+		return nil
+
+	case definition.UpdatePillarMethodName:
+		param := new(definition.RegisterParam)
+		if err := definition.ABIPillars.UnpackMethod(param, method, block.Data); err != nil {
+			return fmt.Errorf("invalid update call: %w", err)
+		}
+		pillar := n.pillarByName(param.Name)
+		if pillar == nil || pillar.StakeAddress != block.Address {
+			return fmt.Errorf("no pillar %s owned by %s", param.Name, block.Address)
+		}
+		pillar.BlockProducingAddress = param.ProducerAddress
+		pillar.RewardWithdrawAddress = param.RewardAddress
+		pillar.GiveMomentumRewardPercentage = param.GiveBlockRewardPercentage
+		pillar.GiveDelegateRewardPercentage = param.GiveDelegateRewardPercentage
+		return requireEmpty(amount)
+
+	case definition.RevokeMethodName:
+		var name string
+		if err := definition.ABIPillars.UnpackMethod(&name, method, block.Data); err != nil {
+			return fmt.Errorf("invalid revoke call: %w", err)
+		}
+		if err := requireEmpty(amount); err != nil {
+			return err
+		}
+		for i, pillar := range n.pillars {
+			if pillar.Name == name && pillar.StakeAddress == block.Address {
+				n.pillars = append(n.pillars[:i:i], n.pillars[i+1:]...)
+				n.deliver(types.PillarContract, block.Address, types.ZnnTokenStandard, constants.PillarStakeAmount)
+				return nil
+			}
+		}
+		return fmt.Errorf("no pillar %s owned by %s", name, block.Address)
+
+	case definition.DelegateMethodName:
+		var name string
+		if err := definition.ABIPillars.UnpackMethod(&name, method, block.Data); err != nil {
+			return fmt.Errorf("invalid delegate call: %w", err)
+		}
+		if n.pillarByName(name) == nil {
+			return fmt.Errorf("pillar %s does not exist", name)
+		}
+		n.delegations[block.Address] = name
+		return requireEmpty(amount)
+
+	case definition.UndelegateMethodName:
+		delete(n.delegations, block.Address)
+		return requireEmpty(amount)
+	}
+	return n.callDeposits(types.PillarContract, block, method, amount)
+}
+
+func (n *Node) callSentinel(block *nom.AccountBlock, method string, amount *big.Int) error {
+	switch method {
+	case definition.RegisterSentinelMethodName:
+		if block.TokenStandard != types.ZnnTokenStandard || amount.Cmp(constants.SentinelZnnRegisterAmount) != 0 {
+			return constants.ErrInvalidTokenOrAmount
+		}
+		if sentinel := n.sentinels[block.Address]; sentinel != nil && sentinel.Active {
+			return fmt.Errorf("%s already has an active sentinel", block.Address)
+		}
+		deposit := n.deposit(types.SentinelContract, block.Address)
+		if deposit.Cmp(constants.SentinelQsrDepositAmount) < 0 {
+			return fmt.Errorf("not enough QSR deposited: need %s", constants.SentinelQsrDepositAmount)
+		}
+		deposit.Sub(deposit, constants.SentinelQsrDepositAmount)
+		n.sentinels[block.Address] = &embedded.SentinelInfo{
+			Owner:                 block.Address,
+			RegistrationTimestamp: n.now.Unix(),
+			RevokeCooldown:        constants.SentinelLockTimeWindow,
+			Active:                true,
+		}
+		return nil
+
+	case definition.RevokeSentinelMethodName:
+		sentinel := n.sentinels[block.Address]
+		if sentinel == nil || !sentinel.Active {
+			return fmt.Errorf("%s has no active sentinel", block.Address)
+		}
+		if err := requireEmpty(amount); err != nil {
+			return err
+		}
+		sentinel.Active = false
+		n.deliver(types.SentinelContract, block.Address, types.ZnnTokenStandard, constants.SentinelZnnRegisterAmount)
+		n.deliver(types.SentinelContract, block.Address, types.QsrTokenStandard, constants.SentinelQsrDepositAmount)
+		return nil
+	}
+	return n.callDeposits(types.SentinelContract, block, method, amount)
+}
+
+// callDeposits handles the QSR deposit and reward methods shared by the
+// pillar and sentinel contracts
+func (n *Node) callDeposits(contract types.Address, block *nom.AccountBlock, method string, amount *big.Int) error {
+	switch method {
+	case definition.DepositQsrMethodName:
+		if block.TokenStandard != types.QsrTokenStandard || amount.Sign() <= 0 {
+			return constants.ErrInvalidTokenOrAmount
+		}
+		deposit := n.deposit(contract, block.Address)
+		deposit.Add(deposit, amount)
+		return nil
+
+	case definition.WithdrawQsrMethodName:
+		if err := requireEmpty(amount); err != nil {
+			return err
+		}
+		deposit := n.deposit(contract, block.Address)
+		if deposit.Sign() == 0 {
+			return fmt.Errorf("no QSR deposited by %s", block.Address)
+		}
+		n.deliver(contract, block.Address, types.QsrTokenStandard, deposit)
+		deposit.SetInt64(0)
+		return nil
+
+	case definition.CollectRewardMethodName:
+		return n.collectReward(contract, block.Address, amount)
+	}
+	return unsupported(contract, method)
+}
+
+func (n *Node) callToken(block *nom.AccountBlock, method string, amount *big.Int) error {
+	switch method {
+	case definition.IssueMethodName:
+		param := new(definition.IssueParam)
+		if err := definition.ABIToken.UnpackMethod(param, method, block.Data); err != nil {
+			return fmt.Errorf("invalid issue call: %w", err)
+		}
+		if block.TokenStandard != types.ZnnTokenStandard || amount.Cmp(constants.TokenIssueAmount) != 0 {
+			return constants.ErrInvalidTokenOrAmount
+		}
+		if param.TotalSupply.Cmp(param.MaxSupply) > 0 {
+			return fmt.Errorf("total supply exceeds max supply")
+		}
+		zts := types.NewZenonTokenStandard(block.Hash.Bytes())
+		n.tokens[zts] = &api.Token{
+			TokenName:          param.TokenName,
+			TokenSymbol:        param.TokenSymbol,
+			TokenDomain:        param.TokenDomain,
+			TotalSupply:        new(big.Int).Set(param.TotalSupply),
+			MaxSupply:          new(big.Int).Set(param.MaxSupply),
+			Decimals:           param.Decimals,
+			Owner:              block.Address,
+			ZenonTokenStandard: zts,
+			IsMintable:         param.IsMintable,
+			IsBurnable:         param.IsBurnable,
+			IsUtility:          param.IsUtility,
+		}
+		if param.TotalSupply.Sign() > 0 {
+			n.deliver(types.TokenContract, block.Address, zts, param.TotalSupply)
+		}
+		return nil
+
+	case definition.MintMethodName:
+		param := new(definition.MintParam)
+		if err := definition.ABIToken.UnpackMethod(param, method, block.Data); err != nil {
+			return fmt.Errorf("invalid mint call: %w", err)
+		}
+		token := n.tokens[param.TokenStandard]
+		if token == nil || token.Owner != block.Address || !token.IsMintable {
+			return fmt.Errorf("%s cannot mint %s", block.Address, param.TokenStandard)
+		}
+		supply := new(big.Int).Add(token.TotalSupply, param.Amount)
+		if supply.Cmp(token.MaxSupply) > 0 {
+			return fmt.Errorf("mint exceeds max supply of %s", param.TokenStandard)
+		}
+		if err := requireEmpty(amount); err != nil {
+			return err
+		}
+		token.TotalSupply = supply
+		n.deliver(types.TokenContract, param.ReceiveAddress, param.TokenStandard, param.Amount)
+		return nil
+
+	case definition.BurnMethodName:
+		token := n.tokens[block.TokenStandard]
+		if token == nil || amount.Sign() <= 0 || (!token.IsBurnable && token.Owner != block.Address) {
+			return fmt.Errorf("%s cannot burn %s", block.Address, block.TokenStandard)
+		}
+		token.TotalSupply = new(big.Int).Sub(token.TotalSupply, amount)
+		token.MaxSupply = new(big.Int).Sub(token.MaxSupply, amount)
+		return nil
+
+	case definition.UpdateTokenMethodName:
+		param := new(definition.UpdateTokenParam)
+		if err := definition.ABIToken.UnpackMethod(param, method, block.Data); err != nil {
+			return fmt.Errorf("invalid update token call: %w", err)
+		}
+		token := n.tokens[param.TokenStandard]
+		if token == nil || token.Owner != block.Address {
+			return fmt.Errorf("%s does not own %s", block.Address, param.TokenStandard)
+		}
+		if err := requireEmpty(amount); err != nil {
+			return err
+		}
+		token.Owner = param.Owner
+		token.IsMintable = token.IsMintable && param.IsMintable
+		token.IsBurnable = param.IsBurnable
+		return nil
+	}
+	return unsupported(types.TokenContract, method)
+}
+
+func (n *Node) callLiquidity(block *nom.AccountBlock, method string, amount *big.Int) error {
+	if method == definition.CollectRewardMethodName {
+		return n.collectReward(types.LiquidityContract, block.Address, amount)
+	}
+	return unsupported(types.LiquidityContract, method)
+}
+
+// collectReward delivers the uncollected reward of an address and records it
+// in the reward history
+func (n *Node) collectReward(contract, address types.Address, amount *big.Int) error {
+	if err := requireEmpty(amount); err != nil {
+		return err
+	}
+	reward := n.rewards[contract][address]
+	if reward == nil || (reward.Znn.Sign() == 0 && reward.Qsr.Sign() == 0) {
+		return fmt.Errorf("%s has no reward to collect", address)
+	}
+	if reward.Znn.Sign() > 0 {
+		n.deliver(contract, address, types.ZnnTokenStandard, reward.Znn)
+	}
+	if reward.Qsr.Sign() > 0 {
+		n.deliver(contract, address, types.QsrTokenStandard, reward.Qsr)
+	}
+
+	if n.history[contract] == nil {
+		n.history[contract] = make(map[types.Address][]*embedded.RewardHistoryEntry)
+	}
+	entries := n.history[contract][address]
+	n.history[contract][address] = append(entries, &embedded.RewardHistoryEntry{
+		Epoch: int64(len(entries)),
+		Znn:   reward.Znn,
+		Qsr:   reward.Qsr,
+	})
+	n.rewards[contract][address] = &definition.RewardDeposit{Address: reward.Address, Znn: new(big.Int), Qsr: new(big.Int)}
+	return nil
+}
+
+func (n *Node) pillarByName(name string) *embedded.PillarInfo {
+	for _, pillar := range n.pillars {
+		if pillar.Name == name {
+			return pillar
+		}
+	}
+	return nil
+}
+
+// pillarRegistrationCost returns the QSR needed to register the next pillar
+func (n *Node) pillarRegistrationCost() *big.Int {
+	cost := new(big.Int).Mul(constants.PillarQsrStakeIncreaseAmount, big.NewInt(int64(len(n.pillars))))
+	return cost.Add(cost, constants.PillarQsrStakeBaseAmount)
+}
+
+// requireEmpty rejects calls that must not send tokens
+func requireEmpty(amount *big.Int) error {
+	if amount.Sign() != 0 {
+		return constants.ErrInvalidTokenOrAmount
+	}
+	return nil
+}
+
+func unsupported(contract types.Address, method string) error {
+	return fmt.Errorf("mock node does not simulate %s on %s", method, contract)
+}
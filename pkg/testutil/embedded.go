@@ -0,0 +1,391 @@
+package testutil
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+// plasmaAPI serves the embedded.plasma namespace
+type plasmaAPI struct {
+	node *Node
+}
+
+// Get returns the plasma of an address
+func (p *plasmaAPI) Get(address types.Address) (*embedded.PlasmaInfo, error) {
+	if err := p.node.scripted("embedded.plasma.get"); err != nil {
+		return nil, err
+	}
+	n := p.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	plasma := n.availablePlasma(address)
+	return &embedded.PlasmaInfo{CurrentPlasma: plasma, MaxPlasma: plasma, QsrAmount: n.fusedQsr(address)}, nil
+}
+
+// GetEntriesByAddress returns a page of the fusion entries created by an address
+func (p *plasmaAPI) GetEntriesByAddress(address types.Address, pageIndex, pageSize uint32) (*embedded.FusionEntryList, error) {
+	if err := p.node.scripted("embedded.plasma.getEntriesByAddress"); err != nil {
+		return nil, err
+	}
+	n := p.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	entries := n.fusionsOf(address)
+	total := new(big.Int)
+	for _, entry := range entries {
+		total.Add(total, entry.QsrAmount)
+	}
+	return &embedded.FusionEntryList{QsrAmount: total, Count: len(entries), Fusions: page(entries, pageIndex, pageSize)}, nil
+}
+
+// GetRequiredPoWForAccountBlock returns the plasma of a block and the PoW
+// difficulty needed when the address does not have enough plasma
+func (p *plasmaAPI) GetRequiredPoWForAccountBlock(param embedded.GetRequiredParam) (*embedded.GetRequiredResult, error) {
+	if err := p.node.scripted("embedded.plasma.getRequiredPoWForAccountBlock"); err != nil {
+		return nil, err
+	}
+	n := p.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var toAddress types.Address
+	if param.ToAddr != nil {
+		toAddress = *param.ToAddr
+	}
+	result := &embedded.GetRequiredResult{
+		AvailablePlasma: n.availablePlasma(param.SelfAddr),
+		BasePlasma:      requiredPlasma(param.BlockType, toAddress, len(param.Data)),
+	}
+	if result.AvailablePlasma < result.BasePlasma {
+		result.RequiredDifficulty = result.BasePlasma * constants.PoWDifficultyPerPlasma
+	}
+	return result, nil
+}
+
+// stakeAPI serves the embedded.stake namespace
+type stakeAPI struct {
+	node *Node
+}
+
+// GetEntriesByAddress returns a page of the stake entries of an address
+func (s *stakeAPI) GetEntriesByAddress(address types.Address, pageIndex, pageSize uint32) (*embedded.StakeList, error) {
+	if err := s.node.scripted("embedded.stake.getEntriesByAddress"); err != nil {
+		return nil, err
+	}
+	n := s.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	entries := n.stakesOf(address)
+	list := &embedded.StakeList{
+		TotalAmount:         new(big.Int),
+		TotalWeightedAmount: new(big.Int),
+		Count:               len(entries),
+		Entries:             page(entries, pageIndex, pageSize),
+	}
+	for _, entry := range entries {
+		list.TotalAmount.Add(list.TotalAmount, entry.Amount)
+		list.TotalWeightedAmount.Add(list.TotalWeightedAmount, entry.WeightedAmount)
+	}
+	return list, nil
+}
+
+// GetUncollectedReward returns the stake reward an address can collect
+func (s *stakeAPI) GetUncollectedReward(address types.Address) (*definition.RewardDeposit, error) {
+	return s.node.uncollectedReward("embedded.stake.getUncollectedReward", types.StakeContract, address)
+}
+
+// GetFrontierRewardByPage returns a page of the stake rewards collected by an address
+func (s *stakeAPI) GetFrontierRewardByPage(address types.Address, pageIndex, pageSize uint32) (*embedded.RewardHistoryList, error) {
+	return s.node.rewardHistory("embedded.stake.getFrontierRewardByPage", types.StakeContract, address, pageIndex, pageSize)
+}
+
+// pillarAPI serves the embedded.pillar namespace
+type pillarAPI struct {
+	node *Node
+}
+
+// GetAll returns a page of the registered pillars
+func (p *pillarAPI) GetAll(pageIndex, pageSize uint32) (*embedded.PillarInfoList, error) {
+	if err := p.node.scripted("embedded.pillar.getAll"); err != nil {
+		return nil, err
+	}
+	n := p.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return &embedded.PillarInfoList{Count: uint32(len(n.pillars)), List: page(n.pillars, pageIndex, pageSize)}, nil
+}
+
+// GetByOwner returns the pillars owned by an address
+func (p *pillarAPI) GetByOwner(address types.Address) ([]*embedded.PillarInfo, error) {
+	if err := p.node.scripted("embedded.pillar.getByOwner"); err != nil {
+		return nil, err
+	}
+	n := p.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	pillars := make([]*embedded.PillarInfo, 0)
+	for _, pillar := range n.pillars {
+		if pillar.StakeAddress == address {
+			pillars = append(pillars, pillar)
+		}
+	}
+	return pillars, nil
+}
+
+// GetByName returns a pillar, or nil if it does not exist
+func (p *pillarAPI) GetByName(name string) (*embedded.PillarInfo, error) {
+	if err := p.node.scripted("embedded.pillar.getByName"); err != nil {
+		return nil, err
+	}
+	n := p.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.pillarByName(name), nil
+}
+
+// CheckNameAvailability reports whether no pillar uses a name
+func (p *pillarAPI) CheckNameAvailability(name string) (bool, error) {
+	if err := p.node.scripted("embedded.pillar.checkNameAvailability"); err != nil {
+		return false, err
+	}
+	n := p.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.pillarByName(name) == nil, nil
+}
+
+// GetDelegatedPillar returns the pillar an address delegates to, or nil
+func (p *pillarAPI) GetDelegatedPillar(address types.Address) (*embedded.GetDelegatedPillarResponse, error) {
+	if err := p.node.scripted("embedded.pillar.getDelegatedPillar"); err != nil {
+		return nil, err
+	}
+	n := p.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	name, found := n.delegations[address]
+	if !found {
+		return nil, nil
+	}
+	status := embedded.PillarInActive
+	if pillar := n.pillarByName(name); pillar != nil && pillar.RevokeTime == 0 {
+		status = embedded.PillarActive
+	}
+	return &embedded.GetDelegatedPillarResponse{
+		Name:       name,
+		NodeStatus: status,
+		Balance:    new(big.Int).Set(n.balance(address, types.ZnnTokenStandard)),
+	}, nil
+}
+
+// GetDepositedQsr returns the QSR an address has deposited for a pillar
+func (p *pillarAPI) GetDepositedQsr(address types.Address) (string, error) {
+	return p.node.depositedQsr("embedded.pillar.getDepositedQsr", types.PillarContract, address)
+}
+
+// GetQsrRegistrationCost returns the QSR needed to register the next pillar
+func (p *pillarAPI) GetQsrRegistrationCost() (string, error) {
+	if err := p.node.scripted("embedded.pillar.getQsrRegistrationCost"); err != nil {
+		return "", err
+	}
+	n := p.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.pillarRegistrationCost().String(), nil
+}
+
+// GetUncollectedReward returns the pillar reward an address can collect
+func (p *pillarAPI) GetUncollectedReward(address types.Address) (*definition.RewardDeposit, error) {
+	return p.node.uncollectedReward("embedded.pillar.getUncollectedReward", types.PillarContract, address)
+}
+
+// GetFrontierRewardByPage returns a page of the pillar rewards collected by an address
+func (p *pillarAPI) GetFrontierRewardByPage(address types.Address, pageIndex, pageSize uint32) (*embedded.RewardHistoryList, error) {
+	return p.node.rewardHistory("embedded.pillar.getFrontierRewardByPage", types.PillarContract, address, pageIndex, pageSize)
+}
+
+// GetPillarEpochHistory returns an empty epoch history
+func (p *pillarAPI) GetPillarEpochHistory(name string, pageIndex, pageSize uint32) (*embedded.PillarEpochHistoryList, error) {
+	if err := p.node.scripted("embedded.pillar.getPillarEpochHistory"); err != nil {
+		return nil, err
+	}
+	return &embedded.PillarEpochHistoryList{List: []*definition.PillarEpochHistory{}}, nil
+}
+
+// sentinelAPI serves the embedded.sentinel namespace
+type sentinelAPI struct {
+	node *Node
+}
+
+// GetByOwner returns the sentinel of an address, or nil
+func (s *sentinelAPI) GetByOwner(address types.Address) (*embedded.SentinelInfo, error) {
+	if err := s.node.scripted("embedded.sentinel.getByOwner"); err != nil {
+		return nil, err
+	}
+	n := s.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.sentinels[address], nil
+}
+
+// GetAllActive returns a page of the active sentinels
+func (s *sentinelAPI) GetAllActive(pageIndex, pageSize uint32) (*embedded.SentinelInfoList, error) {
+	if err := s.node.scripted("embedded.sentinel.getAllActive"); err != nil {
+		return nil, err
+	}
+	n := s.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var active []*embedded.SentinelInfo
+	for _, sentinel := range n.sentinels {
+		if sentinel.Active {
+			active = append(active, sentinel)
+		}
+	}
+	return &embedded.SentinelInfoList{Count: len(active), List: page(active, pageIndex, pageSize)}, nil
+}
+
+// GetDepositedQsr returns the QSR an address has deposited for a sentinel
+func (s *sentinelAPI) GetDepositedQsr(address types.Address) (string, error) {
+	return s.node.depositedQsr("embedded.sentinel.getDepositedQsr", types.SentinelContract, address)
+}
+
+// GetUncollectedReward returns the sentinel reward an address can collect
+func (s *sentinelAPI) GetUncollectedReward(address types.Address) (*definition.RewardDeposit, error) {
+	return s.node.uncollectedReward("embedded.sentinel.getUncollectedReward", types.SentinelContract, address)
+}
+
+// GetFrontierRewardByPage returns a page of the sentinel rewards collected by an address
+func (s *sentinelAPI) GetFrontierRewardByPage(address types.Address, pageIndex, pageSize uint32) (*embedded.RewardHistoryList, error) {
+	return s.node.rewardHistory("embedded.sentinel.getFrontierRewardByPage", types.SentinelContract, address, pageIndex, pageSize)
+}
+
+// tokenAPI serves the embedded.token namespace
+type tokenAPI struct {
+	node *Node
+}
+
+// GetAll returns a page of every token
+func (t *tokenAPI) GetAll(pageIndex, pageSize uint32) (*embedded.TokenList, error) {
+	if err := t.node.scripted("embedded.token.getAll"); err != nil {
+		return nil, err
+	}
+	n := t.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	tokens := n.sortedTokens(nil)
+	return &embedded.TokenList{Count: len(tokens), List: page(tokens, pageIndex, pageSize)}, nil
+}
+
+// GetByOwner returns a page of the tokens owned by an address
+func (t *tokenAPI) GetByOwner(address types.Address, pageIndex, pageSize uint32) (*embedded.TokenList, error) {
+	if err := t.node.scripted("embedded.token.getByOwner"); err != nil {
+		return nil, err
+	}
+	n := t.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	tokens := n.sortedTokens(&address)
+	return &embedded.TokenList{Count: len(tokens), List: page(tokens, pageIndex, pageSize)}, nil
+}
+
+// GetByZts returns a token, or nil if it does not exist
+func (t *tokenAPI) GetByZts(zts types.ZenonTokenStandard) (*api.Token, error) {
+	if err := t.node.scripted("embedded.token.getByZts"); err != nil {
+		return nil, err
+	}
+	n := t.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.tokens[zts], nil
+}
+
+// liquidityAPI serves the embedded.liquidity namespace
+type liquidityAPI struct {
+	node *Node
+}
+
+// GetUncollectedReward returns the liquidity reward an address can collect
+func (l *liquidityAPI) GetUncollectedReward(address types.Address) (*definition.RewardDeposit, error) {
+	return l.node.uncollectedReward("embedded.liquidity.getUncollectedReward", types.LiquidityContract, address)
+}
+
+// GetFrontierRewardByPage returns a page of the liquidity rewards collected by an address
+func (l *liquidityAPI) GetFrontierRewardByPage(address types.Address, pageIndex, pageSize uint32) (*embedded.RewardHistoryList, error) {
+	return l.node.rewardHistory("embedded.liquidity.getFrontierRewardByPage", types.LiquidityContract, address, pageIndex, pageSize)
+}
+
+// uncollectedReward returns the reward of an address in a contract, zero if none
+func (n *Node) uncollectedReward(method string, contract, address types.Address) (*definition.RewardDeposit, error) {
+	if err := n.scripted(method); err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if reward := n.rewards[contract][address]; reward != nil {
+		return reward, nil
+	}
+	owner := address
+	return &definition.RewardDeposit{Address: &owner, Znn: new(big.Int), Qsr: new(big.Int)}, nil
+}
+
+// rewardHistory returns a page of the rewards collected by an address, newest first
+func (n *Node) rewardHistory(method string, contract, address types.Address, pageIndex, pageSize uint32) (*embedded.RewardHistoryList, error) {
+	if err := n.scripted(method); err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	entries := n.history[contract][address]
+	newest := make([]*embedded.RewardHistoryEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		newest = append(newest, entries[i])
+	}
+	return &embedded.RewardHistoryList{Count: int64(len(entries)), List: page(newest, pageIndex, pageSize)}, nil
+}
+
+// depositedQsr returns the QSR deposit of an address as a decimal string
+func (n *Node) depositedQsr(method string, contract, address types.Address) (string, error) {
+	if err := n.scripted(method); err != nil {
+		return "", err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.deposit(contract, address).String(), nil
+}
+
+// sortedTokens returns the tokens ordered by standard, optionally only those of an owner
+func (n *Node) sortedTokens(owner *types.Address) []*api.Token {
+	tokens := make([]*api.Token, 0, len(n.tokens))
+	for _, token := range n.tokens {
+		if owner == nil || token.Owner == *owner {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].ZenonTokenStandard.String() < tokens[j].ZenonTokenStandard.String()
+	})
+	return tokens
+}
@@ -10,7 +10,7 @@ import (
 // Test addresses for different scenarios
 var (
 	// ValidAddress is a valid Zenon address for testing
-	ValidAddress = types.ParseAddressPanic("z1qzal6c5s9rjnnxd2z672tx3apscy5s5qqhslq5")
+	ValidAddress = types.ParseAddressPanic("z1qzal6c5s9rjnnxd2z672tx3apscy5s5qjskqxw")
 
 	// ValidAddress2 is another valid Zenon address for testing
 	ValidAddress2 = types.ParseAddressPanic("z1qqjnwjjpnue8xmmpanz6csze6tcmtzzdtfsww7")
@@ -0,0 +1,308 @@
+package testutil
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"math/big"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/pow"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/constants"
+)
+
+// ledgerAPI serves the ledger namespace
+type ledgerAPI struct {
+	node *Node
+}
+
+// PublishRawTransaction validates a signed block and applies it to the ledger
+func (l *ledgerAPI) PublishRawTransaction(block *nom.AccountBlock) error {
+	if err := l.node.scripted("ledger.publishRawTransaction"); err != nil {
+		return err
+	}
+	return l.node.publish(block)
+}
+
+// GetFrontierMomentum returns the latest momentum
+func (l *ledgerAPI) GetFrontierMomentum() (*api.Momentum, error) {
+	if err := l.node.scripted("ledger.getFrontierMomentum"); err != nil {
+		return nil, err
+	}
+	n := l.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	frontier := *n.frontierMomentum()
+	return &api.Momentum{Momentum: &frontier, Producer: ProducerAddress}, nil
+}
+
+// GetAccountInfoByAddress returns the height and balances of an address
+func (l *ledgerAPI) GetAccountInfoByAddress(address types.Address) (*api.AccountInfo, error) {
+	if err := l.node.scripted("ledger.getAccountInfoByAddress"); err != nil {
+		return nil, err
+	}
+	n := l.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	a := n.account(address)
+	info := &api.AccountInfo{
+		Address:        address,
+		AccountHeight:  a.height,
+		BalanceInfoMap: make(map[types.ZenonTokenStandard]*api.BalanceInfo),
+	}
+	for zts, balance := range a.balances {
+		info.BalanceInfoMap[zts] = &api.BalanceInfo{
+			TokenInfo: n.tokens[zts],
+			Balance:   new(big.Int).Set(balance),
+		}
+	}
+	return info, nil
+}
+
+// GetFrontierAccountBlock returns the latest block of an address, or nil
+func (l *ledgerAPI) GetFrontierAccountBlock(address types.Address) (*api.AccountBlock, error) {
+	if err := l.node.scripted("ledger.getFrontierAccountBlock"); err != nil {
+		return nil, err
+	}
+	n := l.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	a := n.account(address)
+	if a.height == 0 {
+		return nil, nil
+	}
+	return n.blocks[a.frontier], nil
+}
+
+// GetAccountBlockByHash returns a block, or nil if it does not exist
+func (l *ledgerAPI) GetAccountBlockByHash(hash types.Hash) (*api.AccountBlock, error) {
+	if err := l.node.scripted("ledger.getAccountBlockByHash"); err != nil {
+		return nil, err
+	}
+	n := l.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.blocks[hash], nil
+}
+
+// GetAccountBlocksByPage returns a page of the blocks of an address, newest first
+func (l *ledgerAPI) GetAccountBlocksByPage(address types.Address, pageIndex, pageSize uint32) (*api.AccountBlockList, error) {
+	if err := l.node.scripted("ledger.getAccountBlocksByPage"); err != nil {
+		return nil, err
+	}
+	n := l.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	hashes := n.account(address).blocks
+	blocks := make([]*api.AccountBlock, 0, len(hashes))
+	for i := len(hashes) - 1; i >= 0; i-- {
+		blocks = append(blocks, n.blocks[hashes[i]])
+	}
+	return blockList(blocks, pageIndex, pageSize), nil
+}
+
+// GetAccountBlocksByHeight returns count blocks of an address starting at height
+func (l *ledgerAPI) GetAccountBlocksByHeight(address types.Address, height, count uint64) (*api.AccountBlockList, error) {
+	if err := l.node.scripted("ledger.getAccountBlocksByHeight"); err != nil {
+		return nil, err
+	}
+	n := l.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	hashes := n.account(address).blocks
+	list := &api.AccountBlockList{List: []*api.AccountBlock{}, Count: len(hashes)}
+	for h := height; h >= 1 && h < height+count && h <= uint64(len(hashes)); h++ {
+		list.List = append(list.List, n.blocks[hashes[h-1]])
+	}
+	return list, nil
+}
+
+// GetUnreceivedBlocksByAddress returns a page of the blocks sent to an
+// address that it has not received yet, oldest first
+func (l *ledgerAPI) GetUnreceivedBlocksByAddress(address types.Address, pageIndex, pageSize uint32) (*api.AccountBlockList, error) {
+	if err := l.node.scripted("ledger.getUnreceivedBlocksByAddress"); err != nil {
+		return nil, err
+	}
+	n := l.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var blocks []*api.AccountBlock
+	for _, hash := range n.unreceived[address] {
+		blocks = append(blocks, n.blocks[hash])
+	}
+	return blockList(blocks, pageIndex, pageSize), nil
+}
+
+// GetUnconfirmedBlocksByAddress returns a page of the blocks of an address
+// published since the last momentum
+func (l *ledgerAPI) GetUnconfirmedBlocksByAddress(address types.Address, pageIndex, pageSize uint32) (*api.AccountBlockList, error) {
+	if err := l.node.scripted("ledger.getUnconfirmedBlocksByAddress"); err != nil {
+		return nil, err
+	}
+	n := l.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var blocks []*api.AccountBlock
+	for _, block := range n.unconfirmed {
+		if block.Address == address {
+			blocks = append(blocks, block)
+		}
+	}
+	return blockList(blocks, pageIndex, pageSize), nil
+}
+
+// blockList returns one page of blocks with the total count
+func blockList(blocks []*api.AccountBlock, pageIndex, pageSize uint32) *api.AccountBlockList {
+	list := page(blocks, pageIndex, pageSize)
+	return &api.AccountBlockList{
+		List:  list,
+		Count: len(blocks),
+		More:  uint64(pageIndex+1)*uint64(pageSize) < uint64(len(blocks)),
+	}
+}
+
+// publish checks a block against the account chain and applies it
+func (n *Node) publish(block *nom.AccountBlock) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if block.Hash != block.ComputeHash() {
+		return fmt.Errorf("invalid block hash %s", block.Hash)
+	}
+	if len(block.PublicKey) != ed25519.PublicKeySize || types.PubKeyToAddress(block.PublicKey) != block.Address {
+		return fmt.Errorf("public key does not match address %s", block.Address)
+	}
+	if !ed25519.Verify(block.PublicKey, block.Hash.Bytes(), block.Signature) {
+		return fmt.Errorf("invalid signature for block %s", block.Hash)
+	}
+
+	a := n.account(block.Address)
+	if block.Height != a.height+1 {
+		return fmt.Errorf("invalid height %d for %s: expected %d", block.Height, block.Address, a.height+1)
+	}
+	if block.PreviousHash != a.frontier {
+		return fmt.Errorf("invalid previous hash %s for %s", block.PreviousHash, block.Address)
+	}
+	if !n.knownMomentum(block.MomentumAcknowledged) {
+		return fmt.Errorf("unknown momentum %s acknowledged", block.MomentumAcknowledged.Hash)
+	}
+	if _, found := n.blocks[block.Hash]; found {
+		return fmt.Errorf("block %s already exists", block.Hash)
+	}
+
+	base := requiredPlasma(block.BlockType, block.ToAddress, len(block.Data))
+	if block.Difficulty == 0 && n.availablePlasma(block.Address) < base {
+		return fmt.Errorf("insufficient plasma for %s: need %d", block.Address, base)
+	}
+	if block.Difficulty > 0 && !pow.CheckPoWNonce(block) {
+		return fmt.Errorf("invalid PoW nonce for block %s", block.Hash)
+	}
+
+	published := &api.AccountBlock{AccountBlock: *block}
+	switch block.BlockType {
+	case nom.BlockTypeUserSend:
+		amount := block.Amount
+		if amount == nil {
+			amount = new(big.Int)
+		}
+		if amount.Sign() < 0 {
+			return fmt.Errorf("negative amount")
+		}
+		if n.balance(block.Address, block.TokenStandard).Cmp(amount) < 0 {
+			return fmt.Errorf("insufficient balance of %s", block.TokenStandard)
+		}
+		published.TokenInfo = n.tokens[block.TokenStandard]
+		if types.IsEmbeddedAddress(block.ToAddress) {
+			if err := n.callContract(block, amount); err != nil {
+				return err
+			}
+		} else {
+			n.unreceived[block.ToAddress] = append(n.unreceived[block.ToAddress], block.Hash)
+		}
+		balance := n.balance(block.Address, block.TokenStandard)
+		balance.Sub(balance, amount)
+
+	case nom.BlockTypeUserReceive:
+		send, err := n.takeUnreceived(block.Address, block.FromBlockHash)
+		if err != nil {
+			return err
+		}
+		balance := n.balance(block.Address, send.TokenStandard)
+		balance.Add(balance, send.Amount)
+		// Like a node, the amount is left on the paired send block, since
+		// the receive block must match its hash
+		published.PairedAccountBlock = send
+
+	default:
+		return fmt.Errorf("unsupported block type %d", block.BlockType)
+	}
+
+	n.blocks[block.Hash] = published
+	n.unconfirmed = append(n.unconfirmed, published)
+	a.height = block.Height
+	a.frontier = block.Hash
+	a.blocks = append(a.blocks, block.Hash)
+	return nil
+}
+
+// takeUnreceived removes a send block from the unreceived blocks of an address
+func (n *Node) takeUnreceived(address types.Address, hash types.Hash) (*api.AccountBlock, error) {
+	pending := n.unreceived[address]
+	for i, candidate := range pending {
+		if candidate == hash {
+			n.unreceived[address] = append(pending[:i:i], pending[i+1:]...)
+			return n.blocks[hash], nil
+		}
+	}
+	return nil, fmt.Errorf("block %s is not waiting to be received by %s", hash, address)
+}
+
+func (n *Node) knownMomentum(acknowledged types.HashHeight) bool {
+	for _, momentum := range n.momentums {
+		if momentum.Hash == acknowledged.Hash && momentum.Height == acknowledged.Height {
+			return true
+		}
+	}
+	return false
+}
+
+// fusedQsr returns the QSR fused for a beneficiary
+func (n *Node) fusedQsr(beneficiary types.Address) *big.Int {
+	fused := new(big.Int)
+	for _, f := range n.fusions {
+		if f.entry.Beneficiary == beneficiary {
+			fused.Add(fused, f.entry.QsrAmount)
+		}
+	}
+	return fused
+}
+
+// availablePlasma returns the plasma of an address. Published blocks do not
+// consume plasma in the mock.
+func (n *Node) availablePlasma(address types.Address) uint64 {
+	units := new(big.Int).Div(n.fusedQsr(address), big.NewInt(constants.CostPerFusionUnit)).Uint64()
+	if units > constants.MaxFusionUnitsPerAccount {
+		units = constants.MaxFusionUnitsPerAccount
+	}
+	return units*constants.PlasmaPerFusionUnit + n.account(address).plasma
+}
+
+// requiredPlasma returns the base plasma of a block
+func requiredPlasma(blockType uint64, toAddress types.Address, dataLength int) uint64 {
+	if blockType == nom.BlockTypeUserSend && types.IsEmbeddedAddress(toAddress) {
+		return constants.EmbeddedSimplePlasma
+	}
+	if blockType == nom.BlockTypeUserReceive {
+		return constants.AccountBlockBasePlasma
+	}
+	return constants.AccountBlockBasePlasma + uint64(dataLength)*constants.ABByteDataPlasma
+}
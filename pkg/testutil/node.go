@@ -0,0 +1,489 @@
+package testutil
+
+import (
+	"encoding/binary"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/rpc/server"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+// Node is an in-process Zenon node for integration tests. It serves the
+// ledger, plasma, stake, pillar, sentinel, token and liquidity APIs over a
+// JSON-RPC WebSocket from an in-memory ledger. Published blocks are checked
+// against the account chain and their signature, update balances and apply
+// the effects of embedded contract calls. Contract responses are delivered
+// as unreceived blocks, as on a real node.
+type Node struct {
+	// URL is the WebSocket URL to pass to client.New
+	URL string
+
+	httpServer *httptest.Server
+	rpcServer  *server.Server
+
+	mu         sync.Mutex
+	now        time.Time
+	momentums  []*nom.Momentum
+	accounts   map[types.Address]*account
+	blocks     map[types.Hash]*api.AccountBlock
+	unreceived map[types.Address][]types.Hash
+	// unconfirmed holds blocks published since the last momentum
+	unconfirmed []*api.AccountBlock
+	tokens      map[types.ZenonTokenStandard]*api.Token
+	fusions     []*fusion
+	stakes      []*embedded.StakeEntry
+	pillars     []*embedded.PillarInfo
+	delegations map[types.Address]string
+	sentinels   map[types.Address]*embedded.SentinelInfo
+	deposits    map[types.Address]map[types.Address]*big.Int
+	rewards     map[types.Address]map[types.Address]*definition.RewardDeposit
+	history     map[types.Address]map[types.Address][]*embedded.RewardHistoryEntry
+	failures    map[string]*failure
+	stalls      map[string]chan struct{}
+	sequence    uint64
+}
+
+// account is the chain and balances of one address
+type account struct {
+	height   uint64
+	frontier types.Hash
+	balances map[types.ZenonTokenStandard]*big.Int
+	// plasma is granted on top of the plasma of fused QSR
+	plasma uint64
+	blocks []types.Hash
+}
+
+// fusion is a fusion entry together with the address that fused it
+type fusion struct {
+	owner types.Address
+	entry *embedded.FusionEntry
+}
+
+// failure is a scripted error returned by an RPC method
+type failure struct {
+	err error
+	// remaining is the number of calls left to fail; negative fails every call
+	remaining int
+}
+
+// NewNode starts a mock node with ZNN and QSR issued and a genesis momentum.
+// The node is stopped when the test finishes.
+func NewNode(t testing.TB) *Node {
+	t.Helper()
+
+	n := &Node{
+		now:         time.Unix(1700000000, 0).UTC(),
+		accounts:    make(map[types.Address]*account),
+		blocks:      make(map[types.Hash]*api.AccountBlock),
+		unreceived:  make(map[types.Address][]types.Hash),
+		tokens:      make(map[types.ZenonTokenStandard]*api.Token),
+		delegations: make(map[types.Address]string),
+		sentinels:   make(map[types.Address]*embedded.SentinelInfo),
+		deposits:    make(map[types.Address]map[types.Address]*big.Int),
+		rewards:     make(map[types.Address]map[types.Address]*definition.RewardDeposit),
+		history:     make(map[types.Address]map[types.Address][]*embedded.RewardHistoryEntry),
+		failures:    make(map[string]*failure),
+		stalls:      make(map[string]chan struct{}),
+	}
+	n.tokens[types.ZnnTokenStandard] = coinToken("Zenon Coin", "ZNN", types.ZnnTokenStandard)
+	n.tokens[types.QsrTokenStandard] = coinToken("Quasar", "QSR", types.QsrTokenStandard)
+	n.momentums = []*nom.Momentum{{
+		ChainIdentifier: 1,
+		Height:          1,
+		Hash:            n.nextHash("momentum"),
+		TimestampUnix:   uint64(n.now.Unix()),
+	}}
+
+	n.rpcServer = server.NewServer()
+	receivers := map[string]any{
+		"ledger":             &ledgerAPI{node: n},
+		"embedded.plasma":    &plasmaAPI{node: n},
+		"embedded.stake":     &stakeAPI{node: n},
+		"embedded.pillar":    &pillarAPI{node: n},
+		"embedded.sentinel":  &sentinelAPI{node: n},
+		"embedded.token":     &tokenAPI{node: n},
+		"embedded.liquidity": &liquidityAPI{node: n},
+	}
+	for name, receiver := range receivers {
+		if err := n.rpcServer.RegisterName(name, receiver); err != nil {
+			t.Fatalf("failed to register %s API: %v", name, err)
+		}
+	}
+
+	n.httpServer = httptest.NewServer(n.rpcServer.WebsocketHandler([]string{"*"}))
+	n.URL = "ws" + strings.TrimPrefix(n.httpServer.URL, "http")
+	t.Cleanup(n.Close)
+
+	return n
+}
+
+// Close stops the node
+func (n *Node) Close() {
+	n.mu.Lock()
+	for method, release := range n.stalls {
+		close(release)
+		delete(n.stalls, method)
+	}
+	n.mu.Unlock()
+
+	n.httpServer.Close()
+	n.rpcServer.Stop()
+}
+
+// Stall makes calls of an RPC method wait without answering until the
+// returned function is called or the node is closed
+func (n *Node) Stall(method string) func() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	release := make(chan struct{})
+	n.stalls[method] = release
+	return func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if n.stalls[method] == release {
+			close(release)
+			delete(n.stalls, method)
+		}
+	}
+}
+
+// Fail makes every call of an RPC method (e.g. "ledger.getFrontierMomentum")
+// return err until ClearFailures is called
+func (n *Node) Fail(method string, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.failures[method] = &failure{err: err, remaining: -1}
+}
+
+// FailNext makes the next call of an RPC method return err
+func (n *Node) FailNext(method string, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.failures[method] = &failure{err: err, remaining: 1}
+}
+
+// ClearFailures removes every scripted error
+func (n *Node) ClearFailures() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.failures = make(map[string]*failure)
+}
+
+// SetTime sets the time used for momentum and stake timestamps
+func (n *Node) SetTime(now time.Time) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.now = now.UTC()
+}
+
+// AdvanceTime moves the node time forward
+func (n *Node) AdvanceTime(d time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.now = n.now.Add(d)
+}
+
+// AddMomentums produces count momentums, each 10 seconds after the previous
+// one. The first momentum confirms every block published since the last one.
+func (n *Node) AddMomentums(count int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for i := 0; i < count; i++ {
+		n.now = n.now.Add(10 * time.Second)
+		frontier := n.frontierMomentum()
+		momentum := &nom.Momentum{
+			ChainIdentifier: 1,
+			Height:          frontier.Height + 1,
+			PreviousHash:    frontier.Hash,
+			Hash:            n.nextHash("momentum"),
+			TimestampUnix:   uint64(n.now.Unix()),
+		}
+		n.momentums = append(n.momentums, momentum)
+
+		for _, block := range n.unconfirmed {
+			block.ConfirmationDetail = &api.AccountBlockConfirmationDetail{
+				MomentumHeight:    momentum.Height,
+				MomentumHash:      momentum.Hash,
+				MomentumTimestamp: int64(momentum.TimestampUnix),
+			}
+		}
+		n.unconfirmed = nil
+	}
+}
+
+// Height returns the height of the frontier momentum
+func (n *Node) Height() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.frontierMomentum().Height
+}
+
+// SetBalance sets the balance of a token for an address
+func (n *Node) SetBalance(address types.Address, zts types.ZenonTokenStandard, amount *big.Int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.account(address).balances[zts] = new(big.Int).Set(amount)
+}
+
+// Balance returns the balance of a token for an address
+func (n *Node) Balance(address types.Address, zts types.ZenonTokenStandard) *big.Int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return new(big.Int).Set(n.balance(address, zts))
+}
+
+// SetPlasma grants an address plasma on top of the plasma of its fused QSR
+func (n *Node) SetPlasma(address types.Address, plasma uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.account(address).plasma = plasma
+}
+
+// Send delivers amount from a contract or any other address to an address
+// as an unreceived block, without checking the sender's balance
+func (n *Node) Send(from, to types.Address, zts types.ZenonTokenStandard, amount *big.Int) types.Hash {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.deliver(from, to, zts, amount)
+}
+
+// Unreceived returns the hashes of the blocks waiting to be received by an address
+func (n *Node) Unreceived(address types.Address) []types.Hash {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]types.Hash(nil), n.unreceived[address]...)
+}
+
+// Blocks returns the account blocks published by an address, oldest first
+func (n *Node) Blocks(address types.Address) []*api.AccountBlock {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var blocks []*api.AccountBlock
+	for _, hash := range n.account(address).blocks {
+		blocks = append(blocks, n.blocks[hash])
+	}
+	return blocks
+}
+
+// AddToken registers a token
+func (n *Node) AddToken(token *api.Token) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.tokens[token.ZenonTokenStandard] = token
+}
+
+// AddPillar registers a pillar
+func (n *Node) AddPillar(pillar *embedded.PillarInfo) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if pillar.Weight == nil {
+		pillar.Weight = new(big.Int)
+	}
+	if pillar.CurrentStats == nil {
+		pillar.CurrentStats = &embedded.PillarStats{}
+	}
+	n.pillars = append(n.pillars, pillar)
+}
+
+// AddFusion records a fusion entry of owner without a block, for seeding
+func (n *Node) AddFusion(owner types.Address, entry *embedded.FusionEntry) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.fusions = append(n.fusions, &fusion{owner: owner, entry: entry})
+}
+
+// AddStake records a stake entry without a block, for seeding
+func (n *Node) AddStake(entry *embedded.StakeEntry) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.stakes = append(n.stakes, entry)
+}
+
+// SetUncollectedReward sets the reward an address can collect from the
+// stake, pillar, sentinel or liquidity contract
+func (n *Node) SetUncollectedReward(contract, address types.Address, znn, qsr *big.Int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.rewards[contract] == nil {
+		n.rewards[contract] = make(map[types.Address]*definition.RewardDeposit)
+	}
+	owner := address
+	n.rewards[contract][address] = &definition.RewardDeposit{
+		Address: &owner,
+		Znn:     new(big.Int).Set(znn),
+		Qsr:     new(big.Int).Set(qsr),
+	}
+}
+
+// Fusions returns the fusion entries created by an address
+func (n *Node) Fusions(owner types.Address) []*embedded.FusionEntry {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.fusionsOf(owner)
+}
+
+// Stakes returns the stake entries of an address
+func (n *Node) Stakes(address types.Address) []*embedded.StakeEntry {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.stakesOf(address)
+}
+
+// Deposit returns the QSR an address has deposited in the pillar or sentinel contract
+func (n *Node) Deposit(contract, address types.Address) *big.Int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return new(big.Int).Set(n.deposit(contract, address))
+}
+
+// scripted waits while a method is stalled, then returns its scripted
+// error, if any
+func (n *Node) scripted(method string) error {
+	n.mu.Lock()
+	release := n.stalls[method]
+	n.mu.Unlock()
+	if release != nil {
+		<-release
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, found := n.failures[method]
+	if !found {
+		return nil
+	}
+	if f.remaining > 0 {
+		f.remaining--
+		if f.remaining == 0 {
+			delete(n.failures, method)
+		}
+	}
+	return f.err
+}
+
+func (n *Node) frontierMomentum() *nom.Momentum {
+	return n.momentums[len(n.momentums)-1]
+}
+
+// account returns the account of an address, creating it on first use
+func (n *Node) account(address types.Address) *account {
+	a, found := n.accounts[address]
+	if !found {
+		a = &account{balances: make(map[types.ZenonTokenStandard]*big.Int)}
+		n.accounts[address] = a
+	}
+	return a
+}
+
+// balance returns the live balance of a token, creating it on first use
+func (n *Node) balance(address types.Address, zts types.ZenonTokenStandard) *big.Int {
+	a := n.account(address)
+	if a.balances[zts] == nil {
+		a.balances[zts] = new(big.Int)
+	}
+	return a.balances[zts]
+}
+
+// deposit returns the live QSR deposit of an address in a contract
+func (n *Node) deposit(contract, address types.Address) *big.Int {
+	if n.deposits[contract] == nil {
+		n.deposits[contract] = make(map[types.Address]*big.Int)
+	}
+	if n.deposits[contract][address] == nil {
+		n.deposits[contract][address] = new(big.Int)
+	}
+	return n.deposits[contract][address]
+}
+
+func (n *Node) fusionsOf(owner types.Address) []*embedded.FusionEntry {
+	var entries []*embedded.FusionEntry
+	for _, f := range n.fusions {
+		if f.owner == owner {
+			entries = append(entries, f.entry)
+		}
+	}
+	return entries
+}
+
+func (n *Node) stakesOf(address types.Address) []*embedded.StakeEntry {
+	var entries []*embedded.StakeEntry
+	for _, entry := range n.stakes {
+		if entry.Address == address {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// nextHash returns a unique hash for blocks and momentums created by the node
+func (n *Node) nextHash(kind string) types.Hash {
+	n.sequence++
+	data := binary.BigEndian.AppendUint64([]byte(kind), n.sequence)
+	return types.NewHash(data)
+}
+
+// deliver creates a send block from an address that has no chain in the
+// node, such as an embedded contract, and leaves it unreceived
+func (n *Node) deliver(from, to types.Address, zts types.ZenonTokenStandard, amount *big.Int) types.Hash {
+	block := &api.AccountBlock{
+		AccountBlock: nom.AccountBlock{
+			Version:         1,
+			ChainIdentifier: 1,
+			BlockType:       nom.BlockTypeContractSend,
+			Hash:            n.nextHash("send"),
+			Address:         from,
+			ToAddress:       to,
+			TokenStandard:   zts,
+			Amount:          new(big.Int).Set(amount),
+		},
+		TokenInfo: n.tokens[zts],
+	}
+	if !types.IsEmbeddedAddress(from) {
+		block.BlockType = nom.BlockTypeUserSend
+	}
+	n.blocks[block.Hash] = block
+	n.unconfirmed = append(n.unconfirmed, block)
+	n.unreceived[to] = append(n.unreceived[to], block.Hash)
+	return block.Hash
+}
+
+// coinToken describes ZNN or QSR
+func coinToken(name, symbol string, zts types.ZenonTokenStandard) *api.Token {
+	return &api.Token{
+		TokenName:          name,
+		TokenSymbol:        symbol,
+		TokenDomain:        "zenon.network",
+		TotalSupply:        big.NewInt(0),
+		MaxSupply:          big.NewInt(0),
+		Decimals:           8,
+		Owner:              types.TokenContract,
+		ZenonTokenStandard: zts,
+		IsMintable:         true,
+		IsBurnable:         true,
+		IsUtility:          true,
+	}
+}
+
+// page returns one page of a list
+func page[T any](list []T, pageIndex, pageSize uint32) []T {
+	start := uint64(pageIndex) * uint64(pageSize)
+	if start >= uint64(len(list)) {
+		return []T{}
+	}
+	end := start + uint64(pageSize)
+	if end > uint64(len(list)) {
+		end = uint64(len(list))
+	}
+	return list[start:end]
+}
@@ -0,0 +1,231 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/0x3639/znn_cli_go/pkg/transaction"
+)
+
+// connect starts a node and returns a client with the keypair of the fixture mnemonic
+func connect(t *testing.T) (*Node, *client.Client, *wallet.KeyPair, types.Address) {
+	t.Helper()
+
+	node := NewNode(t)
+	c, err := client.New(t.Context(), node.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+
+	ks, err := wallet.NewKeyStoreFromMnemonic(MnemonicFixture)
+	require.NoError(t, err)
+	keypair, err := ks.GetKeyPair(0)
+	require.NoError(t, err)
+	address, err := keypair.GetAddress()
+	require.NoError(t, err)
+
+	node.SetPlasma(*address, 10*constants.AccountBlockBasePlasma)
+	return node, c, keypair, *address
+}
+
+func coins(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), big.NewInt(1e8))
+}
+
+func TestNodeSendAndReceive(t *testing.T) {
+	node, c, keypair, address := connect(t)
+	node.SetBalance(address, types.ZnnTokenStandard, coins(10))
+
+	template := c.LedgerApi.SendTemplate(ValidAddress, types.ZnnTokenStandard, coins(3), nil)
+	require.NoError(t, transaction.BuildAndSend(t.Context(), c.RpcClient, address, template, keypair))
+	assert.Equal(t, coins(7), node.Balance(address, types.ZnnTokenStandard))
+	assert.Equal(t, []types.Hash{template.Hash}, node.Unreceived(ValidAddress))
+
+	// The same block cannot be published twice
+	assert.Error(t, transaction.Publish(t.Context(), c.RpcClient, template))
+
+	node.Send(ProducerAddress, address, types.QsrTokenStandard, coins(5))
+	received, err := transaction.ReceiveAll(t.Context(), c.RpcClient, address, keypair, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, received)
+	assert.Equal(t, coins(5), node.Balance(address, types.QsrTokenStandard))
+
+	info, err := c.LedgerApi.GetAccountInfoByAddress(address)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), info.AccountHeight)
+
+	unconfirmed, err := c.LedgerApi.GetUnconfirmedBlocksByAddress(address, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, unconfirmed.Count)
+	node.AddMomentums(1)
+	unconfirmed, err = c.LedgerApi.GetUnconfirmedBlocksByAddress(address, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, unconfirmed.Count)
+	assert.Equal(t, node.Height(), node.Blocks(address)[0].ConfirmationDetail.MomentumHeight)
+}
+
+func TestNodeRejectsInvalidBlocks(t *testing.T) {
+	node, c, keypair, address := connect(t)
+	node.SetBalance(address, types.ZnnTokenStandard, coins(1))
+
+	// Insufficient balance
+	template := c.LedgerApi.SendTemplate(ValidAddress, types.ZnnTokenStandard, coins(2), nil)
+	assert.Error(t, transaction.BuildAndSend(t.Context(), c.RpcClient, address, template, keypair))
+
+	// Tampered after signing
+	template = c.LedgerApi.SendTemplate(ValidAddress, types.ZnnTokenStandard, coins(1), nil)
+	require.NoError(t, transaction.Autofill(t.Context(), c.RpcClient, address, template))
+	template.Hash = template.ComputeHash()
+	require.NoError(t, transaction.EnsurePlasmaOrPoW(t.Context(), c.RpcClient, address, template))
+	require.NoError(t, transaction.Sign(template, keypair))
+	template.Amount = big.NewInt(1)
+	template.Hash = template.ComputeHash()
+	assert.Error(t, transaction.Publish(t.Context(), c.RpcClient, template))
+
+	assert.Empty(t, node.Blocks(address))
+	assert.Equal(t, coins(1), node.Balance(address, types.ZnnTokenStandard))
+}
+
+func TestNodePlasma(t *testing.T) {
+	node, c, keypair, address := connect(t)
+	node.SetBalance(address, types.QsrTokenStandard, coins(100))
+
+	// Addresses without plasma need PoW
+	required, err := transaction.RequiredPlasma(t.Context(), c.RpcClient, ProducerAddress, c.PlasmaApi.Fuse(address, coins(20)))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(constants.EmbeddedSimplePlasma*constants.PoWDifficultyPerPlasma), required.RequiredDifficulty)
+
+	chain := transaction.NewChain(c.RpcClient, address, keypair)
+	require.NoError(t, chain.Send(t.Context(), c.PlasmaApi.Fuse(address, coins(20))))
+	require.NoError(t, chain.Send(t.Context(), c.PlasmaApi.Fuse(ValidAddress, coins(10))))
+	assert.Equal(t, coins(70), node.Balance(address, types.QsrTokenStandard))
+
+	info, err := c.PlasmaApi.Get(address)
+	require.NoError(t, err)
+	assert.Equal(t, coins(20), info.QsrAmount)
+	assert.Equal(t, uint64(20*constants.PlasmaPerFusionUnit+10*constants.AccountBlockBasePlasma), info.CurrentPlasma)
+
+	// FusionEntryList.UnmarshalJSON in go-zenon drops every entry, so the
+	// entries are read from the node
+	entries := node.Fusions(address)
+	require.Len(t, entries, 2)
+	assert.Equal(t, ValidAddress, entries[1].Beneficiary)
+
+	// Fusions can only be cancelled once they expire
+	id := entries[0].Id
+	assert.Error(t, transaction.BuildAndSend(t.Context(), c.RpcClient, address, c.PlasmaApi.Cancel(id), keypair))
+	node.AddMomentums(int(constants.FuseExpiration))
+	require.NoError(t, transaction.BuildAndSend(t.Context(), c.RpcClient, address, c.PlasmaApi.Cancel(id), keypair))
+	assert.Len(t, node.Fusions(address), 1)
+
+	_, err = transaction.ReceiveAll(t.Context(), c.RpcClient, address, keypair, nil)
+	require.NoError(t, err)
+	assert.Equal(t, coins(90), node.Balance(address, types.QsrTokenStandard))
+}
+
+func TestNodeStakeAndRewards(t *testing.T) {
+	node, c, keypair, address := connect(t)
+	node.SetBalance(address, types.ZnnTokenStandard, coins(100))
+
+	month := int64(constants.StakeTimeUnitSec)
+	require.NoError(t, transaction.BuildAndSend(t.Context(), c.RpcClient, address, c.StakeApi.Stake(month, coins(10)), keypair))
+	stakes, err := c.StakeApi.GetEntriesByAddress(address, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, stakes.Count)
+	assert.Equal(t, coins(10), stakes.TotalAmount)
+
+	id := stakes.Entries[0].Id
+	assert.Error(t, transaction.BuildAndSend(t.Context(), c.RpcClient, address, c.StakeApi.Cancel(id), keypair))
+	node.AdvanceTime(time.Duration(month) * time.Second)
+	require.NoError(t, transaction.BuildAndSend(t.Context(), c.RpcClient, address, c.StakeApi.Cancel(id), keypair))
+	assert.Empty(t, node.Stakes(address))
+
+	node.SetUncollectedReward(types.StakeContract, address, coins(1), coins(2))
+	reward, err := c.StakeApi.GetUncollectedReward(address)
+	require.NoError(t, err)
+	assert.Equal(t, coins(2), reward.Qsr)
+	require.NoError(t, transaction.BuildAndSend(t.Context(), c.RpcClient, address, c.StakeApi.CollectReward(), keypair))
+
+	received, err := transaction.ReceiveAll(t.Context(), c.RpcClient, address, keypair, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, received)
+	assert.Equal(t, coins(101), node.Balance(address, types.ZnnTokenStandard))
+	assert.Equal(t, coins(2), node.Balance(address, types.QsrTokenStandard))
+
+	history, err := c.StakeApi.GetFrontierRewardByPage(address, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), history.Count)
+	assert.Equal(t, coins(1), history.List[0].Znn)
+
+	// Nothing left to collect
+	assert.Error(t, transaction.BuildAndSend(t.Context(), c.RpcClient, address, c.StakeApi.CollectReward(), keypair))
+}
+
+func TestNodeScriptedFailures(t *testing.T) {
+	node, c, _, address := connect(t)
+	failure := errors.New("node is syncing")
+
+	node.FailNext("embedded.plasma.get", failure)
+	_, err := c.PlasmaApi.Get(address)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "node is syncing")
+	_, err = c.PlasmaApi.Get(address)
+	assert.NoError(t, err)
+
+	node.Fail("ledger.getAccountInfoByAddress", failure)
+	for i := 0; i < 2; i++ {
+		_, err = c.LedgerApi.GetAccountInfoByAddress(address)
+		assert.Error(t, err)
+	}
+	node.ClearFailures()
+	_, err = c.LedgerApi.GetAccountInfoByAddress(address)
+	assert.NoError(t, err)
+}
+
+func TestNodeStall(t *testing.T) {
+	node, _, keypair, address := connect(t)
+	node.SetBalance(address, types.ZnnTokenStandard, coins(10))
+
+	ctx, cancel := context.WithCancel(client.WithRequestTimeout(t.Context(), 100*time.Millisecond))
+	defer cancel()
+	c, err := client.New(ctx, node.URL)
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	// A stalled publish may still go through, so the error says so
+	release := node.Stall("ledger.publishRawTransaction")
+	template := c.LedgerApi.SendTemplate(ValidAddress, types.ZnnTokenStandard, coins(1), nil)
+	err = transaction.BuildAndSend(ctx, c.RpcClient, address, template, keypair)
+	assert.Equal(t, errs.Timeout, errs.KindOf(err))
+	assert.Contains(t, err.Error(), "may still be published")
+	release()
+
+	// Interrupted while the sign guard approves the block, before PoW and signing
+	transaction.SetSignGuard(func(*nom.AccountBlock) error {
+		cancel()
+		return nil
+	})
+	defer transaction.SetSignGuard(nil)
+	template = c.LedgerApi.SendTemplate(ValidAddress, types.ZnnTokenStandard, coins(1), nil)
+	err = transaction.BuildAndSend(ctx, c.RpcClient, address, template, keypair)
+	assert.Equal(t, errs.Interrupted, errs.KindOf(err))
+	assert.Contains(t, err.Error(), "nothing was signed or published")
+	assert.Empty(t, template.Signature)
+
+	// Interrupted before anything is signed
+	template = c.LedgerApi.SendTemplate(ValidAddress, types.ZnnTokenStandard, coins(1), nil)
+	err = transaction.BuildAndSend(ctx, c.RpcClient, address, template, keypair)
+	assert.Equal(t, errs.Interrupted, errs.KindOf(err))
+	assert.Contains(t, err.Error(), "nothing was signed or published")
+}
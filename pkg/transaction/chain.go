@@ -0,0 +1,51 @@
+package transaction
+
+import (
+	"context"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+)
+
+// Chain publishes consecutive blocks of one account without waiting for
+// each block to be visible at the node. Only the first block is autofilled;
+// each following block links to the previous one locally and acknowledges
+// the same momentum. After a failure the next block is autofilled again.
+type Chain struct {
+	client   *rpc_client.RpcClient
+	address  types.Address
+	keypair  *wallet.KeyPair
+	previous *nom.AccountBlock
+}
+
+// NewChain creates a chain for the account of keypair at address
+func NewChain(c *rpc_client.RpcClient, address types.Address, keypair *wallet.KeyPair) *Chain {
+	return &Chain{client: c, address: address, keypair: keypair}
+}
+
+// Send builds, signs and publishes template as the next block of the chain
+func (ch *Chain) Send(ctx context.Context, template *nom.AccountBlock) error {
+	if ch.previous == nil {
+		if err := Autofill(ctx, ch.client, ch.address, template); err != nil {
+			return stepFailed(stepAutofill, template, err)
+		}
+	} else {
+		template.Address = ch.address
+		template.Height = ch.previous.Height + 1
+		template.PreviousHash = ch.previous.Hash
+		template.MomentumAcknowledged = ch.previous.MomentumAcknowledged
+	}
+
+	template.Hash = template.ComputeHash()
+
+	if err := signAndPublish(ctx, ch.client, ch.address, template, ch.keypair); err != nil {
+		ch.previous = nil
+		return err
+	}
+
+	ch.previous = template
+	return nil
+}
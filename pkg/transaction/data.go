@@ -0,0 +1,87 @@
+package transaction
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm/constants"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+)
+
+// MaxDataSize is the maximum size in bytes of account block data accepted by the network
+const MaxDataSize = constants.MaxDataLength
+
+// EncodeData builds a block data payload from a UTF-8 memo, a hex string or a
+// base64 string. At most one of the inputs may be set; if none is set the
+// returned data is nil.
+//
+// Returns an error if more than one input is set, the encoding is invalid,
+// or the payload exceeds MaxDataSize.
+func EncodeData(memo, dataHex, dataBase64 string) ([]byte, error) {
+	set := 0
+	for _, value := range []string{memo, dataHex, dataBase64} {
+		if value != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, errs.New(errs.UserInput, "only one of memo, hex data or base64 data can be set")
+	}
+
+	var data []byte
+	switch {
+	case memo != "":
+		data = []byte(memo)
+	case dataHex != "":
+		decoded, err := hex.DecodeString(strings.TrimPrefix(dataHex, "0x"))
+		if err != nil {
+			return nil, errs.Errorf(errs.UserInput, "invalid hex data: %w", err)
+		}
+		data = decoded
+	case dataBase64 != "":
+		decoded, err := base64.StdEncoding.DecodeString(dataBase64)
+		if err != nil {
+			return nil, errs.Errorf(errs.UserInput, "invalid base64 data: %w", err)
+		}
+		data = decoded
+	default:
+		return nil, nil
+	}
+
+	if len(data) > MaxDataSize {
+		return nil, errs.Errorf(errs.UserInput, "data is %d bytes, maximum is %d bytes", len(data), MaxDataSize)
+	}
+
+	return data, nil
+}
+
+// RequiredPlasma queries the plasma and PoW difficulty required to publish the template.
+// The requirement grows with the size of the template's data.
+func RequiredPlasma(ctx context.Context, c *rpc_client.RpcClient, address types.Address, template *nom.AccountBlock) (*embedded.GetRequiredResult, error) {
+	toAddr := &template.ToAddress
+	param := embedded.GetRequiredParam{
+		SelfAddr:  address,
+		BlockType: template.BlockType,
+		ToAddr:    toAddr,
+		Data:      template.Data,
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, errs.FromRPC(err)
+	}
+	result, err := c.PlasmaApi.GetRequiredPoWForAccountBlock(param)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get required PoW: %w", errs.FromRPC(err))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package transaction
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/common/types"
+
+	"github.com/0x3639/znn_cli_go/pkg/client"
+	"github.com/0x3639/znn_cli_go/pkg/testutil"
+)
+
+// TestBuildAndSendReplay sends two blocks against testdata/send.jsonl, a
+// recording of the same sends to a node, and checks the chain they form
+func TestBuildAndSendReplay(t *testing.T) {
+	replay, err := client.LoadReplay("testdata/send.jsonl")
+	require.NoError(t, err)
+	c, err := client.New(client.WithReplay(t.Context(), replay), "ws://127.0.0.1:1")
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	ks, err := wallet.NewKeyStoreFromMnemonic(testutil.MnemonicFixture)
+	require.NoError(t, err)
+	keypair, err := ks.GetKeyPair(0)
+	require.NoError(t, err)
+	address, err := keypair.GetAddress()
+	require.NoError(t, err)
+
+	var blocks []types.Hash
+	for height := uint64(1); height <= 2; height++ {
+		template := c.LedgerApi.SendTemplate(testutil.ValidAddress, types.ZnnTokenStandard, big.NewInt(3e8), nil)
+		require.NoError(t, BuildAndSend(t.Context(), c.RpcClient, *address, template, keypair))
+		assert.Equal(t, height, template.Height)
+		assert.Equal(t, uint64(1), template.MomentumAcknowledged.Height)
+		if len(blocks) > 0 {
+			assert.Equal(t, blocks[len(blocks)-1], template.PreviousHash, "the recorded frontier block is the first block sent")
+		}
+		assert.Equal(t, template.ComputeHash(), template.Hash)
+		blocks = append(blocks, template.Hash)
+	}
+}
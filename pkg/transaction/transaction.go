@@ -4,14 +4,17 @@
 package transaction
 
 import (
+	"context"
 	"fmt"
 	"math/big"
+	"sync/atomic"
 
 	"github.com/0x3639/znn-sdk-go/wallet"
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/pow"
-	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 
 	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
 )
@@ -33,29 +36,37 @@ const (
 //  3. Sets MomentumAcknowledged to current frontier momentum
 //
 // Parameters:
+//   - ctx: Context that stops the autofill between node queries
 //   - c: RPC client for querying account and momentum info
 //   - address: Address of the account creating the transaction
 //   - template: AccountBlock template to autofill
 //
 // Returns an error if unable to query account info or frontier momentum.
-func Autofill(c *rpc_client.RpcClient, address types.Address, template *nom.AccountBlock) error {
+func Autofill(ctx context.Context, c *rpc_client.RpcClient, address types.Address, template *nom.AccountBlock) error {
 	// Set the address field
 	template.Address = address
 
+	if err := ctx.Err(); err != nil {
+		return errs.FromRPC(err)
+	}
+
 	// Get account info to determine height
 	accountInfo, err := c.LedgerApi.GetAccountInfoByAddress(address)
 	if err != nil {
-		return fmt.Errorf("failed to get account info: %w", err)
+		return fmt.Errorf("failed to get account info: %w", errs.FromRPC(err))
 	}
 
 	// Set height (next block = current height + 1)
 	template.Height = accountInfo.AccountHeight + 1
 
 	// Set previous hash (if not first block)
+	if err := ctx.Err(); err != nil {
+		return errs.FromRPC(err)
+	}
 	if accountInfo.AccountHeight > 0 {
 		frontierBlock, err := c.LedgerApi.GetFrontierAccountBlock(address)
 		if err != nil {
-			return fmt.Errorf("failed to get frontier account block: %w", err)
+			return fmt.Errorf("failed to get frontier account block: %w", errs.FromRPC(err))
 		}
 		template.PreviousHash = frontierBlock.Hash
 	} else {
@@ -64,9 +75,12 @@ func Autofill(c *rpc_client.RpcClient, address types.Address, template *nom.Acco
 	}
 
 	// Get frontier momentum for acknowledgment
+	if err := ctx.Err(); err != nil {
+		return errs.FromRPC(err)
+	}
 	momentum, err := c.LedgerApi.GetFrontierMomentum()
 	if err != nil {
-		return fmt.Errorf("failed to get frontier momentum: %w", err)
+		return fmt.Errorf("failed to get frontier momentum: %w", errs.FromRPC(err))
 	}
 
 	template.MomentumAcknowledged = types.HashHeight{
@@ -81,51 +95,73 @@ func Autofill(c *rpc_client.RpcClient, address types.Address, template *nom.Acco
 // If not, it generates Proof of Work with the required difficulty.
 //
 // The function:
-//  1. Computes transaction hash
-//  2. Queries required PoW difficulty based on available plasma
-//  3. If plasma sufficient (difficulty = 0), returns immediately
-//  4. Otherwise, generates PoW with required difficulty
+//  1. Queries required PoW difficulty based on available plasma
+//  2. If plasma sufficient (difficulty = 0), records the fused plasma
+//  3. Otherwise, generates PoW over the address and previous hash
+//  4. Recomputes the transaction hash, which covers plasma, difficulty and nonce
 //
 // Parameters:
+//   - ctx: Context that stops the query or the PoW generation
 //   - c: RPC client for querying plasma requirements
 //   - address: Address of the account creating the transaction
 //   - template: AccountBlock template (must already have hash computed)
 //
 // Returns an error if unable to query plasma or generate PoW.
-func EnsurePlasmaOrPoW(c *rpc_client.RpcClient, address types.Address, template *nom.AccountBlock) error {
-	// Check required PoW difficulty
-	toAddr := &template.ToAddress
-	param := embedded.GetRequiredParam{
-		SelfAddr:  address,
-		BlockType: template.BlockType,
-		ToAddr:    toAddr,
-		Data:      template.Data,
+func EnsurePlasmaOrPoW(ctx context.Context, c *rpc_client.RpcClient, address types.Address, template *nom.AccountBlock) error {
+	if err := setPlasma(ctx, c, address, template); err != nil {
+		return err
 	}
+	return generatePoW(ctx, template)
+}
 
-	result, err := c.PlasmaApi.GetRequiredPoWForAccountBlock(param)
+// setPlasma queries the plasma requirement of a template and records either
+// the fused plasma it uses or the PoW difficulty it needs
+func setPlasma(ctx context.Context, c *rpc_client.RpcClient, address types.Address, template *nom.AccountBlock) error {
+	result, err := RequiredPlasma(ctx, c, address, template)
 	if err != nil {
-		return fmt.Errorf("failed to get required PoW: %w", err)
+		return err
 	}
 
 	// If plasma is sufficient, no PoW needed
 	if result.RequiredDifficulty == 0 {
 		template.FusedPlasma = result.BasePlasma
-		return nil
+		template.Difficulty = 0
+	} else {
+		template.FusedPlasma = 0
+		template.Difficulty = result.RequiredDifficulty
+		if template.Difficulty < DefaultPoWDifficulty {
+			template.Difficulty = DefaultPoWDifficulty
+		}
 	}
+	template.Hash = template.ComputeHash()
+	return nil
+}
 
-	// Generate PoW
-	difficulty := result.RequiredDifficulty
-	if difficulty < DefaultPoWDifficulty {
-		difficulty = DefaultPoWDifficulty
+// generatePoW generates the PoW nonce for the difficulty set by setPlasma,
+// if any
+func generatePoW(ctx context.Context, template *nom.AccountBlock) error {
+	if template.Difficulty == 0 {
+		return nil
 	}
 
-	// Generate PoW nonce using SDK function
-	difficultyBig := new(big.Int).SetUint64(difficulty)
-	nonceBytes := pow.GetPoWNonce(difficultyBig, template.Hash)
+	// Generate PoW nonce using SDK function. The generation cannot be
+	// stopped, so an interrupted command returns without waiting for it.
+	difficultyBig := new(big.Int).SetUint64(template.Difficulty)
+	powHash := pow.GetAccountBlockHash(template)
+	nonce := make(chan []byte, 1)
+	go func() { nonce <- pow.GetPoWNonce(difficultyBig, powHash) }()
+	var nonceBytes []byte
+	select {
+	case nonceBytes = <-nonce:
+	case <-ctx.Done():
+		return errs.FromRPC(ctx.Err())
+	}
 
 	// Set the nonce
 	copy(template.Nonce.Data[:], nonceBytes)
-	template.Difficulty = difficulty
+
+	// FusedPlasma, Difficulty and Nonce are part of the block hash
+	template.Hash = template.ComputeHash()
 
 	return nil
 }
@@ -137,8 +173,24 @@ func EnsurePlasmaOrPoW(c *rpc_client.RpcClient, address types.Address, template
 //   - template: AccountBlock to sign (must have hash computed)
 //   - keypair: Wallet keypair to use for signing
 //
-// Returns an error if signing fails.
+// Returns an error if the sign guard rejects the transaction or signing fails.
 func Sign(template *nom.AccountBlock, keypair *wallet.KeyPair) error {
+	if err := approve(template); err != nil {
+		return err
+	}
+	return sign(template, keypair)
+}
+
+// approve passes a block to the sign guard, if one is set
+func approve(template *nom.AccountBlock) error {
+	if guard := signGuard.Load(); guard != nil {
+		return (*guard)(template)
+	}
+	return nil
+}
+
+// sign signs a block without consulting the sign guard
+func sign(template *nom.AccountBlock, keypair *wallet.KeyPair) error {
 	signature, err := keypair.Sign(template.Hash.Bytes())
 	if err != nil {
 		return fmt.Errorf("failed to sign transaction: %w", err)
@@ -149,12 +201,30 @@ func Sign(template *nom.AccountBlock, keypair *wallet.KeyPair) error {
 		return fmt.Errorf("failed to get public key: %w", err)
 	}
 
-	copy(template.Signature[:], signature)
-	copy(template.PublicKey[:], publicKey)
+	template.Signature = signature
+	template.PublicKey = publicKey
 
 	return nil
 }
 
+// SignGuard is called before every block is signed. Returning an error
+// prevents the block from being signed. Blocks built by BuildAndSend and
+// Chain are passed to it once their plasma or PoW difficulty is set, before
+// any PoW is generated.
+type SignGuard func(block *nom.AccountBlock) error
+
+var signGuard atomic.Pointer[SignGuard]
+
+// SetSignGuard sets the function that approves every block passed to Sign,
+// including blocks signed by BuildAndSend and Chain. Pass nil to remove it.
+func SetSignGuard(guard SignGuard) {
+	if guard == nil {
+		signGuard.Store(nil)
+		return
+	}
+	signGuard.Store(&guard)
+}
+
 // Publish publishes a signed and finalized transaction to the network.
 //
 // The transaction must be fully prepared:
@@ -164,12 +234,37 @@ func Sign(template *nom.AccountBlock, keypair *wallet.KeyPair) error {
 //   - Signed
 //
 // Parameters:
+//   - ctx: Context checked before the block is sent to the node
 //   - c: RPC client for publishing
 //   - template: Fully prepared AccountBlock
 //
 // Returns an error if the transaction is rejected by the node.
-func Publish(c *rpc_client.RpcClient, template *nom.AccountBlock) error {
-	return c.LedgerApi.PublishRawTransaction(template)
+func Publish(ctx context.Context, c *rpc_client.RpcClient, template *nom.AccountBlock) error {
+	if err := ctx.Err(); err != nil {
+		return errs.FromRPC(err)
+	}
+	err := errs.FromRPC(c.LedgerApi.PublishRawTransaction(template))
+	if recorder := publishRecorder.Load(); recorder != nil {
+		(*recorder)(template, err)
+	}
+	return err
+}
+
+// PublishRecorder is called after every publish attempt with the block and
+// the error returned by the node, if any
+type PublishRecorder func(block *nom.AccountBlock, err error)
+
+var publishRecorder atomic.Pointer[PublishRecorder]
+
+// SetPublishRecorder sets the function notified of every block passed to
+// Publish, including blocks published by BuildAndSend and Chain. Pass nil to
+// stop recording.
+func SetPublishRecorder(recorder PublishRecorder) {
+	if recorder == nil {
+		publishRecorder.Store(nil)
+		return
+	}
+	publishRecorder.Store(&recorder)
 }
 
 // BuildAndSend is a convenience function that performs the complete transaction flow:
@@ -180,8 +275,10 @@ func Publish(c *rpc_client.RpcClient, template *nom.AccountBlock) error {
 //  5. Publish to network
 //
 // This is the recommended way to send transactions as it handles all steps correctly.
+// When ctx is done or the node times out, the error reports which step was reached.
 //
 // Parameters:
+//   - ctx: Context that interrupts the flow
 //   - c: RPC client for querying and publishing
 //   - address: Address of the account creating the transaction
 //   - template: AccountBlock template (ToAddress, Amount, TokenStandard, Data, etc.)
@@ -192,33 +289,127 @@ func Publish(c *rpc_client.RpcClient, template *nom.AccountBlock) error {
 // Example:
 //
 //	template := c.LedgerApi.SendTemplate(toAddress, types.ZnnTokenStandard, amount, nil)
-//	err := transaction.BuildAndSend(c, myAddress, template, keypair)
+//	err := transaction.BuildAndSend(ctx, c, myAddress, template, keypair)
 //	if err != nil {
 //	    return fmt.Errorf("failed to send: %w", err)
 //	}
-func BuildAndSend(c *rpc_client.RpcClient, address types.Address, template *nom.AccountBlock, keypair *wallet.KeyPair) error {
+func BuildAndSend(ctx context.Context, c *rpc_client.RpcClient, address types.Address, template *nom.AccountBlock, keypair *wallet.KeyPair) error {
 	// 1. Autofill
-	if err := Autofill(c, address, template); err != nil {
-		return fmt.Errorf("autofill failed: %w", err)
+	if err := Autofill(ctx, c, address, template); err != nil {
+		return stepFailed(stepAutofill, template, err)
 	}
 
 	// 2. Compute hash
 	template.Hash = template.ComputeHash()
 
-	// 3. Ensure plasma or generate PoW
-	if err := EnsurePlasmaOrPoW(c, address, template); err != nil {
-		return fmt.Errorf("plasma/PoW failed: %w", err)
+	// 3-5. Ensure plasma or generate PoW, sign and publish
+	return signAndPublish(ctx, c, address, template, keypair)
+}
+
+// Steps of building and publishing a block, as reported when one fails
+const (
+	stepAutofill = "autofill"
+	stepPoW      = "plasma/PoW"
+	stepSign     = "signing"
+	stepSigned   = "signed"
+	stepPublish  = "publish"
+)
+
+// signAndPublish ensures plasma or PoW for an autofilled template with its
+// hash computed, then signs and publishes it. The sign guard approves the
+// block before PoW is generated, so a rejected block costs no PoW.
+func signAndPublish(ctx context.Context, c *rpc_client.RpcClient, address types.Address, template *nom.AccountBlock, keypair *wallet.KeyPair) error {
+	if err := setPlasma(ctx, c, address, template); err != nil {
+		return stepFailed(stepPoW, template, err)
+	}
+	if err := approve(template); err != nil {
+		return stepFailed(stepSign, template, err)
 	}
+	if err := generatePoW(ctx, template); err != nil {
+		return stepFailed(stepPoW, template, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return stepFailed(stepSign, template, errs.FromRPC(err))
+	}
+	if err := sign(template, keypair); err != nil {
+		return stepFailed(stepSign, template, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return stepFailed(stepSigned, template, errs.FromRPC(err))
+	}
+	if err := Publish(ctx, c, template); err != nil {
+		return stepFailed(stepPublish, template, err)
+	}
+	return nil
+}
 
-	// 4. Sign
-	if err := Sign(template, keypair); err != nil {
-		return fmt.Errorf("signing failed: %w", err)
+// stepFailed wraps the error of a step. When the step was interrupted or
+// timed out, the error reports how far the block got.
+func stepFailed(step string, template *nom.AccountBlock, err error) error {
+	kind := errs.KindOf(err)
+	if kind != errs.Interrupted && kind != errs.Timeout {
+		if step == stepSigned {
+			step = stepPublish
+		}
+		return fmt.Errorf("%s failed: %w", step, err)
 	}
 
-	// 5. Publish
-	if err := Publish(c, template); err != nil {
-		return fmt.Errorf("publish failed: %w", err)
+	reason := "interrupted"
+	if kind == errs.Timeout {
+		reason = "timed out"
 	}
+	switch step {
+	case stepSigned:
+		return errs.Errorf(kind, "%s: block %s signed but not published: %w", reason, template.Hash, err)
+	case stepPublish:
+		return errs.Errorf(kind, "%s while publishing block %s, which may still be published (check the account history): %w", reason, template.Hash, err)
+	default:
+		return errs.Errorf(kind, "%s during %s: nothing was signed or published: %w", reason, step, err)
+	}
+}
 
-	return nil
+// ReceiveAll receives every unreceived block for an address in batches of 5.
+// The optional onReceived callback is invoked with each published receive block,
+// whose FromBlockHash is the send block it received.
+//
+// Parameters:
+//   - ctx: Context that interrupts receiving
+//   - c: RPC client for querying and publishing
+//   - address: Address of the receiving account
+//   - keypair: Wallet keypair of the receiving account
+//   - onReceived: Callback invoked after each block is received (may be nil)
+//
+// Returns the number of blocks received and any error encountered.
+func ReceiveAll(ctx context.Context, c *rpc_client.RpcClient, address types.Address, keypair *wallet.KeyPair, onReceived func(*nom.AccountBlock)) (int, error) {
+	receivedCount := 0
+	for {
+		blocks, err := c.LedgerApi.GetUnreceivedBlocksByAddress(address, 0, 5)
+		if err != nil {
+			return receivedCount, fmt.Errorf("failed to get unreceived blocks: %w", errs.FromRPC(err))
+		}
+
+		if len(blocks.List) == 0 {
+			return receivedCount, nil
+		}
+
+		// Receive each block in current batch
+		for _, block := range blocks.List {
+			template := &nom.AccountBlock{
+				Version:         1,
+				ChainIdentifier: 1,
+				BlockType:       nom.BlockTypeUserReceive,
+				FromBlockHash:   block.Hash,
+				Data:            nil,
+			}
+
+			if err := BuildAndSend(ctx, c, address, template, keypair); err != nil {
+				return receivedCount, fmt.Errorf("failed to receive block %s: %w", block.Hash, err)
+			}
+
+			receivedCount++
+			if onReceived != nil {
+				onReceived(template)
+			}
+		}
+	}
 }
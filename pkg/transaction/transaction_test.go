@@ -1,9 +1,16 @@
 package transaction
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/zenon-network/go-zenon/chain/nom"
+
+	"github.com/0x3639/znn_cli_go/pkg/errs"
 )
 
 // TestConstants verifies the package constants are correct
@@ -32,6 +39,41 @@ func TestConstants(t *testing.T) {
 	}
 }
 
+// TestEncodeData tests building block data from memo, hex and base64 inputs
+func TestEncodeData(t *testing.T) {
+	tests := []struct {
+		name       string
+		memo       string
+		dataHex    string
+		dataBase64 string
+		expected   []byte
+		wantErr    bool
+	}{
+		{name: "none", expected: nil},
+		{name: "memo", memo: "invoice #42", expected: []byte("invoice #42")},
+		{name: "hex", dataHex: "deadbeef", expected: []byte{0xde, 0xad, 0xbe, 0xef}},
+		{name: "hex with prefix", dataHex: "0x0102", expected: []byte{0x01, 0x02}},
+		{name: "base64", dataBase64: "aGVsbG8=", expected: []byte("hello")},
+		{name: "invalid hex", dataHex: "xyz", wantErr: true},
+		{name: "invalid base64", dataBase64: "%%%", wantErr: true},
+		{name: "multiple inputs", memo: "a", dataHex: "01", wantErr: true},
+		{name: "too large", memo: strings.Repeat("a", MaxDataSize+1), wantErr: true},
+		{name: "maximum size", memo: strings.Repeat("a", MaxDataSize), expected: []byte(strings.Repeat("a", MaxDataSize))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := EncodeData(tt.memo, tt.dataHex, tt.dataBase64)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, data)
+		})
+	}
+}
+
 // Note: Full integration tests for Autofill, EnsurePlasmaOrPoW, Sign, Publish, and BuildAndSend
 // require a live Zenon node connection and are better suited for integration test suites.
 // These functions are tested indirectly through the CLI command tests with real node connections.
@@ -46,3 +88,41 @@ func TestConstants(t *testing.T) {
 // 1. Use integration tests with a test node for complete transaction flow validation
 // 2. Test individual commands (send, receive, etc.) which use this package
 // 3. Manually test against testnet before mainnet deployment
+
+// TestWaitUntil tests polling until a condition holds or times out
+func TestWaitUntil(t *testing.T) {
+	calls := 0
+	err := WaitUntil(t.Context(), time.Second, time.Millisecond, func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	err = WaitUntil(t.Context(), 5*time.Millisecond, time.Millisecond, func() (bool, error) { return false, nil })
+	assert.Error(t, err)
+
+	err = WaitUntil(t.Context(), time.Second, time.Millisecond, func() (bool, error) { return false, errors.New("rpc failed") })
+	assert.EqualError(t, err, "rpc failed")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	err = WaitUntil(ctx, time.Minute, time.Minute, func() (bool, error) { return false, nil })
+	assert.Equal(t, errs.Interrupted, errs.KindOf(err))
+}
+
+// TestStepFailed tests that interrupted steps report how far the block got
+func TestStepFailed(t *testing.T) {
+	template := &nom.AccountBlock{}
+	interrupted := errs.FromRPC(context.Canceled)
+
+	err := stepFailed(stepSigned, template, interrupted)
+	assert.Equal(t, errs.Interrupted, errs.KindOf(err))
+	assert.Contains(t, err.Error(), "signed but not published")
+
+	err = stepFailed(stepSign, template, interrupted)
+	assert.Contains(t, err.Error(), "nothing was signed or published")
+
+	err = stepFailed(stepSigned, template, errors.New("rejected"))
+	assert.EqualError(t, err, "publish failed: rejected")
+}
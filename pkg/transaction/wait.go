@@ -0,0 +1,38 @@
+package transaction
+
+import (
+	"context"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+)
+
+// DefaultWaitInterval is the polling interval used by WaitUntil
+const DefaultWaitInterval = 5 * time.Second
+
+// WaitUntil polls check every interval until it reports true, returns an
+// error, timeout elapses or ctx is done. It is used to wait for an embedded
+// contract to process a previously published block, such as a QSR deposit.
+func WaitUntil(ctx context.Context, timeout, interval time.Duration, check func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if err := ctx.Err(); err != nil {
+			return errs.FromRPC(err)
+		}
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return errs.Errorf(errs.Timeout, "timed out after %s", timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return errs.FromRPC(ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
@@ -4,14 +4,22 @@ package wallet
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/0x3639/znn-sdk-go/wallet"
 	"github.com/0x3639/znn_cli_go/internal/prompt"
-	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/0x3639/znn_cli_go/pkg/errs"
+	"github.com/zenon-network/go-zenon/common/types"
 )
 
+// DefaultGapLimit is the number of consecutive unused addresses after which discovery stops
+const DefaultGapLimit = 20
+
 // Manager wraps the SDK KeyStoreManager with CLI-specific functionality
 type Manager struct {
 	manager *wallet.KeyStoreManager
@@ -75,28 +83,26 @@ func LoadWallet(walletDir, keystoreName, passphrase string, index int) (*wallet.
 	// Create manager
 	mgr, err := NewManager(walletDir)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, errs.Wrap(errs.Wallet, err)
 	}
 
 	// Determine which keyStore to use
 	if keystoreName == "" {
 		wallets, err := mgr.List()
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to list wallets: %w", err)
+			return nil, nil, errs.Errorf(errs.Wallet, "failed to list wallets: %w", err)
 		}
 
 		if len(wallets) == 0 {
-			return nil, nil, fmt.Errorf("no wallets found in %s. Create one with: znn-cli wallet.createNew", walletDir)
+			return nil, nil, errs.Errorf(errs.Wallet, "no wallets found in %s. Create one with: znn-cli wallet.createNew", walletDir)
 		}
 
 		if len(wallets) == 1 {
 			keystoreName = wallets[0]
-			if os.Getenv("ZNN_CLI_VERBOSE") == "1" {
-				format.Info(fmt.Sprintf("Using wallet: %s", keystoreName))
-			}
+			slog.Info("using the only wallet", "keyStore", keystoreName)
 		} else {
 			// Multiple wallets found, ask user to specify
-			return nil, nil, fmt.Errorf("multiple wallets found: %v. Specify with --keyStore flag", wallets)
+			return nil, nil, errs.Errorf(errs.Wallet, "multiple wallets found: %v. Specify with --keyStore flag", wallets)
 		}
 	}
 
@@ -104,7 +110,7 @@ func LoadWallet(walletDir, keystoreName, passphrase string, index int) (*wallet.
 	if passphrase == "" {
 		pass, err := prompt.Password("Enter passphrase: ")
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to read passphrase: %w", err)
+			return nil, nil, errs.Errorf(errs.Wallet, "failed to read passphrase: %w", err)
 		}
 		passphrase = pass
 	}
@@ -112,18 +118,115 @@ func LoadWallet(walletDir, keystoreName, passphrase string, index int) (*wallet.
 	// Load keyStore
 	ks, err := mgr.Load(passphrase, keystoreName)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load wallet: %w", err)
+		return nil, nil, errs.Errorf(errs.Wallet, "failed to load wallet: %w", err)
 	}
 
 	// Get keypair at index
 	kp, err := ks.GetKeyPair(index)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get keypair at index %d: %w", index, err)
+		return nil, nil, errs.Errorf(errs.Wallet, "failed to get keypair at index %d: %w", index, err)
 	}
+	recordLoaded(keystoreName, ks, index, kp)
 
 	return ks, kp, nil
 }
 
+// accountScanLimit is the number of indexes Account derives when looking up
+// an address that was not loaded by LoadWallet
+const accountScanLimit = 128
+
+// loadedKeyStore is a keyStore loaded by LoadWallet
+type loadedKeyStore struct {
+	name string
+	ks   *wallet.KeyStore
+}
+
+var (
+	loadedMu sync.Mutex
+	loaded   []loadedKeyStore
+	accounts = make(map[types.Address]accountRef)
+)
+
+// accountRef locates an address in a loaded keyStore
+type accountRef struct {
+	keyStore string
+	index    int
+}
+
+// recordLoaded remembers a keyStore loaded by LoadWallet and the address it was loaded for
+func recordLoaded(name string, ks *wallet.KeyStore, index int, kp *wallet.KeyPair) {
+	loadedMu.Lock()
+	defer loadedMu.Unlock()
+	if address, err := kp.GetAddress(); err == nil {
+		accounts[*address] = accountRef{keyStore: name, index: index}
+	}
+	for _, existing := range loaded {
+		if existing.name == name {
+			return
+		}
+	}
+	loaded = append(loaded, loadedKeyStore{name: name, ks: ks})
+}
+
+// DeriveKeyPair returns the keypair at an index of a keyStore loaded by
+// LoadWallet, recording its address so that Account finds it at any index
+func DeriveKeyPair(ks *wallet.KeyStore, index int) (*wallet.KeyPair, error) {
+	kp, err := ks.GetKeyPair(index)
+	if err != nil {
+		return nil, errs.Errorf(errs.Wallet, "failed to get keypair at index %d: %w", index, err)
+	}
+
+	loadedMu.Lock()
+	defer loadedMu.Unlock()
+	for _, entry := range loaded {
+		if entry.ks != ks {
+			continue
+		}
+		if address, err := kp.GetAddress(); err == nil {
+			accounts[*address] = accountRef{keyStore: entry.name, index: index}
+		}
+	}
+	return kp, nil
+}
+
+// LoadedKeyStores returns the names of the keyStores loaded by LoadWallet so far
+func LoadedKeyStores() []string {
+	loadedMu.Lock()
+	defer loadedMu.Unlock()
+	names := make([]string, 0, len(loaded))
+	for _, entry := range loaded {
+		names = append(names, entry.name)
+	}
+	return names
+}
+
+// Account returns the loaded keyStore and index of an address. Addresses of
+// other indexes are found by deriving the first indexes of each keyStore.
+func Account(address types.Address) (string, int, bool) {
+	loadedMu.Lock()
+	defer loadedMu.Unlock()
+	if ref, found := accounts[address]; found {
+		return ref.keyStore, ref.index, true
+	}
+	for _, entry := range loaded {
+		for i := 0; i < accountScanLimit; i++ {
+			kp, err := entry.ks.GetKeyPair(i)
+			if err != nil {
+				break
+			}
+			derived, err := kp.GetAddress()
+			if err != nil {
+				break
+			}
+			accounts[*derived] = accountRef{keyStore: entry.name, index: i}
+			if *derived == address {
+				return entry.name, i, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
 // GetAddress returns the address for a keypair as a string
 func GetAddress(kp *wallet.KeyPair) (string, error) {
 	addr, err := kp.GetAddress()
@@ -156,3 +259,101 @@ func DeriveAddresses(ks *wallet.KeyStore, start, end int) ([]string, error) {
 
 	return addresses, nil
 }
+
+// DiscoveredAddress is a derived address that was found to be in use
+type DiscoveredAddress struct {
+	Index   int
+	Address types.Address
+}
+
+// UsageFunc reports whether a derived address has been used on chain
+type UsageFunc func(index int, address types.Address) (bool, error)
+
+// Discover walks derived addresses starting at index 0 and returns the ones
+// reported as used by isUsed. The walk stops after gapLimit consecutive unused
+// addresses. The number of scanned indices is returned alongside the results.
+func Discover(ks *wallet.KeyStore, gapLimit int, isUsed UsageFunc) ([]DiscoveredAddress, int, error) {
+	if gapLimit < 1 {
+		return nil, 0, fmt.Errorf("invalid gap limit: %d", gapLimit)
+	}
+
+	var used []DiscoveredAddress
+	gap := 0
+	index := 0
+	for ; gap < gapLimit; index++ {
+		kp, err := ks.GetKeyPair(index)
+		if err != nil {
+			return nil, index, fmt.Errorf("failed to get keypair at index %d: %w", index, err)
+		}
+
+		addr, err := kp.GetAddress()
+		if err != nil {
+			return nil, index, fmt.Errorf("failed to get address at index %d: %w", index, err)
+		}
+
+		ok, err := isUsed(index, *addr)
+		if err != nil {
+			return nil, index, err
+		}
+
+		if ok {
+			used = append(used, DiscoveredAddress{Index: index, Address: *addr})
+			gap = 0
+		} else {
+			gap++
+		}
+	}
+
+	return used, index, nil
+}
+
+// indexRangeLimit is the number of indices ParseIndexRange accepts, so a
+// mistyped range does not derive billions of addresses
+const indexRangeLimit = 1000
+
+// ParseIndexRange parses an index selection such as "5", "0-20" or "0-3,7,10-12"
+// into an ordered list of unique address indices.
+func ParseIndexRange(value string) ([]int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("index range cannot be empty")
+	}
+
+	seen := make(map[int]bool)
+	var indices []int
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		bounds := strings.SplitN(part, "-", 2)
+
+		start, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid index range %q: %w", part, err)
+		}
+		end := start
+		if len(bounds) == 2 {
+			end, err = strconv.Atoi(strings.TrimSpace(bounds[1]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid index range %q: %w", part, err)
+			}
+		}
+
+		if start < 0 || end < start {
+			return nil, fmt.Errorf("invalid range: start=%d end=%d", start, end)
+		}
+		if end-start >= indexRangeLimit {
+			return nil, fmt.Errorf("index range %q selects more than %d indices", part, indexRangeLimit)
+		}
+
+		for i := start; i <= end; i++ {
+			if !seen[i] {
+				seen[i] = true
+				indices = append(indices, i)
+			}
+		}
+		if len(indices) > indexRangeLimit {
+			return nil, fmt.Errorf("index range %q selects more than %d indices", value, indexRangeLimit)
+		}
+	}
+
+	return indices, nil
+}
@@ -0,0 +1,168 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/0x3639/znn-sdk-go/wallet"
+	"github.com/0x3639/znn_cli_go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// TestDiscover tests the gap-limit address discovery walk
+func TestDiscover(t *testing.T) {
+	ks, err := wallet.NewKeyStoreFromMnemonic(testutil.MnemonicFixture)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		usedIndices     map[int]bool
+		gapLimit        int
+		expectedIndices []int
+		expectedScanned int
+	}{
+		{
+			name:            "nothing used",
+			usedIndices:     map[int]bool{},
+			gapLimit:        3,
+			expectedIndices: nil,
+			expectedScanned: 3,
+		},
+		{
+			name:            "first index used",
+			usedIndices:     map[int]bool{0: true},
+			gapLimit:        3,
+			expectedIndices: []int{0},
+			expectedScanned: 4,
+		},
+		{
+			name:            "gap inside limit",
+			usedIndices:     map[int]bool{0: true, 3: true},
+			gapLimit:        3,
+			expectedIndices: []int{0, 3},
+			expectedScanned: 7,
+		},
+		{
+			name:            "gap reaches limit",
+			usedIndices:     map[int]bool{0: true, 4: true},
+			gapLimit:        3,
+			expectedIndices: []int{0},
+			expectedScanned: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used, scanned, err := Discover(ks, tt.gapLimit, func(index int, address types.Address) (bool, error) {
+				return tt.usedIndices[index], nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedScanned, scanned)
+
+			var indices []int
+			for _, entry := range used {
+				indices = append(indices, entry.Index)
+
+				expected, err := DeriveAddresses(ks, entry.Index, entry.Index)
+				require.NoError(t, err)
+				assert.Equal(t, expected[0], entry.Address.String())
+			}
+			assert.Equal(t, tt.expectedIndices, indices)
+		})
+	}
+}
+
+// TestDiscoverErrors tests error handling in address discovery
+func TestDiscoverErrors(t *testing.T) {
+	ks, err := wallet.NewKeyStoreFromMnemonic(testutil.MnemonicFixture)
+	require.NoError(t, err)
+
+	_, _, err = Discover(ks, 0, func(int, types.Address) (bool, error) { return false, nil })
+	assert.Error(t, err)
+
+	queryErr := errors.New("node unreachable")
+	_, _, err = Discover(ks, 5, func(int, types.Address) (bool, error) { return false, queryErr })
+	assert.ErrorIs(t, err, queryErr)
+}
+
+// TestParseIndexRange tests parsing of address index selections
+func TestParseIndexRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  []int
+		expectErr bool
+	}{
+		{name: "single index", input: "5", expected: []int{5}},
+		{name: "range", input: "0-3", expected: []int{0, 1, 2, 3}},
+		{name: "list and ranges", input: "0-1, 4,6-7", expected: []int{0, 1, 4, 6, 7}},
+		{name: "duplicates removed", input: "1-3,2", expected: []int{1, 2, 3}},
+		{name: "empty", input: "", expectErr: true},
+		{name: "reversed range", input: "5-2", expectErr: true},
+		{name: "negative", input: "-1", expectErr: true},
+		{name: "not a number", input: "a-b", expectErr: true},
+		{name: "range too large", input: "0-4294967295", expectErr: true},
+		{name: "ranges too large together", input: "0-999,2000-2000", expectErr: true},
+		{name: "largest range", input: "1-1000", expected: indexSequence(1, 1000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseIndexRange(tt.input)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+// indexSequence returns the indices from start to end
+func indexSequence(start, end int) []int {
+	var indices []int
+	for i := start; i <= end; i++ {
+		indices = append(indices, i)
+	}
+	return indices
+}
+
+// TestAccount tests that loaded keyStores locate their addresses
+func TestAccount(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir)
+	require.NoError(t, err)
+	_, err = mgr.CreateFromMnemonic(testutil.MnemonicFixture, "passphrase", "fixture")
+	require.NoError(t, err)
+
+	ks, _, err := LoadWallet(dir, "fixture", "passphrase", 1)
+	require.NoError(t, err)
+	assert.Contains(t, LoadedKeyStores(), "fixture")
+
+	for _, index := range []int{1, 5} {
+		kp, err := ks.GetKeyPair(index)
+		require.NoError(t, err)
+		address, err := kp.GetAddress()
+		require.NoError(t, err)
+		name, found, ok := Account(*address)
+		require.True(t, ok)
+		assert.Equal(t, "fixture", name)
+		assert.Equal(t, index, found)
+	}
+
+	// Indexes beyond the scan limit are found once derived through the keyStore
+	kp, err := DeriveKeyPair(ks, accountScanLimit+10)
+	require.NoError(t, err)
+	address, err := kp.GetAddress()
+	require.NoError(t, err)
+	name, found, ok := Account(*address)
+	require.True(t, ok)
+	assert.Equal(t, "fixture", name)
+	assert.Equal(t, accountScanLimit+10, found)
+
+	_, _, ok = Account(testutil.ProducerAddress)
+	assert.False(t, ok)
+}
@@ -0,0 +1,247 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 signature of a webhook body
+	SignatureHeader = "X-Znn-Signature"
+
+	// EventHeader carries the event type of a webhook
+	EventHeader = "X-Znn-Event"
+
+	// DeliveryHeader carries the event ID of a webhook
+	DeliveryHeader = "X-Znn-Delivery"
+
+	// DefaultMaxAttempts is the number of delivery attempts per poll
+	DefaultMaxAttempts = 5
+
+	// DefaultBackoff is the delay before the first retry; it doubles per attempt
+	DefaultBackoff = time.Second
+)
+
+// Sink receives event payloads
+type Sink interface {
+	// Name identifies the sink in the outbox
+	Name() string
+	// Deliver sends the JSON payload of an event
+	Deliver(ctx context.Context, payload []byte, event *Event) error
+}
+
+// Webhook posts events to a URL, signed with a shared secret
+type Webhook struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+// Name returns the sink name
+func (w *Webhook) Name() string {
+	return "webhook:" + w.URL
+}
+
+// Deliver posts the payload and treats any non-2xx response as a failure
+func (w *Webhook) Deliver(ctx context.Context, payload []byte, event *Event) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(event.Type))
+	req.Header.Set(DeliveryHeader, event.ID)
+	if w.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, payload))
+	}
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Command runs a shell command per event with the payload on stdin
+type Command struct {
+	Command string
+}
+
+// Name returns the sink name
+func (c *Command) Name() string {
+	return "exec:" + c.Command
+}
+
+// Deliver runs the command and treats a non-zero exit status as a failure
+func (c *Command) Deliver(ctx context.Context, payload []byte, event *Event) error {
+	// #nosec G204 - The hook command is configured by the user
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.Command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"ZNN_EVENT_ID="+event.ID,
+		"ZNN_EVENT_TYPE="+string(event.Type),
+		"ZNN_EVENT_ADDRESS="+event.Address,
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		message := strings.TrimSpace(string(output))
+		if len(message) > 200 {
+			message = message[:200]
+		}
+		if message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+	return nil
+}
+
+// Sign returns the signature header value for a payload: sha256=<hex HMAC-SHA256>
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher delivers outbox entries to sinks with retries
+type Dispatcher struct {
+	Sinks       []Sink
+	MaxAttempts int
+	Backoff     time.Duration
+
+	// sleep waits between attempts; replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewDispatcher creates a dispatcher with the default retry policy
+func NewDispatcher(sinks []Sink) *Dispatcher {
+	return &Dispatcher{
+		Sinks:       sinks,
+		MaxAttempts: DefaultMaxAttempts,
+		Backoff:     DefaultBackoff,
+	}
+}
+
+// SinkNames returns the names of the dispatcher's sinks
+func (d *Dispatcher) SinkNames() []string {
+	names := make([]string, 0, len(d.Sinks))
+	for _, sink := range d.Sinks {
+		names = append(names, sink.Name())
+	}
+	return names
+}
+
+// Flush tries to deliver every outbox entry. Delivered sinks are removed from
+// an entry and fully delivered entries are dropped; save is called after each
+// entry so progress survives a crash. Entries that still fail stay in the
+// outbox for the next flush. It returns the number of deliveries made.
+func (d *Dispatcher) Flush(ctx context.Context, state *State, save func() error) (int, error) {
+	sinks := make(map[string]Sink, len(d.Sinks))
+	for _, sink := range d.Sinks {
+		sinks[sink.Name()] = sink
+	}
+
+	delivered := 0
+	var remaining []*Delivery
+	for i, delivery := range state.Outbox {
+		if ctx.Err() != nil {
+			remaining = append(remaining, state.Outbox[i:]...)
+			break
+		}
+
+		payload, err := json.Marshal(delivery.Event)
+		if err != nil {
+			return delivered, fmt.Errorf("failed to encode event: %w", err)
+		}
+
+		var pending []string
+		for _, name := range delivery.Pending {
+			sink, found := sinks[name]
+			if !found {
+				// The sink was removed from the configuration
+				continue
+			}
+			if err := d.deliver(ctx, sink, payload, delivery); err != nil {
+				delivery.LastError = fmt.Sprintf("%s: %v", name, err)
+				pending = append(pending, name)
+				continue
+			}
+			delivered++
+		}
+
+		delivery.Pending = pending
+		if len(pending) > 0 {
+			remaining = append(remaining, delivery)
+		}
+
+		state.Outbox = append(append([]*Delivery(nil), remaining...), state.Outbox[i+1:]...)
+		if err := save(); err != nil {
+			return delivered, err
+		}
+	}
+
+	state.Outbox = remaining
+	return delivered, save()
+}
+
+// deliver sends a payload to one sink, retrying with exponential backoff
+func (d *Dispatcher) deliver(ctx context.Context, sink Sink, payload []byte, delivery *Delivery) error {
+	attempts := d.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	sleep := d.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	backoff := d.Backoff
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if sleepErr := sleep(ctx, backoff); sleepErr != nil {
+				return err
+			}
+			backoff *= 2
+		}
+		delivery.Attempts++
+		if err = sink.Deliver(ctx, payload, &delivery.Event); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,133 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noSleep skips retry backoff in tests
+func noSleep(ctx context.Context, d time.Duration) error {
+	return nil
+}
+
+// failingSink fails a fixed number of times before succeeding
+type failingSink struct {
+	name     string
+	failures int
+	calls    int
+}
+
+func (s *failingSink) Name() string { return s.name }
+
+func (s *failingSink) Deliver(ctx context.Context, payload []byte, event *Event) error {
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+// TestSign verifies the webhook signature format
+func TestSign(t *testing.T) {
+	// echo -n '{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=77325902caca812dc259733aacd046b73817372c777b8d95b402647474516e13",
+		Sign("secret", []byte("{}")))
+	assert.NotEqual(t, Sign("secret", []byte("{}")), Sign("other", []byte("{}")))
+}
+
+// TestWebhookRetry verifies webhook headers and retry until success
+func TestWebhookRetry(t *testing.T) {
+	calls := 0
+	var body []byte
+	var headers http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		headers = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	state := NewState()
+	event := Event{ID: "received:abc", Type: EventReceived, Address: "z1qq"}
+	webhook := &Webhook{URL: srv.URL, Secret: "secret"}
+	dispatcher := NewDispatcher([]Sink{webhook})
+	dispatcher.sleep = noSleep
+	state.Enqueue([]Event{event}, dispatcher.SinkNames())
+
+	saves := 0
+	delivered, err := dispatcher.Flush(context.Background(), state, func() error { saves++; return nil })
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, state.Outbox)
+	assert.Positive(t, saves)
+
+	var received Event
+	require.NoError(t, json.Unmarshal(body, &received))
+	assert.Equal(t, event.ID, received.ID)
+	assert.Equal(t, Sign("secret", body), headers.Get(SignatureHeader))
+	assert.Equal(t, "received", headers.Get(EventHeader))
+	assert.Equal(t, "received:abc", headers.Get(DeliveryHeader))
+}
+
+// TestFlushKeepsFailures verifies failed sinks stay pending and removed sinks are dropped
+func TestFlushKeepsFailures(t *testing.T) {
+	down := &failingSink{name: "down", failures: 100}
+	up := &failingSink{name: "up"}
+	dispatcher := &Dispatcher{Sinks: []Sink{down, up}, MaxAttempts: 3, sleep: noSleep}
+
+	state := NewState()
+	state.Enqueue([]Event{{ID: "a"}}, []string{"down", "up", "removed"})
+
+	delivered, err := dispatcher.Flush(context.Background(), state, func() error { return nil })
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 3, down.calls)
+	require.Len(t, state.Outbox, 1)
+	assert.Equal(t, []string{"down"}, state.Outbox[0].Pending)
+	assert.Equal(t, 4, state.Outbox[0].Attempts)
+	assert.Contains(t, state.Outbox[0].LastError, "unavailable")
+
+	// The next flush delivers once the sink recovers
+	down.failures = 0
+	delivered, err = dispatcher.Flush(context.Background(), state, func() error { return nil })
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Empty(t, state.Outbox)
+}
+
+// TestCommand verifies hook commands receive the payload and environment
+func TestCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "event")
+	command := &Command{Command: `cat > "` + out + `"; test "$ZNN_EVENT_TYPE" = balance`}
+
+	event := &Event{ID: "balance:x", Type: EventBalance}
+	require.NoError(t, command.Deliver(context.Background(), []byte(`{"id":"balance:x"}`), event))
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"balance:x"}`, string(data))
+
+	failing := &Command{Command: "echo broken >&2; exit 3"}
+	err = failing.Deliver(context.Background(), nil, event)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken")
+}
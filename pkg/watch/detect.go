@@ -0,0 +1,363 @@
+package watch
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/0x3639/znn_cli_go/pkg/format"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+
+	rpc_client "github.com/0x3639/znn-sdk-go/rpc_client"
+)
+
+const (
+	// pageSize is the number of entries requested per page
+	pageSize = 50
+
+	// maxUnreceivedPages bounds the unreceived blocks inspected per poll
+	maxUnreceivedPages = 10
+)
+
+// Watcher detects events for a set of addresses
+type Watcher struct {
+	Client    *rpc_client.RpcClient
+	Addresses []types.Address
+	State     *State
+	Now       func() time.Time
+}
+
+// Poll detects new events for every address and advances the state.
+//
+// On the first poll of an address its pending blocks, height and balances are
+// recorded without emitting events; expired stakes and cancellable fusions are
+// reported on the first poll since they require action.
+func (w *Watcher) Poll() ([]Event, error) {
+	now := time.Now()
+	if w.Now != nil {
+		now = w.Now()
+	}
+
+	momentum, err := w.Client.LedgerApi.GetFrontierMomentum()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frontier momentum: %w", err)
+	}
+
+	var events []Event
+	for _, address := range w.Addresses {
+		addressEvents, err := w.pollAddress(address, momentum.Height, now)
+		if err != nil {
+			return events, fmt.Errorf("%s: %w", address, err)
+		}
+		events = append(events, addressEvents...)
+	}
+	return events, nil
+}
+
+// pollAddress detects events for one address. The address state is only
+// updated when every query succeeds, so a failed poll is retried in full.
+func (w *Watcher) pollAddress(address types.Address, height uint64, now time.Time) ([]Event, error) {
+	stored := w.State.Address(address.String())
+	// The detectors replace slices and maps rather than modifying them, so a shallow copy suffices
+	next := *stored
+	state := &next
+	initialized := state.Initialized
+
+	var events []Event
+
+	unreceived, err := w.unreceived(address, state, now)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := w.Client.LedgerApi.GetAccountInfoByAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account info: %w", err)
+	}
+
+	received, err := w.received(address, state, info.AccountHeight, now)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := diffBalances(state, address.String(), info, now)
+
+	stakes, err := w.expiredStakes(address, state, now)
+	if err != nil {
+		return nil, err
+	}
+
+	fusions, err := w.cancellableFusions(address, state, height, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if initialized {
+		events = append(events, unreceived...)
+		events = append(events, received...)
+		events = append(events, balances...)
+	}
+	events = append(events, stakes...)
+	events = append(events, fusions...)
+
+	state.Initialized = true
+	*stored = next
+	return events, nil
+}
+
+// unreceived reports blocks sent to the address that were not pending at the previous poll
+func (w *Watcher) unreceived(address types.Address, state *AddressState, now time.Time) ([]Event, error) {
+	var blocks []*api.AccountBlock
+	for pageIndex := uint32(0); pageIndex < maxUnreceivedPages; pageIndex++ {
+		list, err := w.Client.LedgerApi.GetUnreceivedBlocksByAddress(address, pageIndex, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get unreceived blocks: %w", err)
+		}
+		blocks = append(blocks, list.List...)
+		if !list.More || len(list.List) == 0 {
+			break
+		}
+	}
+
+	current := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		current = append(current, block.Hash.String())
+	}
+	added := newEntries(state.Unreceived, current)
+	state.Unreceived = current
+
+	var events []Event
+	for _, block := range blocks {
+		if !added[block.Hash.String()] {
+			continue
+		}
+		event := Event{
+			ID:      string(EventUnreceived) + ":" + block.Hash.String(),
+			Type:    EventUnreceived,
+			Address: address.String(),
+			Time:    now,
+			Hash:    block.Hash.String(),
+			From:    block.Address.String(),
+			Memo:    format.Memo(block.Data),
+		}
+		setAmount(&event, block)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// received reports confirmed receive blocks above the stored height
+func (w *Watcher) received(address types.Address, state *AddressState, accountHeight uint64, now time.Time) ([]Event, error) {
+	if !state.Initialized {
+		state.Height = accountHeight
+		return nil, nil
+	}
+
+	var events []Event
+	for state.Height < accountHeight {
+		list, err := w.Client.LedgerApi.GetAccountBlocksByHeight(address, state.Height+1, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account blocks: %w", err)
+		}
+		if len(list.List) == 0 {
+			break
+		}
+
+		for _, block := range list.List {
+			// Stop at the first unconfirmed block so it is reported once confirmed
+			if block.ConfirmationDetail == nil {
+				return events, nil
+			}
+			state.Height = block.Height
+
+			if !nom.IsReceiveBlock(block.BlockType) {
+				continue
+			}
+			event := Event{
+				ID:       string(EventReceived) + ":" + block.Hash.String(),
+				Type:     EventReceived,
+				Address:  address.String(),
+				Time:     now,
+				Hash:     block.Hash.String(),
+				Momentum: block.ConfirmationDetail.MomentumHeight,
+			}
+			if send := block.PairedAccountBlock; send != nil {
+				event.From = send.Address.String()
+				event.Memo = format.Memo(send.Data)
+				setAmount(&event, send)
+			}
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// expiredStakes reports stake entries that became revocable
+func (w *Watcher) expiredStakes(address types.Address, state *AddressState, now time.Time) ([]Event, error) {
+	var current []string
+	var events []Event
+	previous := toSet(state.ExpiredStakes)
+
+	for pageIndex := uint32(0); ; pageIndex++ {
+		list, err := w.Client.StakeApi.GetEntriesByAddress(address, pageIndex, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stake entries: %w", err)
+		}
+		for _, entry := range list.Entries {
+			if entry.ExpirationTimestamp > now.Unix() {
+				continue
+			}
+			id := entry.Id.String()
+			current = append(current, id)
+			if previous[id] {
+				continue
+			}
+			events = append(events, Event{
+				ID:            string(EventStakeExpired) + ":" + id,
+				Type:          EventStakeExpired,
+				Address:       address.String(),
+				Time:          now,
+				EntryID:       id,
+				TokenStandard: types.ZnnTokenStandard.String(),
+				Symbol:        "ZNN",
+				Amount:        entry.Amount.String(),
+			})
+		}
+		if len(list.Entries) < pageSize {
+			break
+		}
+	}
+
+	state.ExpiredStakes = current
+	return events, nil
+}
+
+// cancellableFusions reports fusion entries that became cancellable
+func (w *Watcher) cancellableFusions(address types.Address, state *AddressState, height uint64, now time.Time) ([]Event, error) {
+	var current []string
+	var events []Event
+	previous := toSet(state.CancellableFusions)
+
+	for pageIndex := uint32(0); ; pageIndex++ {
+		list, err := w.Client.PlasmaApi.GetEntriesByAddress(address, pageIndex, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fusion entries: %w", err)
+		}
+		for _, entry := range list.Fusions {
+			if entry.ExpirationHeight > height {
+				continue
+			}
+			id := entry.Id.String()
+			current = append(current, id)
+			if previous[id] {
+				continue
+			}
+			events = append(events, Event{
+				ID:            string(EventFusionCancellable) + ":" + id,
+				Type:          EventFusionCancellable,
+				Address:       address.String(),
+				Time:          now,
+				EntryID:       id,
+				From:          entry.Beneficiary.String(),
+				TokenStandard: types.QsrTokenStandard.String(),
+				Symbol:        "QSR",
+				Amount:        entry.QsrAmount.String(),
+				Momentum:      height,
+			})
+		}
+		if len(list.Fusions) < pageSize {
+			break
+		}
+	}
+
+	state.CancellableFusions = current
+	return events, nil
+}
+
+// diffBalances reports changed token balances and stores the new balances
+func diffBalances(state *AddressState, address string, info *api.AccountInfo, now time.Time) []Event {
+	current := make(map[string]string)
+	symbols := make(map[string]string)
+	for zts, balanceInfo := range info.BalanceInfoMap {
+		if balanceInfo.Balance == nil {
+			continue
+		}
+		current[zts.String()] = balanceInfo.Balance.String()
+		if balanceInfo.TokenInfo != nil {
+			symbols[zts.String()] = balanceInfo.TokenInfo.TokenSymbol
+		}
+	}
+
+	var tokens []string
+	for zts := range current {
+		tokens = append(tokens, zts)
+	}
+	for zts := range state.Balances {
+		if _, found := current[zts]; !found {
+			tokens = append(tokens, zts)
+		}
+	}
+	sort.Strings(tokens)
+
+	var events []Event
+	for _, zts := range tokens {
+		previous, amount := state.Balances[zts], current[zts]
+		if previous == "" {
+			previous = "0"
+		}
+		if amount == "" {
+			amount = "0"
+		}
+		if previous == amount {
+			continue
+		}
+		events = append(events, Event{
+			ID:            fmt.Sprintf("%s:%s:%s:%d", EventBalance, address, zts, info.AccountHeight),
+			Type:          EventBalance,
+			Address:       address,
+			Time:          now,
+			TokenStandard: zts,
+			Symbol:        symbols[zts],
+			Amount:        amount,
+			Previous:      previous,
+		})
+	}
+
+	state.Balances = current
+	return events
+}
+
+// setAmount copies the token and amount of a send block to an event
+func setAmount(event *Event, block *api.AccountBlock) {
+	if block.Amount == nil || block.Amount.Sign() == 0 {
+		return
+	}
+	event.TokenStandard = block.TokenStandard.String()
+	event.Amount = block.Amount.String()
+	if block.TokenInfo != nil {
+		event.Symbol = block.TokenInfo.TokenSymbol
+	}
+}
+
+// newEntries returns the entries of current that are not in previous
+func newEntries(previous, current []string) map[string]bool {
+	seen := toSet(previous)
+	added := make(map[string]bool)
+	for _, entry := range current {
+		if !seen[entry] {
+			added[entry] = true
+		}
+	}
+	return added
+}
+
+// toSet converts a slice to a set
+func toSet(entries []string) map[string]bool {
+	set := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		set[entry] = true
+	}
+	return set
+}
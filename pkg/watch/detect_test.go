@@ -0,0 +1,56 @@
+package watch
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+)
+
+// TestDiffBalances verifies balance change detection
+func TestDiffBalances(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	state := &AddressState{Balances: map[string]string{
+		types.ZnnTokenStandard.String(): "100",
+		types.QsrTokenStandard.String(): "50",
+	}}
+
+	info := &api.AccountInfo{
+		AccountHeight: 7,
+		BalanceInfoMap: map[types.ZenonTokenStandard]*api.BalanceInfo{
+			types.ZnnTokenStandard: {Balance: big.NewInt(100)},
+			types.QsrTokenStandard: {Balance: big.NewInt(80), TokenInfo: &api.Token{TokenSymbol: "QSR"}},
+		},
+	}
+
+	events := diffBalances(state, "z1qq", info, now)
+	require.Len(t, events, 1)
+	assert.Equal(t, EventBalance, events[0].Type)
+	assert.Equal(t, "80", events[0].Amount)
+	assert.Equal(t, "50", events[0].Previous)
+	assert.Equal(t, "QSR", events[0].Symbol)
+	assert.Equal(t, "balance:z1qq:"+types.QsrTokenStandard.String()+":7", events[0].ID)
+
+	// Unchanged balances produce no events
+	assert.Empty(t, diffBalances(state, "z1qq", info, now))
+
+	// A token disappearing from the account is a change to zero
+	info.BalanceInfoMap = map[types.ZenonTokenStandard]*api.BalanceInfo{
+		types.ZnnTokenStandard: {Balance: big.NewInt(100)},
+	}
+	events = diffBalances(state, "z1qq", info, now)
+	require.Len(t, events, 1)
+	assert.Equal(t, "0", events[0].Amount)
+	assert.Equal(t, "80", events[0].Previous)
+}
+
+// TestNewEntries verifies set differences
+func TestNewEntries(t *testing.T) {
+	added := newEntries([]string{"a", "b"}, []string{"b", "c", "d"})
+	assert.Equal(t, map[string]bool{"c": true, "d": true}, added)
+	assert.Empty(t, newEntries([]string{"a"}, nil))
+}
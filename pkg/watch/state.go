@@ -0,0 +1,158 @@
+// Package watch detects account and chain events for a set of addresses and
+// delivers them to webhooks and hook commands. Detection progress and
+// undelivered events are kept in a state file, so a restarted watcher
+// neither misses nor re-detects events.
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StateVersion is the current state file format version
+const StateVersion = 1
+
+// EventType identifies the kind of event
+type EventType string
+
+const (
+	// EventUnreceived is a new block sent to the address that is not yet received
+	EventUnreceived EventType = "unreceived"
+	// EventReceived is a confirmed receive block of the address
+	EventReceived EventType = "received"
+	// EventBalance is a change of a token balance
+	EventBalance EventType = "balance"
+	// EventStakeExpired is a stake entry that can be revoked
+	EventStakeExpired EventType = "stakeExpired"
+	// EventFusionCancellable is a fusion entry that can be canceled
+	EventFusionCancellable EventType = "fusionCancellable"
+)
+
+// Event is a detected event. ID is stable across restarts so receivers can deduplicate.
+type Event struct {
+	ID            string    `json:"id"`
+	Type          EventType `json:"type"`
+	Address       string    `json:"address"`
+	Time          time.Time `json:"time"`
+	Hash          string    `json:"hash,omitempty"`
+	From          string    `json:"from,omitempty"`
+	TokenStandard string    `json:"tokenStandard,omitempty"`
+	Symbol        string    `json:"symbol,omitempty"`
+	Amount        string    `json:"amount,omitempty"`
+	Previous      string    `json:"previous,omitempty"`
+	Memo          string    `json:"memo,omitempty"`
+	EntryID       string    `json:"entryId,omitempty"`
+	Momentum      uint64    `json:"momentum,omitempty"`
+	Pillar        string    `json:"pillar,omitempty"`
+	Message       string    `json:"message,omitempty"`
+}
+
+// AddressState is the detection cursor of one address
+type AddressState struct {
+	Initialized        bool              `json:"initialized"`
+	Height             uint64            `json:"height"`
+	Balances           map[string]string `json:"balances"`
+	Unreceived         []string          `json:"unreceived"`
+	ExpiredStakes      []string          `json:"expiredStakes"`
+	CancellableFusions []string          `json:"cancellableFusions"`
+}
+
+// Delivery is an event waiting to be delivered to some sinks
+type Delivery struct {
+	Event     Event    `json:"event"`
+	Pending   []string `json:"pending"`
+	Attempts  int      `json:"attempts"`
+	LastError string   `json:"lastError,omitempty"`
+}
+
+// State is the persisted watcher state
+type State struct {
+	Version   int                      `json:"version"`
+	Addresses map[string]*AddressState `json:"addresses"`
+	Outbox    []*Delivery              `json:"outbox"`
+}
+
+// NewState returns an empty state
+func NewState() *State {
+	return &State{
+		Version:   StateVersion,
+		Addresses: make(map[string]*AddressState),
+	}
+}
+
+// Address returns the state of an address, creating it if needed
+func (s *State) Address(address string) *AddressState {
+	state, found := s.Addresses[address]
+	if !found {
+		state = &AddressState{Balances: make(map[string]string)}
+		s.Addresses[address] = state
+	}
+	if state.Balances == nil {
+		state.Balances = make(map[string]string)
+	}
+	return state
+}
+
+// Enqueue adds events to the outbox for delivery to the given sinks
+func (s *State) Enqueue(events []Event, sinks []string) {
+	if len(sinks) == 0 {
+		return
+	}
+	for _, event := range events {
+		s.Outbox = append(s.Outbox, &Delivery{
+			Event:   event,
+			Pending: append([]string(nil), sinks...),
+		})
+	}
+}
+
+// LoadState reads the state file, returning an empty state if it does not exist
+func LoadState(path string) (*State, error) {
+	// #nosec G304 - Path is provided by the user or the default config directory
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewState(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watch state: %w", err)
+	}
+
+	state := NewState()
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse watch state: %w", err)
+	}
+	if state.Version != StateVersion {
+		return nil, fmt.Errorf("unsupported watch state version %d", state.Version)
+	}
+	if state.Addresses == nil {
+		state.Addresses = make(map[string]*AddressState)
+	}
+
+	return state, nil
+}
+
+// Save writes the state file, replacing it atomically
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode watch state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create watch state directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write watch state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write watch state: %w", err)
+	}
+
+	return nil
+}
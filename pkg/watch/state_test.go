@@ -0,0 +1,62 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStateSaveLoad verifies the state file round trip
+func TestStateSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watch", "state.json")
+
+	state, err := LoadState(path)
+	require.NoError(t, err)
+	assert.Empty(t, state.Addresses)
+
+	address := state.Address("z1qqjnwjjpnue8xmmpanz6csze6tcmtzzdtfsww7")
+	address.Initialized = true
+	address.Height = 42
+	address.Balances["zts1znnxxxxxxxxxxxxx9z4ulx"] = "100"
+	state.Enqueue([]Event{{ID: "received:abc", Type: EventReceived, Time: time.Unix(1700000000, 0).UTC()}}, []string{"exec:true"})
+	require.NoError(t, state.Save(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := LoadState(path)
+	require.NoError(t, err)
+	assert.Equal(t, state, loaded)
+}
+
+// TestEnqueue verifies outbox entries are only created when sinks exist
+func TestEnqueue(t *testing.T) {
+	state := NewState()
+	events := []Event{{ID: "a"}, {ID: "b"}}
+
+	state.Enqueue(events, nil)
+	assert.Empty(t, state.Outbox)
+
+	sinks := []string{"webhook:https://example.com", "exec:true"}
+	state.Enqueue(events, sinks)
+	require.Len(t, state.Outbox, 2)
+	assert.Equal(t, sinks, state.Outbox[1].Pending)
+
+	// Entries must not share the sink slice
+	state.Outbox[0].Pending[0] = "changed"
+	assert.Equal(t, "webhook:https://example.com", state.Outbox[1].Pending[0])
+}
+
+// TestLoadStateVersion verifies unknown state versions are rejected
+func TestLoadStateVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 99}`), 0600))
+
+	_, err := LoadState(path)
+	assert.Error(t, err)
+}